@@ -0,0 +1,24 @@
+data "radosgw_policy_document" "assets" {
+  statement {
+    actions   = ["s3:GetObject", "s3:ListBucket"]
+    resources = ["arn:aws:s3:::assets", "arn:aws:s3:::assets/*"]
+
+    principals {
+      type = "AWS"
+      # users and subusers as written by radosgw, like the owner of a key
+      users = ["acme$deploy", "demo:readonly"]
+
+      # or references to radosgw_user and radosgw_subuser resources
+      user {
+        tenant  = radosgw_subuser.reader.tenant
+        user_id = radosgw_subuser.reader.user_id
+        subuser = radosgw_subuser.reader.subuser
+      }
+    }
+  }
+}
+
+resource "radosgw_bucket_policy" "assets" {
+  bucket = "assets"
+  policy = data.radosgw_policy_document.assets.json
+}
@@ -0,0 +1,2 @@
+# Bucket ACLs can be imported by the name of their bucket
+terraform import radosgw_bucket_acl.assets assets
@@ -0,0 +1,20 @@
+# Canned ACL
+resource "radosgw_bucket_acl" "assets" {
+  bucket = "assets"
+  acl    = "private"
+}
+
+# Explicit grants, which need to include the owner
+resource "radosgw_bucket_acl" "reports" {
+  bucket = "reports"
+
+  grant {
+    permission = "FULL_CONTROL"
+    user       = "demo"
+  }
+
+  grant {
+    permission = "READ"
+    user       = "analytics$reader"
+  }
+}
@@ -0,0 +1,2 @@
+# Bucket CORS configurations can be imported by the name of their bucket
+terraform import radosgw_bucket_cors_configuration.assets assets
@@ -0,0 +1,11 @@
+resource "radosgw_bucket_cors_configuration" "assets" {
+  bucket = "assets"
+
+  cors_rule {
+    allowed_methods = ["GET", "HEAD"]
+    allowed_origins = ["https://www.example.com"]
+    allowed_headers = ["*"]
+    expose_headers  = ["ETag"]
+    max_age_seconds = 3600
+  }
+}
@@ -0,0 +1,2 @@
+# Bucket lifecycle configurations can be imported by the name of their bucket
+terraform import radosgw_bucket_lifecycle_configuration.assets assets
@@ -0,0 +1,38 @@
+resource "radosgw_bucket_lifecycle_configuration" "assets" {
+  bucket = "assets"
+
+  rule {
+    id = "logs"
+
+    filter {
+      prefix = "logs/"
+    }
+
+    expiration {
+      days = 30
+    }
+  }
+
+  rule {
+    id = "archive"
+
+    filter {
+      prefix                   = "archive/"
+      object_size_greater_than = 1048576
+    }
+
+    # the storage class must exist in the placement target of the bucket
+    transition {
+      days          = 7
+      storage_class = "COLD"
+    }
+
+    noncurrent_version_expiration {
+      noncurrent_days = 90
+    }
+
+    abort_incomplete_multipart_upload {
+      days_after_initiation = 2
+    }
+  }
+}
@@ -0,0 +1,2 @@
+# Bucket notifications can be imported by the name of their bucket
+terraform import radosgw_bucket_notification.media media
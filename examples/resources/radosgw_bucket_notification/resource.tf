@@ -0,0 +1,25 @@
+resource "radosgw_bucket_notification" "media" {
+  bucket = "media"
+
+  topic {
+    id            = "images"
+    topic_arn     = radosgw_topic.uploads.arn
+    events        = ["s3:ObjectCreated:*"]
+    filter_prefix = "images/"
+    filter_suffix = ".jpg"
+
+    filter_metadata = {
+      x-amz-meta-source = "camera"
+    }
+
+    filter_tags = {
+      publish = "true"
+    }
+  }
+
+  topic {
+    id        = "deletions"
+    topic_arn = radosgw_topic.webhook.arn
+    events    = ["s3:ObjectRemoved:*", "s3:ObjectLifecycle:Expiration:*"]
+  }
+}
@@ -0,0 +1,2 @@
+# Bucket object lock configurations can be imported by the name of their bucket
+terraform import radosgw_bucket_object_lock_configuration.records records
@@ -0,0 +1,14 @@
+resource "radosgw_bucket_versioning" "records" {
+  bucket = "records"
+  status = "Enabled"
+}
+
+resource "radosgw_bucket_object_lock_configuration" "records" {
+  # object lock needs versioning enabled first
+  bucket = radosgw_bucket_versioning.records.bucket
+
+  default_retention {
+    mode  = "COMPLIANCE"
+    years = 7
+  }
+}
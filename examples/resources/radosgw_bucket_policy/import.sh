@@ -0,0 +1,2 @@
+# Bucket policies can be imported by the name of their bucket
+terraform import radosgw_bucket_policy.assets assets
@@ -0,0 +1,15 @@
+resource "radosgw_bucket_policy" "assets" {
+  bucket = "assets"
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [{
+      Effect = "Allow"
+      Principal = {
+        # users without tenant have an empty tenant in their ARN
+        AWS = ["arn:aws:iam:::user/reader", "arn:aws:iam::acme:user/deploy"]
+      }
+      Action   = ["s3:GetObject", "s3:ListBucket"]
+      Resource = ["arn:aws:s3:::assets", "arn:aws:s3:::assets/*"]
+    }]
+  })
+}
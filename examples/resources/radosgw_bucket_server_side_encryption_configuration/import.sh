@@ -0,0 +1,2 @@
+# Bucket encryption configurations can be imported by the name of their bucket
+terraform import radosgw_bucket_server_side_encryption_configuration.assets assets
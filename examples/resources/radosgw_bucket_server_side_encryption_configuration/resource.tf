@@ -0,0 +1,10 @@
+resource "radosgw_bucket_server_side_encryption_configuration" "assets" {
+  bucket = "assets"
+
+  rule {
+    apply_server_side_encryption_by_default {
+      sse_algorithm     = "aws:kms"
+      kms_master_key_id = "assets-key"
+    }
+  }
+}
@@ -0,0 +1,2 @@
+# Bucket tags can be imported by the name of their bucket
+terraform import radosgw_bucket_tagging.assets assets
@@ -0,0 +1,7 @@
+resource "radosgw_bucket_tagging" "assets" {
+  bucket = "assets"
+  tags = {
+    team          = "web"
+    "cost-center" = "1234"
+  }
+}
@@ -0,0 +1,2 @@
+# Bucket versioning can be imported by the name of its bucket
+terraform import radosgw_bucket_versioning.assets assets
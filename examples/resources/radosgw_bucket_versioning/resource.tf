@@ -0,0 +1,4 @@
+resource "radosgw_bucket_versioning" "assets" {
+  bucket = "assets"
+  status = "Enabled"
+}
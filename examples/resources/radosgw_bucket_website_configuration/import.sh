@@ -0,0 +1,2 @@
+# Bucket website configurations can be imported by the name of their bucket
+terraform import radosgw_bucket_website_configuration.assets assets
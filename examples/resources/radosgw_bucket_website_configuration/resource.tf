@@ -0,0 +1,14 @@
+resource "radosgw_bucket_website_configuration" "assets" {
+  bucket         = "assets"
+  index_document = "index.html"
+  error_document = "error.html"
+
+  routing_rule {
+    condition {
+      key_prefix_equals = "docs/"
+    }
+    redirect {
+      replace_key_prefix_with = "documents/"
+    }
+  }
+}
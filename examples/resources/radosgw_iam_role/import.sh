@@ -0,0 +1,4 @@
+# Roles are imported by their name, prefixed with their tenant if the
+# provider credentials belong to a tenant
+terraform import radosgw_iam_role.deploy deploy
+terraform import radosgw_iam_role.deploy 'media$deploy'
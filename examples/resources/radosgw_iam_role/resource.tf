@@ -0,0 +1,36 @@
+# A role the user ci can assume with STS AssumeRole
+resource "radosgw_iam_role" "deploy" {
+  name                 = "deploy"
+  path                 = "/ci/"
+  max_session_duration = 7200
+
+  assume_role_policy = data.radosgw_policy_document.deploy_trust.json
+}
+
+data "radosgw_policy_document" "deploy_trust" {
+  statement {
+    actions = ["sts:AssumeRole"]
+
+    principals {
+      type  = "AWS"
+      users = ["ci"]
+    }
+  }
+}
+
+# A role assumed by web identities of an OpenID Connect provider
+resource "radosgw_iam_role" "web" {
+  name = "web"
+
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [{
+      Effect    = "Allow"
+      Action    = "sts:AssumeRoleWithWebIdentity"
+      Principal = { Federated = "arn:aws:iam:::oidc-provider/sso.example.com/realms/media" }
+      Condition = {
+        StringEquals = { "sso.example.com/realms/media:aud" = "uploader" }
+      }
+    }]
+  })
+}
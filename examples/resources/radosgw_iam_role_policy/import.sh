@@ -0,0 +1,4 @@
+# Role policies are imported as <role>:<name>, with the role prefixed with
+# its tenant if the provider credentials belong to a tenant
+terraform import radosgw_iam_role_policy.deploy_assets deploy:deploy-assets
+terraform import radosgw_iam_role_policy.deploy_assets 'media$deploy:deploy-assets'
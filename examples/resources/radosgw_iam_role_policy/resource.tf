@@ -0,0 +1,13 @@
+resource "radosgw_iam_role_policy" "deploy_assets" {
+  role = radosgw_iam_role.deploy.name
+  name = "deploy-assets"
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [{
+      Effect   = "Allow"
+      Action   = ["s3:GetObject", "s3:PutObject", "s3:DeleteObject"]
+      Resource = "arn:aws:s3:::assets/*"
+    }]
+  })
+}
@@ -0,0 +1,2 @@
+# Keys can be imported by their access key, also those of tenanted users
+terraform import radosgw_key.demo DEMOACCESSKEY
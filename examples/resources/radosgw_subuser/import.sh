@@ -0,0 +1,8 @@
+# Subusers can be imported as <user>:<subuser>
+terraform import radosgw_subuser.readonly demo:readonly
+
+# Subusers of tenanted users can be imported as <tenant>$<user>:<subuser>
+terraform import radosgw_subuser.readonly 'acme$demo:readonly'
+
+# or using the JSON form
+terraform import radosgw_subuser.readonly '{"tenant": "acme", "user_id": "demo", "subuser": "readonly"}'
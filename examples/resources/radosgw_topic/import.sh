@@ -0,0 +1,6 @@
+# Topics can be imported by their name, if they belong to the tenant of the
+# provider credentials
+terraform import radosgw_topic.uploads uploads
+
+# or by their ARN
+terraform import radosgw_topic.uploads arn:aws:sns:default::uploads
@@ -0,0 +1,5 @@
+# Users can be imported by their ID
+terraform import radosgw_user.demo demo
+
+# Tenanted users can be imported as <tenant>$<user>
+terraform import radosgw_user.demo 'acme$demo'
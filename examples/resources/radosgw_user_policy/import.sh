@@ -0,0 +1,4 @@
+# User policies are imported as <user>:<name>, or <tenant>$<user>:<name> for
+# users of a tenant
+terraform import radosgw_user_policy.uploader uploader:uploads-only
+terraform import radosgw_user_policy.auditor 'media$auditor:read-only'
@@ -0,0 +1,37 @@
+# Allow the user uploader to only write objects below uploads/
+resource "radosgw_user_policy" "uploader" {
+  user = radosgw_user.uploader.user_id
+  name = "uploads-only"
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Effect   = "Allow"
+        Action   = ["s3:PutObject", "s3:AbortMultipartUpload"]
+        Resource = "arn:aws:s3:::media/uploads/*"
+      },
+      {
+        Effect      = "Deny"
+        NotAction   = ["s3:PutObject", "s3:AbortMultipartUpload"]
+        NotResource = "arn:aws:s3:::media/uploads/*"
+      },
+    ]
+  })
+}
+
+# Policies of tenanted users
+resource "radosgw_user_policy" "auditor" {
+  tenant = "media"
+  user   = "auditor"
+  name   = "read-only"
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [{
+      Effect   = "Deny"
+      Action   = ["s3:Put*", "s3:Delete*"]
+      Resource = "*"
+    }]
+  })
+}
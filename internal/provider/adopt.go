@@ -0,0 +1,36 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// adoptExistingAttribute returns the adopt_existing attribute of resources
+// creating objects of the given kind.
+func adoptExistingAttribute(kind string) schema.BoolAttribute {
+	return schema.BoolAttribute{
+		MarkdownDescription: fmt.Sprintf("Take over the %[1]s if it already exists instead of failing, changing it to match the configuration.  Defaults to `adopt_existing` of the provider.  Only has an effect when the %[1]s is created.", kind),
+		Optional:            true,
+	}
+}
+
+// shouldAdopt reports whether existing objects are adopted on create.  The
+// adopt_existing attribute of the resource overrides the provider setting.
+func shouldAdopt(adoptExisting types.Bool, providerDefault bool) bool {
+	if adoptExisting.IsNull() || adoptExisting.IsUnknown() {
+		return providerDefault
+	}
+	return adoptExisting.ValueBool()
+}
+
+// adoptedChanges describes the changes made to an adopted object for its
+// warning.
+func adoptedChanges(changed []string) string {
+	if len(changed) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("  Changed %s to match the configuration.", strings.Join(changed, ", "))
+}
@@ -0,0 +1,475 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &bucketACLResource{}
+	_ resource.ResourceWithConfigure      = &bucketACLResource{}
+	_ resource.ResourceWithImportState    = &bucketACLResource{}
+	_ resource.ResourceWithIdentity       = &bucketACLResource{}
+	_ resource.ResourceWithValidateConfig = &bucketACLResource{}
+)
+
+// cannedACLs are the canned ACLs radosgw supports for buckets.
+var cannedACLs = []string{"private", "public-read", "public-read-write", "authenticated-read"}
+
+// aclPermissions are the permissions of ACL grants.
+var aclPermissions = []string{"FULL_CONTROL", "READ", "WRITE", "READ_ACP", "WRITE_ACP"}
+
+// aclGroupURIPrefix is the prefix of the URIs of the groups ACLs can grant
+// permissions to, followed by the group name.
+const aclGroupURIPrefix = "http://acs.amazonaws.com/groups/global/"
+
+// aclGroups are the groups radosgw supports in ACL grants.
+var aclGroups = []string{"AllUsers", "AuthenticatedUsers"}
+
+// NewBucketACLResource is a helper function to simplify the provider implementation.
+func NewBucketACLResource() resource.Resource {
+	return &bucketACLResource{}
+}
+
+// bucketACLResource is the resource implementation.
+type bucketACLResource struct {
+	s3 s3iface.S3API
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *bucketACLResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
+		return
+	}
+
+	r.s3 = s3.New(data.awsSession)
+}
+
+// Metadata returns the resource type name.
+func (r *bucketACLResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_acl"
+}
+
+// Schema defines the schema for the resource.
+func (r *bucketACLResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the access control list (ACL) of a bucket through the S3 API of radosgw, either as a canned ACL or as explicit grants.  The provider credentials must belong to the owner of the bucket or to a system user.\n\n" +
+			"Grants are the complete ACL of the bucket, so the owner keeps full control through the ACL only with a grant of its own.  Changes of the grants outside of Terraform are detected as drift.  On destroy, the ACL of the bucket is reset to `private`.",
+		Attributes: map[string]schema.Attribute{
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Name of the bucket.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"acl": schema.StringAttribute{
+				MarkdownDescription: "Canned ACL of the bucket, one of `private`, `public-read`, `public-read-write` and `authenticated-read`.  Exactly one of `acl` and `grant` must be set.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(cannedACLs...),
+				},
+			},
+			"owner": schema.StringAttribute{
+				MarkdownDescription: "ID of the user owning the bucket.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"grant": schema.SetNestedBlock{
+				MarkdownDescription: "Permission granted to a user or group.",
+				Validators: []validator.Set{
+					setvalidator.ConflictsWith(path.MatchRoot("acl")),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"permission": schema.StringAttribute{
+							MarkdownDescription: "Permission granted, one of `FULL_CONTROL`, `READ`, `WRITE`, `READ_ACP` and `WRITE_ACP`.",
+							Required:            true,
+							Validators: []validator.String{
+								stringvalidator.OneOf(aclPermissions...),
+							},
+						},
+						"user": schema.StringAttribute{
+							MarkdownDescription: "ID of the user the permission is granted to, written `<tenant>$<user>` for users of a tenant.  Exactly one of `user` and `group` must be set.",
+							Optional:            true,
+							Validators: []validator.String{
+								stringvalidator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("group")),
+							},
+						},
+						"group": schema.StringAttribute{
+							MarkdownDescription: "Group the permission is granted to, `AllUsers` for anyone including anonymous requests or `AuthenticatedUsers` for all users of radosgw.",
+							Optional:            true,
+							Validators: []validator.String{
+								stringvalidator.OneOf(aclGroups...),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// IdentitySchema defines the identity of the resource.
+func (r *bucketACLResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"bucket": identityschema.StringAttribute{
+				RequiredForImport: true,
+			},
+		},
+	}
+}
+
+type bucketACLResourceModel struct {
+	Bucket types.String    `tfsdk:"bucket"`
+	ACL    types.String    `tfsdk:"acl"`
+	Owner  types.String    `tfsdk:"owner"`
+	Grants []aclGrantModel `tfsdk:"grant"`
+}
+
+type bucketACLIdentityModel struct {
+	Bucket types.String `tfsdk:"bucket"`
+}
+
+type aclGrantModel struct {
+	Permission types.String `tfsdk:"permission"`
+	User       types.String `tfsdk:"user"`
+	Group      types.String `tfsdk:"group"`
+}
+
+// errorTarget describes the ACL for explaining S3 API errors.
+func (m bucketACLResourceModel) errorTarget() rgwErrorTarget {
+	return rgwErrorTarget{
+		resourceType: "radosgw_bucket_acl",
+		importID:     m.Bucket.ValueString(),
+		bucketPath:   path.Root("bucket"),
+		grantPath:    path.Root("grant"),
+	}
+}
+
+// identity returns the resource identity of the ACL.
+func (m bucketACLResourceModel) identity() bucketACLIdentityModel {
+	return bucketACLIdentityModel{Bucket: m.Bucket}
+}
+
+// ValidateConfig implements resource.ResourceWithValidateConfig.  Besides
+// checking the grants, it warns when the bucket is made public, so that this
+// shows up in plans.
+func (r *bucketACLResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	// grants of dynamic blocks can be unknown, which the model cannot hold
+	var config bucketACLResourceModel
+	var grants types.Set
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("bucket"), &config.Bucket)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("acl"), &config.ACL)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("grant"), &grants)...)
+	if resp.Diagnostics.HasError() || grants.IsUnknown() {
+		return
+	}
+	resp.Diagnostics.Append(grants.ElementsAs(ctx, &config.Grants, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.ACL.IsNull() && len(config.Grants) == 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("acl"), "Missing ACL",
+			"Exactly one of acl and grant must be set.")
+		return
+	}
+
+	var public []string
+	switch config.ACL.ValueString() {
+	case "public-read", "public-read-write":
+		public = append(public, fmt.Sprintf("the canned ACL %q grants anyone, including anonymous requests, access", config.ACL.ValueString()))
+	case "authenticated-read":
+		public = append(public, `the canned ACL "authenticated-read" grants every user of radosgw read access`)
+	}
+	for _, grant := range config.Grants {
+		if !grant.User.IsNull() && !grant.User.IsUnknown() {
+			if _, err := parseUserImportID(grant.User.ValueString()); err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("grant"), "Invalid grant user",
+					fmt.Sprintf("User %q of a grant must be written as \"[<tenant>$]<user>\": %s.", grant.User.ValueString(), err))
+			}
+		}
+		switch grant.Group.ValueString() {
+		case "AllUsers":
+			public = append(public, fmt.Sprintf("%s is granted to anyone, including anonymous requests", grant.Permission.ValueString()))
+		case "AuthenticatedUsers":
+			public = append(public, fmt.Sprintf("%s is granted to every user of radosgw", grant.Permission.ValueString()))
+		}
+	}
+	if len(public) > 0 {
+		resp.Diagnostics.AddWarning("Bucket will be public",
+			fmt.Sprintf("The ACL of bucket %q makes it public: %s.", config.Bucket.ValueString(), strings.Join(public, "; ")))
+	}
+}
+
+// Read implements resource.Resource.
+func (r *bucketACLResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state bucketACLResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	out, err := r.s3.GetBucketAclWithContext(ctx, &s3.GetBucketAclInput{
+		Bucket: aws.String(state.Bucket.ValueString()),
+	})
+	if code := awsErrorCode(err); code == "NoSuchBucket" {
+		tflog.Warn(ctx, "bucket removed outside of Terraform", map[string]any{"bucket": state.Bucket.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error fetching bucket ACL",
+			fmt.Sprintf("Could not fetch ACL of bucket %q", state.Bucket.ValueString()), err)
+		return
+	}
+
+	state.setACL(out)
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.Identity.Set(ctx, state.identity())
+	resp.Diagnostics.Append(diags...)
+}
+
+// setACL sets the owner and the ACL of m to those of a GetBucketAcl
+// response.
+func (m *bucketACLResourceModel) setACL(out *s3.GetBucketAclOutput) {
+	owner := ""
+	if out.Owner != nil {
+		owner = aws.StringValue(out.Owner.ID)
+	}
+	m.Owner = types.StringValue(owner)
+
+	grants := aclGrantsFromS3(out.Grants)
+	if canned, ok := cannedACLOf(owner, grants); ok && len(m.Grants) == 0 {
+		// ACLs managed as canned ACL, and imported ones matching one,
+		// are kept as such
+		m.ACL = types.StringValue(canned)
+		m.Grants = nil
+	} else {
+		m.ACL = types.StringNull()
+		m.Grants = grants
+	}
+}
+
+// aclGrantsFromS3 converts the grants of a GetBucketAcl response to their
+// model, sorted for stable comparisons.
+func aclGrantsFromS3(grants []*s3.Grant) []aclGrantModel {
+	result := make([]aclGrantModel, 0, len(grants))
+	for _, grant := range grants {
+		if grant.Grantee == nil {
+			continue
+		}
+		model := aclGrantModel{
+			Permission: types.StringValue(aws.StringValue(grant.Permission)),
+			User:       types.StringNull(),
+			Group:      types.StringNull(),
+		}
+		if uri := aws.StringValue(grant.Grantee.URI); uri != "" {
+			model.Group = types.StringValue(strings.TrimPrefix(uri, aclGroupURIPrefix))
+		} else {
+			model.User = types.StringValue(aws.StringValue(grant.Grantee.ID))
+		}
+		result = append(result, model)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return aclGrantKey(result[i]) < aclGrantKey(result[j]) })
+	return result
+}
+
+// aclGrantKey identifies a grant for sorting and comparing grants.
+func aclGrantKey(grant aclGrantModel) string {
+	return grant.User.ValueString() + "|" + grant.Group.ValueString() + "|" + grant.Permission.ValueString()
+}
+
+// cannedACLGrants returns the grants radosgw stores for a canned ACL of a
+// bucket owned by owner.
+func cannedACLGrants(canned, owner string) []aclGrantModel {
+	grant := func(user, group, permission string) aclGrantModel {
+		return aclGrantModel{Permission: types.StringValue(permission), User: optionalString(user), Group: optionalString(group)}
+	}
+
+	grants := []aclGrantModel{grant(owner, "", "FULL_CONTROL")}
+	switch canned {
+	case "public-read":
+		grants = append(grants, grant("", "AllUsers", "READ"))
+	case "public-read-write":
+		grants = append(grants, grant("", "AllUsers", "READ"), grant("", "AllUsers", "WRITE"))
+	case "authenticated-read":
+		grants = append(grants, grant("", "AuthenticatedUsers", "READ"))
+	}
+	return grants
+}
+
+// cannedACLOf returns the canned ACL with exactly the grants, if there is
+// one.
+func cannedACLOf(owner string, grants []aclGrantModel) (string, bool) {
+	key := func(grants []aclGrantModel) string {
+		keys := make([]string, 0, len(grants))
+		for _, grant := range grants {
+			keys = append(keys, aclGrantKey(grant))
+		}
+		return strings.Join(sortedSet(keys), "\n")
+	}
+
+	actual := key(grants)
+	for _, canned := range cannedACLs {
+		if key(cannedACLGrants(canned, owner)) == actual {
+			return canned, true
+		}
+	}
+	return "", false
+}
+
+// ImportState implements resource.ResourceWithImportState.  ACLs are
+// imported by the name of their bucket or by their identity, as canned ACL
+// if they match one.
+func (r *bucketACLResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughWithIdentity(ctx, path.Root("bucket"), path.Root("bucket"), req, resp)
+}
+
+// Create implements resource.Resource.
+func (r *bucketACLResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan bucketACLResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	owner, err := r.put(ctx, plan)
+	if err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error setting bucket ACL",
+			fmt.Sprintf("Could not set ACL of bucket %q", plan.Bucket.ValueString()), err)
+		return
+	}
+	plan.Owner = types.StringValue(owner)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.Identity.Set(ctx, plan.identity())
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *bucketACLResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan bucketACLResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	owner, err := r.put(ctx, plan)
+	if err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error updating bucket ACL",
+			fmt.Sprintf("Could not update ACL of bucket %q", plan.Bucket.ValueString()), err)
+		return
+	}
+	plan.Owner = types.StringValue(owner)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.Identity.Set(ctx, plan.identity())
+	resp.Diagnostics.Append(diags...)
+}
+
+// put sets the ACL of the bucket to that of plan and returns the owner of
+// the bucket, which grants need to be sent with.
+func (r *bucketACLResource) put(ctx context.Context, plan bucketACLResourceModel) (string, error) {
+	current, err := r.s3.GetBucketAclWithContext(ctx, &s3.GetBucketAclInput{
+		Bucket: aws.String(plan.Bucket.ValueString()),
+	})
+	if err != nil {
+		return "", err
+	}
+	owner := current.Owner
+	if owner == nil {
+		owner = &s3.Owner{}
+	}
+
+	input := &s3.PutBucketAclInput{
+		Bucket: aws.String(plan.Bucket.ValueString()),
+	}
+	if !plan.ACL.IsNull() {
+		input.ACL = plan.ACL.ValueStringPointer()
+	} else {
+		policy := &s3.AccessControlPolicy{Owner: owner}
+		for _, grant := range plan.Grants {
+			grantee := &s3.Grantee{}
+			if !grant.Group.IsNull() {
+				grantee.SetType(s3.TypeGroup).SetURI(aclGroupURIPrefix + grant.Group.ValueString())
+			} else {
+				grantee.SetType(s3.TypeCanonicalUser).SetID(grant.User.ValueString())
+			}
+			policy.Grants = append(policy.Grants, &s3.Grant{Grantee: grantee, Permission: grant.Permission.ValueStringPointer()})
+		}
+		input.AccessControlPolicy = policy
+	}
+
+	_, err = r.s3.PutBucketAclWithContext(ctx, input)
+	return aws.StringValue(owner.ID), err
+}
+
+// Delete implements resource.Resource.  Buckets always have an ACL, so it
+// is reset to private.
+func (r *bucketACLResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state bucketACLResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.s3.PutBucketAclWithContext(ctx, &s3.PutBucketAclInput{
+		Bucket: aws.String(state.Bucket.ValueString()),
+		ACL:    aws.String("private"),
+	})
+	if awsErrorCode(err) == "NoSuchBucket" {
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error resetting bucket ACL",
+			fmt.Sprintf("Could not reset ACL of bucket %q to private", state.Bucket.ValueString()), err)
+		return
+	}
+}
@@ -0,0 +1,260 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+
+	"github.com/spreadshirt/terraform-provider-radosgw/internal/rgwtest"
+)
+
+func TestAccBucketACLResource(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck: func() {
+			testAccBucket(t, server)
+			if _, err := server.API().CreateUser(context.Background(), admin.User{ID: "reader", DisplayName: "Reader"}); err != nil {
+				t.Fatal(err)
+			}
+		},
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + testAccBucketACLResourceConfig(`acl = "public-read"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_bucket_acl.test", "acl", "public-read"),
+					resource.TestCheckResourceAttr("radosgw_bucket_acl.test", "owner", "demo"),
+					resource.TestCheckResourceAttr("radosgw_bucket_acl.test", "grant.#", "0"),
+					testAccCheckBucketSubresource(server, "assets", "acl", regexp.MustCompile(`<URI>http://acs.amazonaws.com/groups/global/AllUsers</URI>`)),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "radosgw_bucket_acl.test",
+				ImportState:                          true,
+				ImportStateId:                        "assets",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "bucket",
+			},
+			// Update and Read testing
+			{
+				Config: providerConfig + testAccBucketACLResourceConfig(testAccBucketACLGrants),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("radosgw_bucket_acl.test", "acl"),
+					resource.TestCheckResourceAttr("radosgw_bucket_acl.test", "grant.#", "2"),
+					resource.TestCheckTypeSetElemNestedAttrs("radosgw_bucket_acl.test", "grant.*", map[string]string{"user": "reader", "permission": "READ"}),
+					testAccCheckBucketSubresource(server, "assets", "acl", regexp.MustCompile(`<ID>reader</ID><DisplayName>Reader</DisplayName></Grantee><Permission>READ</Permission>`)),
+					testAccCheckBucketACLNotPublic(server),
+				),
+			},
+			// Grants changed outside of Terraform
+			{
+				PreConfig: func() {
+					testAccPutBucketACL(t, server, &s3.PutBucketAclInput{Bucket: aws.String("assets"), ACL: aws.String("public-read-write")})
+				},
+				Config:             providerConfig + testAccBucketACLResourceConfig(testAccBucketACLGrants),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: providerConfig + testAccBucketACLResourceConfig(testAccBucketACLGrants),
+				Check:  testAccCheckBucketACLNotPublic(server),
+			},
+		},
+		CheckDestroy: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckBucketACLNotPublic(server),
+			testAccCheckBucketSubresource(server, "assets", "acl", regexp.MustCompile(`^[^R]*<Permission>FULL_CONTROL</Permission></Grant></AccessControlList>`)),
+		),
+	})
+}
+
+func TestAccBucketACLResource_identity(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_12_0),
+		},
+		PreCheck: func() { testAccBucket(t, server) },
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + testAccBucketACLResourceConfig(`acl = "private"`),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectIdentity("radosgw_bucket_acl.test", map[string]knownvalue.Check{
+						"bucket": knownvalue.StringExact("assets"),
+					}),
+				},
+			},
+			{
+				ResourceName:    "radosgw_bucket_acl.test",
+				ImportState:     true,
+				ImportStateKind: resource.ImportBlockWithResourceIdentity,
+			},
+		},
+	})
+}
+
+func TestAccBucketACLResource_dynamic(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck: func() {
+			testAccBucket(t, server)
+			if _, err := server.API().CreateUser(context.Background(), admin.User{ID: "reader", DisplayName: "Reader"}); err != nil {
+				t.Fatal(err)
+			}
+		},
+		Steps: []resource.TestStep{
+			// the grants are unknown until terraform_data is applied
+			{
+				Config: providerConfig + `
+resource "terraform_data" "grants" {
+  input = { demo = "FULL_CONTROL", reader = "READ" }
+}
+
+resource "radosgw_bucket_acl" "test" {
+  bucket = "assets"
+
+  dynamic "grant" {
+    for_each = terraform_data.grants.output
+    content {
+      user       = grant.key
+      permission = grant.value
+    }
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_bucket_acl.test", "grant.#", "2"),
+					testAccCheckBucketSubresource(server, "assets", "acl", regexp.MustCompile(`<ID>reader</ID><DisplayName>Reader</DisplayName></Grantee><Permission>READ</Permission>`)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccBucketACLResource_validation(t *testing.T) {
+	_, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      providerConfig + testAccBucketACLResourceConfig(""),
+				ExpectError: regexp.MustCompile(`Exactly one of acl and grant must be set`),
+			},
+			{
+				Config: providerConfig + testAccBucketACLResourceConfig(`
+  grant {
+    permission = "READ"
+    user       = "a$b$c"
+  }`),
+				ExpectError: regexp.MustCompile(`Invalid grant user`),
+			},
+			{
+				Config: providerConfig + testAccBucketACLResourceConfig(`
+  grant {
+    permission = "READ"
+    user       = "reader"
+    group      = "AllUsers"
+  }`),
+				ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+			},
+		},
+	})
+}
+
+func TestAccBucketACLResource_unknownUser(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccBucket(t, server) },
+		Steps: []resource.TestStep{
+			{
+				Config:      providerConfig + testAccBucketACLResourceConfig(testAccBucketACLGrants),
+				ExpectError: regexp.MustCompile(`Check that the users of the grants exist`),
+			},
+		},
+	})
+}
+
+const testAccBucketACLGrants = `
+  grant {
+    permission = "FULL_CONTROL"
+    user       = "demo"
+  }
+
+  grant {
+    permission = "READ"
+    user       = "reader"
+  }`
+
+func testAccBucketACLResourceConfig(acl string) string {
+	return fmt.Sprintf(`
+resource "radosgw_bucket_acl" "test" {
+  bucket = "assets"
+  %s
+}
+`, acl)
+}
+
+// testAccPutBucketACL sets the ACL of a bucket on the fake radosgw like a
+// client outside of Terraform.
+func testAccPutBucketACL(t *testing.T, server *rgwtest.Server, input *s3.PutBucketAclInput) {
+	t.Helper()
+
+	session, err := newAWSSession(server.URL, server.AccessKey, server.SecretKey, func(client admin.HTTPClient) admin.HTTPClient { return client })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s3.New(session).PutBucketAcl(input); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testAccCheckBucketACLNotPublic checks that the ACL of the bucket assets
+// grants nothing to all users.
+func testAccCheckBucketACLNotPublic(server *rgwtest.Server) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		document, _ := server.BucketSubresource("assets", "acl")
+		if regexp.MustCompile(`AllUsers`).MatchString(document) {
+			return fmt.Errorf("ACL of bucket assets is public: %s", document)
+		}
+		return nil
+	}
+}
+
+func TestCannedACLOf(t *testing.T) {
+	grants := aclGrantsFromS3([]*s3.Grant{
+		{Grantee: &s3.Grantee{Type: aws.String("Group"), URI: aws.String(aclGroupURIPrefix + "AllUsers")}, Permission: aws.String("READ")},
+		{Grantee: &s3.Grantee{Type: aws.String("CanonicalUser"), ID: aws.String("tenant$demo")}, Permission: aws.String("FULL_CONTROL")},
+	})
+	if len(grants) != 2 || grants[0].User.ValueString() != "tenant$demo" || grants[1].Group.ValueString() != "AllUsers" {
+		t.Fatalf("unexpected grants %v", grants)
+	}
+
+	if canned, ok := cannedACLOf("tenant$demo", grants); !ok || canned != "public-read" {
+		t.Errorf("expected public-read, got %q", canned)
+	}
+	if canned, ok := cannedACLOf("other", grants); ok {
+		t.Errorf("grants of another owner match canned ACL %q", canned)
+	}
+	if canned, ok := cannedACLOf("tenant$demo", grants[:1]); !ok || canned != "private" {
+		t.Errorf("expected private, got %q", canned)
+	}
+}
@@ -0,0 +1,276 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &bucketCORSConfigurationResource{}
+	_ resource.ResourceWithConfigure   = &bucketCORSConfigurationResource{}
+	_ resource.ResourceWithImportState = &bucketCORSConfigurationResource{}
+)
+
+// corsMethods are the methods CORS rules of radosgw may allow.
+var corsMethods = []string{"GET", "PUT", "POST", "DELETE", "HEAD"}
+
+// NewBucketCORSConfigurationResource is a helper function to simplify the provider implementation.
+func NewBucketCORSConfigurationResource() resource.Resource {
+	return &bucketCORSConfigurationResource{}
+}
+
+// bucketCORSConfigurationResource is the resource implementation.
+type bucketCORSConfigurationResource struct {
+	s3 s3iface.S3API
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *bucketCORSConfigurationResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
+		return
+	}
+
+	r.s3 = s3.New(data.awsSession)
+}
+
+// Metadata returns the resource type name.
+func (r *bucketCORSConfigurationResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_cors_configuration"
+}
+
+// Schema defines the schema for the resource.
+func (r *bucketCORSConfigurationResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the cross-origin resource sharing (CORS) rules of a bucket through the S3 API of radosgw.  The provider credentials must belong to the owner of the bucket or to a system user.  All rules of the bucket are managed by this resource.",
+		Attributes: map[string]schema.Attribute{
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Name of the bucket.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"cors_rule": schema.ListNestedBlock{
+				MarkdownDescription: "CORS rule.  The first rule matching a request applies.",
+				Validators: []validator.List{
+					listvalidator.IsRequired(),
+					listvalidator.SizeBetween(1, 100),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "ID of the rule.",
+							Optional:            true,
+							Validators: []validator.String{
+								stringvalidator.LengthBetween(1, 255),
+							},
+						},
+						"allowed_methods": schema.SetAttribute{
+							MarkdownDescription: "Methods allowed for the origins, of `GET`, `PUT`, `POST`, `DELETE` and `HEAD`.",
+							ElementType:         types.StringType,
+							Required:            true,
+							Validators: []validator.Set{
+								setvalidator.SizeAtLeast(1),
+								setvalidator.ValueStringsAre(stringvalidator.OneOf(corsMethods...)),
+							},
+						},
+						"allowed_origins": schema.SetAttribute{
+							MarkdownDescription: "Origins allowed to send requests, which may contain one `*` wildcard.",
+							ElementType:         types.StringType,
+							Required:            true,
+							Validators: []validator.Set{
+								setvalidator.SizeAtLeast(1),
+							},
+						},
+						"allowed_headers": schema.SetAttribute{
+							MarkdownDescription: "Headers allowed in preflight requests, which may contain one `*` wildcard.",
+							ElementType:         types.StringType,
+							Optional:            true,
+						},
+						"expose_headers": schema.SetAttribute{
+							MarkdownDescription: "Response headers that browsers may expose to scripts.",
+							ElementType:         types.StringType,
+							Optional:            true,
+						},
+						"max_age_seconds": schema.Int64Attribute{
+							MarkdownDescription: "Time in seconds browsers may cache preflight responses.",
+							Optional:            true,
+							Validators: []validator.Int64{
+								int64validator.AtLeast(0),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+type bucketCORSConfigurationResourceModel struct {
+	Bucket types.String    `tfsdk:"bucket"`
+	Rules  []corsRuleModel `tfsdk:"cors_rule"`
+}
+
+type corsRuleModel struct {
+	ID             types.String `tfsdk:"id"`
+	AllowedMethods []string     `tfsdk:"allowed_methods"`
+	AllowedOrigins []string     `tfsdk:"allowed_origins"`
+	AllowedHeaders []string     `tfsdk:"allowed_headers"`
+	ExposeHeaders  []string     `tfsdk:"expose_headers"`
+	MaxAgeSeconds  types.Int64  `tfsdk:"max_age_seconds"`
+}
+
+// errorTarget describes the CORS configuration for explaining S3 API errors.
+func (m bucketCORSConfigurationResourceModel) errorTarget() rgwErrorTarget {
+	return rgwErrorTarget{
+		resourceType: "radosgw_bucket_cors_configuration",
+		importID:     m.Bucket.ValueString(),
+		bucketPath:   path.Root("bucket"),
+	}
+}
+
+// Read implements resource.Resource.
+func (r *bucketCORSConfigurationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state bucketCORSConfigurationResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	out, err := r.s3.GetBucketCorsWithContext(ctx, &s3.GetBucketCorsInput{
+		Bucket: aws.String(state.Bucket.ValueString()),
+	})
+	if code := awsErrorCode(err); code == "NoSuchCORSConfiguration" || code == "NoSuchBucket" {
+		tflog.Warn(ctx, "bucket CORS configuration removed outside of Terraform", map[string]any{"bucket": state.Bucket.ValueString(), "error_code": code})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error fetching bucket CORS configuration",
+			fmt.Sprintf("Could not fetch CORS configuration of bucket %q", state.Bucket.ValueString()), err)
+		return
+	}
+
+	state.Rules = make([]corsRuleModel, 0, len(out.CORSRules))
+	for _, rule := range out.CORSRules {
+		state.Rules = append(state.Rules, corsRuleModel{
+			ID:             optionalString(aws.StringValue(rule.ID)),
+			AllowedMethods: sortedSet(aws.StringValueSlice(rule.AllowedMethods)),
+			AllowedOrigins: sortedSet(aws.StringValueSlice(rule.AllowedOrigins)),
+			AllowedHeaders: sortedSet(aws.StringValueSlice(rule.AllowedHeaders)),
+			ExposeHeaders:  sortedSet(aws.StringValueSlice(rule.ExposeHeaders)),
+			MaxAgeSeconds:  types.Int64PointerValue(rule.MaxAgeSeconds),
+		})
+	}
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// ImportState implements resource.ResourceWithImportState.  CORS
+// configurations are imported by the name of their bucket.
+func (r *bucketCORSConfigurationResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("bucket"), req, resp)
+}
+
+// Create implements resource.Resource.
+func (r *bucketCORSConfigurationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan bucketCORSConfigurationResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.put(ctx, plan); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error setting bucket CORS configuration",
+			fmt.Sprintf("Could not set CORS configuration of bucket %q", plan.Bucket.ValueString()), err)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *bucketCORSConfigurationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan bucketCORSConfigurationResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.put(ctx, plan); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error updating bucket CORS configuration",
+			fmt.Sprintf("Could not update CORS configuration of bucket %q", plan.Bucket.ValueString()), err)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// put sets the CORS configuration of the bucket to that of plan.
+func (r *bucketCORSConfigurationResource) put(ctx context.Context, plan bucketCORSConfigurationResourceModel) error {
+	rules := make([]*s3.CORSRule, 0, len(plan.Rules))
+	for _, rule := range plan.Rules {
+		rules = append(rules, &s3.CORSRule{
+			ID:             rule.ID.ValueStringPointer(),
+			AllowedMethods: aws.StringSlice(sortedSet(rule.AllowedMethods)),
+			AllowedOrigins: aws.StringSlice(sortedSet(rule.AllowedOrigins)),
+			AllowedHeaders: aws.StringSlice(sortedSet(rule.AllowedHeaders)),
+			ExposeHeaders:  aws.StringSlice(sortedSet(rule.ExposeHeaders)),
+			MaxAgeSeconds:  rule.MaxAgeSeconds.ValueInt64Pointer(),
+		})
+	}
+
+	_, err := r.s3.PutBucketCorsWithContext(ctx, &s3.PutBucketCorsInput{
+		Bucket:            aws.String(plan.Bucket.ValueString()),
+		CORSConfiguration: &s3.CORSConfiguration{CORSRules: rules},
+	})
+	return err
+}
+
+// Delete implements resource.Resource.
+func (r *bucketCORSConfigurationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state bucketCORSConfigurationResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.s3.DeleteBucketCorsWithContext(ctx, &s3.DeleteBucketCorsInput{
+		Bucket: aws.String(state.Bucket.ValueString()),
+	})
+	if code := awsErrorCode(err); code == "NoSuchCORSConfiguration" || code == "NoSuchBucket" {
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error removing bucket CORS configuration",
+			fmt.Sprintf("Could not remove CORS configuration of bucket %q", state.Bucket.ValueString()), err)
+		return
+	}
+}
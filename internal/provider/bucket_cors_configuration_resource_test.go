@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccBucketCORSConfigurationResource(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccBucket(t, server) },
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + testAccBucketCORSConfigurationResourceConfig(`["GET", "HEAD"]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_bucket_cors_configuration.test", "cors_rule.#", "2"),
+					resource.TestCheckResourceAttr("radosgw_bucket_cors_configuration.test", "cors_rule.0.allowed_methods.#", "2"),
+					testAccCheckBucketSubresource(server, "assets", "cors", regexp.MustCompile(`<AllowedOrigin>https://www.example.com</AllowedOrigin>`)),
+					testAccCheckBucketSubresource(server, "assets", "cors", regexp.MustCompile(`<MaxAgeSeconds>3600</MaxAgeSeconds>`)),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "radosgw_bucket_cors_configuration.test",
+				ImportState:                          true,
+				ImportStateId:                        "assets",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "bucket",
+			},
+			// Update and Read testing
+			{
+				Config: providerConfig + testAccBucketCORSConfigurationResourceConfig(`["PUT"]`),
+				Check:  testAccCheckBucketSubresource(server, "assets", "cors", regexp.MustCompile(`<AllowedMethod>PUT</AllowedMethod>`)),
+			},
+			// Removed outside of Terraform
+			{
+				PreConfig: func() {
+					server.RemoveBucketSubresource("assets", "cors")
+				},
+				Config:             providerConfig + testAccBucketCORSConfigurationResourceConfig(`["PUT"]`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccBucketCORSConfigurationResource_validation(t *testing.T) {
+	_, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      providerConfig + testAccBucketCORSConfigurationResourceConfig(`["PATCH"]`),
+				ExpectError: regexp.MustCompile(`value must be one of`),
+			},
+		},
+	})
+}
+
+func testAccBucketCORSConfigurationResourceConfig(methods string) string {
+	return fmt.Sprintf(`
+resource "radosgw_bucket_cors_configuration" "test" {
+  bucket = "assets"
+
+  cors_rule {
+    id              = "web"
+    allowed_methods = %s
+    allowed_origins = ["https://www.example.com"]
+    allowed_headers = ["*"]
+    expose_headers  = ["ETag"]
+    max_age_seconds = 3600
+  }
+
+  cors_rule {
+    allowed_methods = ["GET"]
+    allowed_origins = ["*"]
+  }
+}
+`, methods)
+}
@@ -0,0 +1,902 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &bucketLifecycleConfigurationResource{}
+	_ resource.ResourceWithConfigure      = &bucketLifecycleConfigurationResource{}
+	_ resource.ResourceWithImportState    = &bucketLifecycleConfigurationResource{}
+	_ resource.ResourceWithValidateConfig = &bucketLifecycleConfigurationResource{}
+	_ resource.ResourceWithModifyPlan     = &bucketLifecycleConfigurationResource{}
+)
+
+// NewBucketLifecycleConfigurationResource is a helper function to simplify the provider implementation.
+func NewBucketLifecycleConfigurationResource() resource.Resource {
+	return &bucketLifecycleConfigurationResource{}
+}
+
+// bucketLifecycleConfigurationResource is the resource implementation.
+type bucketLifecycleConfigurationResource struct {
+	s3       s3iface.S3API
+	client   adminClient
+	rawAdmin *rawAdminClient
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *bucketLifecycleConfigurationResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
+		return
+	}
+
+	r.s3 = s3.New(data.awsSession)
+	r.client = data.client
+	r.rawAdmin = data.rawAdmin
+}
+
+// Metadata returns the resource type name.
+func (r *bucketLifecycleConfigurationResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_lifecycle_configuration"
+}
+
+// Schema defines the schema for the resource.
+func (r *bucketLifecycleConfigurationResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	daysValidators := []validator.Int64{int64validator.AtLeast(1)}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the lifecycle configuration of a bucket through the S3 API of radosgw.  The provider credentials must belong to the owner of the bucket or to a system user.  All rules of the bucket are managed by this resource.",
+		Attributes: map[string]schema.Attribute{
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Name of the bucket.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"rule": schema.ListNestedBlock{
+				MarkdownDescription: "Lifecycle rule.  Each rule needs at least one action.",
+				Validators: []validator.List{
+					listvalidator.IsRequired(),
+					listvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "Unique ID of the rule.",
+							Required:            true,
+							Validators: []validator.String{
+								stringvalidator.LengthBetween(1, 255),
+							},
+						},
+						"status": schema.StringAttribute{
+							MarkdownDescription: "Either `Enabled` or `Disabled`.  Defaults to `Enabled`.",
+							Optional:            true,
+							Computed:            true,
+							Default:             stringdefault.StaticString(s3.ExpirationStatusEnabled),
+							Validators: []validator.String{
+								stringvalidator.OneOf(s3.ExpirationStatus_Values()...),
+							},
+						},
+					},
+					Blocks: map[string]schema.Block{
+						"filter": schema.ListNestedBlock{
+							MarkdownDescription: "Objects the rule applies to, all objects of the bucket if not set.  Objects must match all conditions set.",
+							Validators: []validator.List{
+								listvalidator.SizeAtMost(1),
+							},
+							NestedObject: schema.NestedBlockObject{
+								Attributes: map[string]schema.Attribute{
+									"prefix": schema.StringAttribute{
+										MarkdownDescription: "Prefix of the object keys.",
+										Optional:            true,
+										Validators: []validator.String{
+											stringvalidator.LengthAtLeast(1),
+										},
+									},
+									"tags": schema.MapAttribute{
+										MarkdownDescription: "Tags the objects must have.",
+										ElementType:         types.StringType,
+										Optional:            true,
+									},
+									"object_size_greater_than": schema.Int64Attribute{
+										MarkdownDescription: "Minimum object size in bytes, exclusive.",
+										Optional:            true,
+										Validators: []validator.Int64{
+											int64validator.AtLeast(0),
+										},
+									},
+									"object_size_less_than": schema.Int64Attribute{
+										MarkdownDescription: "Maximum object size in bytes, exclusive.",
+										Optional:            true,
+										Validators: []validator.Int64{
+											int64validator.AtLeast(1),
+										},
+									},
+								},
+							},
+						},
+						"expiration": schema.ListNestedBlock{
+							MarkdownDescription: "Expire current object versions, which deletes them in unversioned buckets.",
+							Validators: []validator.List{
+								listvalidator.SizeAtMost(1),
+							},
+							NestedObject: schema.NestedBlockObject{
+								Attributes: map[string]schema.Attribute{
+									"days": schema.Int64Attribute{
+										MarkdownDescription: "Days after creation of the objects.",
+										Optional:            true,
+										Validators: append([]validator.Int64{
+											int64validator.ConflictsWith(path.MatchRelative().AtParent().AtName("date")),
+										}, daysValidators...),
+									},
+									"date": schema.StringAttribute{
+										MarkdownDescription: "Date as RFC 3339 timestamp at midnight UTC, such as `2030-01-01T00:00:00Z`.",
+										Optional:            true,
+										Validators: []validator.String{
+											timestampValidator{},
+										},
+									},
+									"expired_object_delete_marker": schema.BoolAttribute{
+										MarkdownDescription: "Remove delete markers without noncurrent versions.  Cannot be combined with `days` and `date`.  Defaults to `false`.",
+										Optional:            true,
+										Computed:            true,
+										Default:             booldefault.StaticBool(false),
+									},
+								},
+							},
+						},
+						"noncurrent_version_expiration": schema.ListNestedBlock{
+							MarkdownDescription: "Delete noncurrent object versions in versioned buckets.",
+							Validators: []validator.List{
+								listvalidator.SizeAtMost(1),
+							},
+							NestedObject: schema.NestedBlockObject{
+								Attributes: map[string]schema.Attribute{
+									"noncurrent_days": schema.Int64Attribute{
+										MarkdownDescription: "Days after the versions became noncurrent.",
+										Required:            true,
+										Validators:          daysValidators,
+									},
+									"newer_noncurrent_versions": schema.Int64Attribute{
+										MarkdownDescription: "Number of noncurrent versions to keep.",
+										Optional:            true,
+										Validators:          daysValidators,
+									},
+								},
+							},
+						},
+						"abort_incomplete_multipart_upload": schema.ListNestedBlock{
+							MarkdownDescription: "Abort multipart uploads that were not completed.",
+							Validators: []validator.List{
+								listvalidator.SizeAtMost(1),
+							},
+							NestedObject: schema.NestedBlockObject{
+								Attributes: map[string]schema.Attribute{
+									"days_after_initiation": schema.Int64Attribute{
+										MarkdownDescription: "Days after the upload was started.",
+										Required:            true,
+										Validators:          daysValidators,
+									},
+								},
+							},
+						},
+						"transition": schema.ListNestedBlock{
+							MarkdownDescription: "Move current object versions to another storage class of the placement target of the bucket.",
+							NestedObject: schema.NestedBlockObject{
+								Attributes: map[string]schema.Attribute{
+									"days": schema.Int64Attribute{
+										MarkdownDescription: "Days after creation of the objects.",
+										Optional:            true,
+										Validators: append([]validator.Int64{
+											int64validator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("date")),
+										}, daysValidators...),
+									},
+									"date": schema.StringAttribute{
+										MarkdownDescription: "Date as RFC 3339 timestamp at midnight UTC, such as `2030-01-01T00:00:00Z`.",
+										Optional:            true,
+										Validators: []validator.String{
+											timestampValidator{},
+										},
+									},
+									"storage_class": schema.StringAttribute{
+										MarkdownDescription: "Storage class to move the objects to.  It is checked against the placement target of the bucket when planning if the provider user has the `zone=read` cap.",
+										Required:            true,
+									},
+								},
+							},
+						},
+						"noncurrent_version_transition": schema.ListNestedBlock{
+							MarkdownDescription: "Move noncurrent object versions to another storage class of the placement target of the bucket.",
+							NestedObject: schema.NestedBlockObject{
+								Attributes: map[string]schema.Attribute{
+									"noncurrent_days": schema.Int64Attribute{
+										MarkdownDescription: "Days after the versions became noncurrent.",
+										Required:            true,
+										Validators:          daysValidators,
+									},
+									"newer_noncurrent_versions": schema.Int64Attribute{
+										MarkdownDescription: "Number of noncurrent versions to keep in the current storage class.",
+										Optional:            true,
+										Validators:          daysValidators,
+									},
+									"storage_class": schema.StringAttribute{
+										MarkdownDescription: "Storage class to move the versions to.  It is checked against the placement target of the bucket when planning if the provider user has the `zone=read` cap.",
+										Required:            true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+type bucketLifecycleConfigurationResourceModel struct {
+	Bucket types.String         `tfsdk:"bucket"`
+	Rules  []lifecycleRuleModel `tfsdk:"rule"`
+}
+
+type lifecycleRuleModel struct {
+	ID                             types.String                         `tfsdk:"id"`
+	Status                         types.String                         `tfsdk:"status"`
+	Filter                         []lifecycleFilterModel               `tfsdk:"filter"`
+	Expiration                     []lifecycleExpirationModel           `tfsdk:"expiration"`
+	NoncurrentVersionExpiration    []lifecycleNoncurrentExpirationModel `tfsdk:"noncurrent_version_expiration"`
+	AbortIncompleteMultipartUpload []lifecycleAbortModel                `tfsdk:"abort_incomplete_multipart_upload"`
+	Transitions                    []lifecycleTransitionModel           `tfsdk:"transition"`
+	NoncurrentVersionTransitions   []lifecycleNoncurrentTransitionModel `tfsdk:"noncurrent_version_transition"`
+}
+
+type lifecycleFilterModel struct {
+	Prefix                types.String      `tfsdk:"prefix"`
+	Tags                  map[string]string `tfsdk:"tags"`
+	ObjectSizeGreaterThan types.Int64       `tfsdk:"object_size_greater_than"`
+	ObjectSizeLessThan    types.Int64       `tfsdk:"object_size_less_than"`
+}
+
+type lifecycleExpirationModel struct {
+	Days                      types.Int64  `tfsdk:"days"`
+	Date                      types.String `tfsdk:"date"`
+	ExpiredObjectDeleteMarker types.Bool   `tfsdk:"expired_object_delete_marker"`
+}
+
+type lifecycleNoncurrentExpirationModel struct {
+	NoncurrentDays          types.Int64 `tfsdk:"noncurrent_days"`
+	NewerNoncurrentVersions types.Int64 `tfsdk:"newer_noncurrent_versions"`
+}
+
+type lifecycleAbortModel struct {
+	DaysAfterInitiation types.Int64 `tfsdk:"days_after_initiation"`
+}
+
+type lifecycleTransitionModel struct {
+	Days         types.Int64  `tfsdk:"days"`
+	Date         types.String `tfsdk:"date"`
+	StorageClass types.String `tfsdk:"storage_class"`
+}
+
+type lifecycleNoncurrentTransitionModel struct {
+	NoncurrentDays          types.Int64  `tfsdk:"noncurrent_days"`
+	NewerNoncurrentVersions types.Int64  `tfsdk:"newer_noncurrent_versions"`
+	StorageClass            types.String `tfsdk:"storage_class"`
+}
+
+// errorTarget describes the lifecycle configuration for explaining S3 API
+// errors.
+func (m bucketLifecycleConfigurationResourceModel) errorTarget() rgwErrorTarget {
+	return rgwErrorTarget{
+		resourceType: "radosgw_bucket_lifecycle_configuration",
+		importID:     m.Bucket.ValueString(),
+		bucketPath:   path.Root("bucket"),
+	}
+}
+
+// lifecycleActionBlocks are the blocks of the actions of lifecycle rules.
+var lifecycleActionBlocks = []string{"expiration", "noncurrent_version_expiration", "abort_incomplete_multipart_upload", "transition", "noncurrent_version_transition"}
+
+// lifecycleTransitionBlocks are the blocks of the transitions of lifecycle
+// rules.
+var lifecycleTransitionBlocks = []string{"transition", "noncurrent_version_transition"}
+
+// ValidateConfig implements resource.ResourceWithValidateConfig.
+func (r *bucketLifecycleConfigurationResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	// rules and their blocks from dynamic blocks can be unknown, which the
+	// model cannot hold
+	var rules types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("rule"), &rules)...)
+	if resp.Diagnostics.HasError() || rules.IsUnknown() {
+		return
+	}
+
+	ids := make(map[string]bool)
+	for i := range rules.Elements() {
+		rulePath := path.Root("rule").AtListIndex(i)
+
+		hasAction := false
+		var expirations types.List
+		for _, name := range lifecycleActionBlocks {
+			var actions types.List
+			resp.Diagnostics.Append(req.Config.GetAttribute(ctx, rulePath.AtName(name), &actions)...)
+			hasAction = hasAction || actions.IsUnknown() || len(actions.Elements()) > 0
+			if name == "expiration" {
+				expirations = actions
+			}
+		}
+		var id types.String
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, rulePath.AtName("id"), &id)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		if !hasAction {
+			resp.Diagnostics.AddAttributeError(rulePath, "Lifecycle rule without action",
+				"Each rule needs at least one of expiration, noncurrent_version_expiration, abort_incomplete_multipart_upload, transition or noncurrent_version_transition.")
+		}
+
+		if !id.IsNull() && !id.IsUnknown() {
+			if ids[id.ValueString()] {
+				resp.Diagnostics.AddAttributeError(rulePath.AtName("id"), "Duplicate lifecycle rule ID",
+					fmt.Sprintf("The rule ID %q is used more than once, radosgw needs unique rule IDs.", id.ValueString()))
+			}
+			ids[id.ValueString()] = true
+		}
+
+		if expirations.IsUnknown() {
+			continue
+		}
+		var expirationModels []lifecycleExpirationModel
+		resp.Diagnostics.Append(expirations.ElementsAs(ctx, &expirationModels, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		for _, expiration := range expirationModels {
+			if expiration.ExpiredObjectDeleteMarker.ValueBool() && (!expiration.Days.IsNull() || !expiration.Date.IsNull()) {
+				resp.Diagnostics.AddAttributeError(rulePath.AtName("expiration"), "Invalid expiration",
+					"expired_object_delete_marker cannot be combined with days or date.")
+			}
+		}
+	}
+}
+
+// ModifyPlan implements resource.ResourceWithModifyPlan.  It checks that
+// transitions use storage classes of the placement target of the bucket.
+func (r *bucketLifecycleConfigurationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	// rules and their transitions from dynamic blocks can be unknown, which
+	// the model cannot hold
+	var plan bucketLifecycleConfigurationResourceModel
+	var rules types.List
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("bucket"), &plan.Bucket)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("rule"), &rules)...)
+	if resp.Diagnostics.HasError() || plan.Bucket.IsUnknown() || rules.IsUnknown() {
+		return
+	}
+
+	type use struct {
+		path         path.Path
+		storageClass string
+	}
+	var uses []use
+	for i := range rules.Elements() {
+		for _, name := range lifecycleTransitionBlocks {
+			blockPath := path.Root("rule").AtListIndex(i).AtName(name)
+			var transitions types.List
+			resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, blockPath, &transitions)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+			if transitions.IsUnknown() {
+				continue
+			}
+			for j := range transitions.Elements() {
+				classPath := blockPath.AtListIndex(j).AtName("storage_class")
+				var storageClass types.String
+				resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, classPath, &storageClass)...)
+				if resp.Diagnostics.HasError() {
+					return
+				}
+				if !storageClass.IsUnknown() {
+					uses = append(uses, use{classPath, storageClass.ValueString()})
+				}
+			}
+		}
+	}
+	if len(uses) == 0 {
+		return
+	}
+
+	bucket, err := r.client.GetBucketInfo(ctx, admin.Bucket{Bucket: plan.Bucket.ValueString()})
+	if err != nil {
+		// creating the configuration fails with a better error
+		tflog.Debug(ctx, "not validating storage classes of missing bucket", map[string]any{"bucket": plan.Bucket.ValueString(), "error": err.Error()})
+		return
+	}
+
+	period, err := r.rawAdmin.getPeriod(ctx)
+	if err != nil {
+		hint := ""
+		if errors.Is(err, admin.ErrAccessDenied) {
+			hint = "  Add the \"zone=read\" cap to the user of the provider to enable this check."
+		}
+		resp.Diagnostics.AddWarning("Storage classes not validated",
+			fmt.Sprintf("Could not fetch the placement targets of radosgw to validate the storage classes of transitions: %s.%s", err, hint))
+		return
+	}
+
+	storageClasses, ok := period.storageClasses(bucket.PlacementRule)
+	if !ok {
+		tflog.Debug(ctx, "not validating storage classes of unknown placement target", map[string]any{"placement_rule": bucket.PlacementRule})
+		return
+	}
+
+	for _, u := range uses {
+		if !containsString(storageClasses, u.storageClass) {
+			resp.Diagnostics.AddAttributeError(u.path, "Unknown storage class",
+				fmt.Sprintf("The placement target %q of bucket %q has no storage class %q.  Available storage classes are: %s.",
+					placementName(bucket.PlacementRule), plan.Bucket.ValueString(), u.storageClass, strings.Join(storageClasses, ", ")))
+		}
+	}
+}
+
+// placementName returns the placement target of a placement rule.
+func placementName(placementRule string) string {
+	placement, _, _ := strings.Cut(placementRule, "/")
+	if placement == "" {
+		return "default"
+	}
+	return placement
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Read implements resource.Resource.
+func (r *bucketLifecycleConfigurationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state bucketLifecycleConfigurationResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	out, err := r.s3.GetBucketLifecycleConfigurationWithContext(ctx, &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(state.Bucket.ValueString()),
+	})
+	if code := awsErrorCode(err); code == "NoSuchLifecycleConfiguration" || code == "NoSuchBucket" {
+		tflog.Warn(ctx, "bucket lifecycle configuration removed outside of Terraform", map[string]any{"bucket": state.Bucket.ValueString(), "error_code": code})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error fetching bucket lifecycle configuration",
+			fmt.Sprintf("Could not fetch lifecycle configuration of bucket %q", state.Bucket.ValueString()), err)
+		return
+	}
+
+	state.Rules = lifecycleRulesFromS3(out.Rules, state.Rules)
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// ImportState implements resource.ResourceWithImportState.  Lifecycle
+// configurations are imported by the name of their bucket.
+func (r *bucketLifecycleConfigurationResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("bucket"), req, resp)
+}
+
+// Create implements resource.Resource.
+func (r *bucketLifecycleConfigurationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan bucketLifecycleConfigurationResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.put(ctx, plan); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error setting bucket lifecycle configuration",
+			fmt.Sprintf("Could not set lifecycle configuration of bucket %q", plan.Bucket.ValueString()), err)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *bucketLifecycleConfigurationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan bucketLifecycleConfigurationResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.put(ctx, plan); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error updating bucket lifecycle configuration",
+			fmt.Sprintf("Could not update lifecycle configuration of bucket %q", plan.Bucket.ValueString()), err)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// put sets the lifecycle configuration of the bucket to that of plan.
+func (r *bucketLifecycleConfigurationResource) put(ctx context.Context, plan bucketLifecycleConfigurationResourceModel) error {
+	rules := make([]*s3.LifecycleRule, 0, len(plan.Rules))
+	for _, rule := range plan.Rules {
+		s3Rule, err := rule.toS3()
+		if err != nil {
+			return fmt.Errorf("invalid lifecycle rule %q: %w", rule.ID.ValueString(), err)
+		}
+		rules = append(rules, s3Rule)
+	}
+
+	_, err := r.s3.PutBucketLifecycleConfigurationWithContext(ctx, &s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 aws.String(plan.Bucket.ValueString()),
+		LifecycleConfiguration: &s3.BucketLifecycleConfiguration{Rules: rules},
+	})
+	return err
+}
+
+// Delete implements resource.Resource.
+func (r *bucketLifecycleConfigurationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state bucketLifecycleConfigurationResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.s3.DeleteBucketLifecycleWithContext(ctx, &s3.DeleteBucketLifecycleInput{
+		Bucket: aws.String(state.Bucket.ValueString()),
+	})
+	if code := awsErrorCode(err); code == "NoSuchLifecycleConfiguration" || code == "NoSuchBucket" {
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error removing bucket lifecycle configuration",
+			fmt.Sprintf("Could not remove lifecycle configuration of bucket %q", state.Bucket.ValueString()), err)
+		return
+	}
+}
+
+// toS3 returns the rule for the S3 API.
+func (m lifecycleRuleModel) toS3() (*s3.LifecycleRule, error) {
+	rule := &s3.LifecycleRule{
+		ID:     aws.String(m.ID.ValueString()),
+		Status: aws.String(m.Status.ValueString()),
+		Filter: lifecycleFilterToS3(m.Filter),
+	}
+
+	for _, expiration := range m.Expiration {
+		rule.Expiration = &s3.LifecycleExpiration{
+			Days: int64Pointer(expiration.Days),
+		}
+		if expiration.ExpiredObjectDeleteMarker.ValueBool() {
+			rule.Expiration.ExpiredObjectDeleteMarker = aws.Bool(true)
+		}
+		date, err := timePointer(expiration.Date)
+		if err != nil {
+			return nil, err
+		}
+		rule.Expiration.Date = date
+	}
+
+	for _, expiration := range m.NoncurrentVersionExpiration {
+		rule.NoncurrentVersionExpiration = &s3.NoncurrentVersionExpiration{
+			NoncurrentDays:          int64Pointer(expiration.NoncurrentDays),
+			NewerNoncurrentVersions: int64Pointer(expiration.NewerNoncurrentVersions),
+		}
+	}
+
+	for _, abort := range m.AbortIncompleteMultipartUpload {
+		rule.AbortIncompleteMultipartUpload = &s3.AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: int64Pointer(abort.DaysAfterInitiation),
+		}
+	}
+
+	for _, transition := range m.Transitions {
+		date, err := timePointer(transition.Date)
+		if err != nil {
+			return nil, err
+		}
+		rule.Transitions = append(rule.Transitions, &s3.Transition{
+			Days:         int64Pointer(transition.Days),
+			Date:         date,
+			StorageClass: aws.String(transition.StorageClass.ValueString()),
+		})
+	}
+
+	for _, transition := range m.NoncurrentVersionTransitions {
+		rule.NoncurrentVersionTransitions = append(rule.NoncurrentVersionTransitions, &s3.NoncurrentVersionTransition{
+			NoncurrentDays:          int64Pointer(transition.NoncurrentDays),
+			NewerNoncurrentVersions: int64Pointer(transition.NewerNoncurrentVersions),
+			StorageClass:            aws.String(transition.StorageClass.ValueString()),
+		})
+	}
+
+	return rule, nil
+}
+
+// lifecycleFilterToS3 returns the filter for the S3 API.  Filters with more
+// than one condition are combined with And, as S3 requires.
+func lifecycleFilterToS3(filters []lifecycleFilterModel) *s3.LifecycleRuleFilter {
+	if len(filters) == 0 {
+		return &s3.LifecycleRuleFilter{Prefix: aws.String("")}
+	}
+	f := filters[0]
+
+	keys := make([]string, 0, len(f.Tags))
+	for key := range f.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	tags := make([]*s3.Tag, 0, len(keys))
+	for _, key := range keys {
+		tags = append(tags, &s3.Tag{Key: aws.String(key), Value: aws.String(f.Tags[key])})
+	}
+
+	conditions := len(tags)
+	for _, set := range []bool{!f.Prefix.IsNull(), !f.ObjectSizeGreaterThan.IsNull(), !f.ObjectSizeLessThan.IsNull()} {
+		if set {
+			conditions++
+		}
+	}
+
+	switch {
+	case conditions == 0:
+		return &s3.LifecycleRuleFilter{Prefix: aws.String("")}
+	case conditions > 1:
+		and := &s3.LifecycleRuleAndOperator{
+			Prefix:                f.Prefix.ValueStringPointer(),
+			ObjectSizeGreaterThan: f.ObjectSizeGreaterThan.ValueInt64Pointer(),
+			ObjectSizeLessThan:    f.ObjectSizeLessThan.ValueInt64Pointer(),
+		}
+		if len(tags) > 0 {
+			and.Tags = tags
+		}
+		return &s3.LifecycleRuleFilter{And: and}
+	case len(tags) == 1:
+		return &s3.LifecycleRuleFilter{Tag: tags[0]}
+	}
+	return &s3.LifecycleRuleFilter{
+		Prefix:                f.Prefix.ValueStringPointer(),
+		ObjectSizeGreaterThan: f.ObjectSizeGreaterThan.ValueInt64Pointer(),
+		ObjectSizeLessThan:    f.ObjectSizeLessThan.ValueInt64Pointer(),
+	}
+}
+
+// lifecycleRulesFromS3 returns the rules returned by radosgw, normalized to
+// the form written by the provider.
+//
+// radosgw returns the rules sorted by ID and transitions sorted by storage
+// class, which are put back into the order of prior, the rules in state.
+// Dates equal to those in prior keep their formatting.
+func lifecycleRulesFromS3(rules []*s3.LifecycleRule, prior []lifecycleRuleModel) []lifecycleRuleModel {
+	priorByID := make(map[string]lifecycleRuleModel, len(prior))
+	for _, rule := range prior {
+		priorByID[rule.ID.ValueString()] = rule
+	}
+
+	models := make([]lifecycleRuleModel, 0, len(rules))
+	for _, rule := range rules {
+		id := aws.StringValue(rule.ID)
+		priorRule := priorByID[id]
+
+		model := lifecycleRuleModel{
+			ID:     types.StringValue(id),
+			Status: types.StringValue(aws.StringValue(rule.Status)),
+			Filter: lifecycleFilterFromS3(rule),
+		}
+
+		if e := rule.Expiration; e != nil && (aws.Int64Value(e.Days) > 0 || e.Date != nil || aws.BoolValue(e.ExpiredObjectDeleteMarker)) {
+			var priorDate types.String
+			if len(priorRule.Expiration) > 0 {
+				priorDate = priorRule.Expiration[0].Date
+			}
+			model.Expiration = []lifecycleExpirationModel{{
+				Days:                      positiveInt64(e.Days),
+				Date:                      timestampValue(e.Date, priorDate),
+				ExpiredObjectDeleteMarker: types.BoolValue(aws.BoolValue(e.ExpiredObjectDeleteMarker)),
+			}}
+		}
+
+		if e := rule.NoncurrentVersionExpiration; e != nil && aws.Int64Value(e.NoncurrentDays) > 0 {
+			model.NoncurrentVersionExpiration = []lifecycleNoncurrentExpirationModel{{
+				NoncurrentDays:          positiveInt64(e.NoncurrentDays),
+				NewerNoncurrentVersions: positiveInt64(e.NewerNoncurrentVersions),
+			}}
+		}
+
+		if a := rule.AbortIncompleteMultipartUpload; a != nil && aws.Int64Value(a.DaysAfterInitiation) > 0 {
+			model.AbortIncompleteMultipartUpload = []lifecycleAbortModel{{
+				DaysAfterInitiation: positiveInt64(a.DaysAfterInitiation),
+			}}
+		}
+
+		priorDates := make(map[string]types.String)
+		for _, transition := range priorRule.Transitions {
+			priorDates[transition.StorageClass.ValueString()] = transition.Date
+		}
+		for _, t := range rule.Transitions {
+			model.Transitions = append(model.Transitions, lifecycleTransitionModel{
+				Days:         positiveInt64(t.Days),
+				Date:         timestampValue(t.Date, priorDates[aws.StringValue(t.StorageClass)]),
+				StorageClass: types.StringValue(aws.StringValue(t.StorageClass)),
+			})
+		}
+		model.Transitions = orderLike(model.Transitions, priorRule.Transitions, func(t lifecycleTransitionModel) string {
+			return t.StorageClass.ValueString()
+		})
+
+		for _, t := range rule.NoncurrentVersionTransitions {
+			model.NoncurrentVersionTransitions = append(model.NoncurrentVersionTransitions, lifecycleNoncurrentTransitionModel{
+				NoncurrentDays:          positiveInt64(t.NoncurrentDays),
+				NewerNoncurrentVersions: positiveInt64(t.NewerNoncurrentVersions),
+				StorageClass:            types.StringValue(aws.StringValue(t.StorageClass)),
+			})
+		}
+		model.NoncurrentVersionTransitions = orderLike(model.NoncurrentVersionTransitions, priorRule.NoncurrentVersionTransitions, func(t lifecycleNoncurrentTransitionModel) string {
+			return t.StorageClass.ValueString()
+		})
+
+		models = append(models, model)
+	}
+
+	return orderLike(models, prior, func(rule lifecycleRuleModel) string {
+		return rule.ID.ValueString()
+	})
+}
+
+// lifecycleFilterFromS3 returns the filter of rule, nil if it applies to all
+// objects.  Both the filter and the deprecated prefix of rules are
+// understood, as radosgw returns either.
+func lifecycleFilterFromS3(rule *s3.LifecycleRule) []lifecycleFilterModel {
+	var filter lifecycleFilterModel
+	prefix := aws.StringValue(rule.Prefix)
+	tags := make(map[string]string)
+
+	if f := rule.Filter; f != nil {
+		if f.Prefix != nil {
+			prefix = aws.StringValue(f.Prefix)
+		}
+		if f.Tag != nil {
+			tags[aws.StringValue(f.Tag.Key)] = aws.StringValue(f.Tag.Value)
+		}
+		filter.ObjectSizeGreaterThan = types.Int64PointerValue(f.ObjectSizeGreaterThan)
+		filter.ObjectSizeLessThan = types.Int64PointerValue(f.ObjectSizeLessThan)
+
+		if and := f.And; and != nil {
+			if and.Prefix != nil {
+				prefix = aws.StringValue(and.Prefix)
+			}
+			for _, tag := range and.Tags {
+				tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+			}
+			if and.ObjectSizeGreaterThan != nil {
+				filter.ObjectSizeGreaterThan = types.Int64PointerValue(and.ObjectSizeGreaterThan)
+			}
+			if and.ObjectSizeLessThan != nil {
+				filter.ObjectSizeLessThan = types.Int64PointerValue(and.ObjectSizeLessThan)
+			}
+		}
+	}
+
+	filter.Prefix = optionalString(prefix)
+	if len(tags) > 0 {
+		filter.Tags = tags
+	}
+
+	if filter.Prefix.IsNull() && filter.Tags == nil && filter.ObjectSizeGreaterThan.IsNull() && filter.ObjectSizeLessThan.IsNull() {
+		return nil
+	}
+	return []lifecycleFilterModel{filter}
+}
+
+// orderLike returns values in the order of the values with the same key in
+// prior, followed by the remaining values in their original order.
+func orderLike[T any](values, prior []T, key func(T) string) []T {
+	if len(values) == 0 {
+		return nil
+	}
+
+	position := make(map[string]int, len(prior))
+	for i, v := range prior {
+		position[key(v)] = i
+	}
+
+	ordered := make([]T, len(values))
+	copy(ordered, values)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		pi, iKnown := position[key(ordered[i])]
+		pj, jKnown := position[key(ordered[j])]
+		switch {
+		case iKnown && jKnown:
+			return pi < pj
+		case iKnown != jKnown:
+			return iKnown
+		}
+		return false
+	})
+	return ordered
+}
+
+// int64Pointer returns a pointer to the value of v, nil if it is null.
+func int64Pointer(v types.Int64) *int64 {
+	return v.ValueInt64Pointer()
+}
+
+// positiveInt64 returns v, or null if it is not set or zero, as radosgw
+// returns zero for unset numbers.
+func positiveInt64(v *int64) types.Int64 {
+	if v == nil || *v == 0 {
+		return types.Int64Null()
+	}
+	return types.Int64Value(*v)
+}
+
+// timePointer parses an RFC 3339 timestamp, returning nil if it is null.
+func timePointer(v types.String) (*time.Time, error) {
+	if v.IsNull() || v.IsUnknown() {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v.ValueString())
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q: %w", v.ValueString(), err)
+	}
+	return &t, nil
+}
+
+// timestampValue returns t as RFC 3339 timestamp, or prior if it is the same
+// time written differently.
+func timestampValue(t *time.Time, prior types.String) types.String {
+	if t == nil {
+		return types.StringNull()
+	}
+	if priorTime, err := time.Parse(time.RFC3339, prior.ValueString()); err == nil && priorTime.Equal(*t) {
+		return prior
+	}
+	return types.StringValue(t.UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,338 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccBucketLifecycleConfigurationResource(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck: func() {
+			testAccBucket(t, server)
+			server.AddStorageClass("default-placement", "COLD")
+		},
+		Steps: []resource.TestStep{
+			// Create and Read testing, the rules are returned sorted by ID
+			{
+				Config: providerConfig + testAccBucketLifecycleConfigurationResourceConfig(30),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_bucket_lifecycle_configuration.test", "rule.#", "2"),
+					resource.TestCheckResourceAttr("radosgw_bucket_lifecycle_configuration.test", "rule.0.id", "logs"),
+					resource.TestCheckResourceAttr("radosgw_bucket_lifecycle_configuration.test", "rule.0.status", "Enabled"),
+					resource.TestCheckResourceAttr("radosgw_bucket_lifecycle_configuration.test", "rule.0.expiration.0.expired_object_delete_marker", "false"),
+					resource.TestCheckResourceAttr("radosgw_bucket_lifecycle_configuration.test", "rule.1.id", "archive"),
+					testAccCheckBucketSubresource(server, "assets", "lifecycle", regexp.MustCompile(`<StorageClass>COLD</StorageClass>`)),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "radosgw_bucket_lifecycle_configuration.test",
+				ImportState:                          true,
+				ImportStateId:                        "assets",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "bucket",
+				// imported rules are in the order returned by radosgw
+				ImportStateVerifyIgnore: []string{"rule"},
+			},
+			// Update and Read testing
+			{
+				Config: providerConfig + testAccBucketLifecycleConfigurationResourceConfig(60),
+				Check:  testAccCheckBucketSubresource(server, "assets", "lifecycle", regexp.MustCompile(`<Days>60</Days>`)),
+			},
+			// Removed outside of Terraform
+			{
+				PreConfig: func() {
+					server.RemoveBucketSubresource("assets", "lifecycle")
+				},
+				Config:             providerConfig + testAccBucketLifecycleConfigurationResourceConfig(60),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccBucketLifecycleConfigurationResource_dynamic(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	// the blocks are unknown until terraform_data is applied, so each step
+	// uses a new one
+	config := func(data, rule string) string {
+		return providerConfig + `
+resource "terraform_data" "` + data + `" {
+  input = { logs = 30 }
+}
+
+resource "radosgw_bucket_lifecycle_configuration" "test" {
+  bucket = "assets"
+` + rule + `
+}
+`
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck: func() {
+			testAccBucket(t, server)
+			server.AddStorageClass("default-placement", "COLD")
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config("rules", `
+  dynamic "rule" {
+    for_each = terraform_data.rules.output
+    content {
+      id = rule.key
+
+      expiration {
+        days = rule.value
+      }
+    }
+  }
+`),
+				Check: testAccCheckBucketSubresource(server, "assets", "lifecycle", regexp.MustCompile(`<Days>30</Days>`)),
+			},
+			{
+				Config: config("transitions", `
+  rule {
+    id = "logs"
+
+    dynamic "transition" {
+      for_each = terraform_data.transitions.output
+      content {
+        days          = transition.value
+        storage_class = "COLD"
+      }
+    }
+  }
+`),
+				Check: testAccCheckBucketSubresource(server, "assets", "lifecycle", regexp.MustCompile(`<StorageClass>COLD</StorageClass>`)),
+			},
+		},
+	})
+}
+
+func TestAccBucketLifecycleConfigurationResource_unknownStorageClass(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccBucket(t, server) },
+		Steps: []resource.TestStep{
+			{
+				Config:      providerConfig + testAccBucketLifecycleConfigurationResourceConfig(30),
+				ExpectError: regexp.MustCompile(`has no storage\s+class "COLD"\.\s+Available storage classes are: STANDARD`),
+			},
+		},
+	})
+}
+
+func TestAccBucketLifecycleConfigurationResource_withoutAction(t *testing.T) {
+	_, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "radosgw_bucket_lifecycle_configuration" "test" {
+  bucket = "assets"
+
+  rule {
+    id = "nothing"
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`Lifecycle rule without action`),
+			},
+		},
+	})
+}
+
+func testAccBucketLifecycleConfigurationResourceConfig(days int) string {
+	return fmt.Sprintf(`
+resource "radosgw_bucket_lifecycle_configuration" "test" {
+  bucket = "assets"
+
+  rule {
+    id = "logs"
+
+    filter {
+      prefix = "logs/"
+      tags   = { retention = "short" }
+    }
+
+    expiration {
+      days = %d
+    }
+
+    abort_incomplete_multipart_upload {
+      days_after_initiation = 2
+    }
+  }
+
+  rule {
+    id = "archive"
+
+    filter {
+      object_size_greater_than = 1048576
+    }
+
+    transition {
+      days          = 7
+      storage_class = "COLD"
+    }
+
+    noncurrent_version_expiration {
+      noncurrent_days           = 90
+      newer_noncurrent_versions = 3
+    }
+  }
+}
+`, days)
+}
+
+func TestLifecycleRulesFromS3(t *testing.T) {
+	date := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	prior := []lifecycleRuleModel{
+		{
+			ID: types.StringValue("b"),
+			Transitions: []lifecycleTransitionModel{
+				{Date: types.StringValue("2030-01-01T01:00:00+01:00"), StorageClass: types.StringValue("WARM")},
+				{Days: types.Int64Value(30), StorageClass: types.StringValue("COLD")},
+			},
+		},
+		{ID: types.StringValue("a")},
+	}
+
+	rules := lifecycleRulesFromS3([]*s3.LifecycleRule{
+		{
+			ID:     aws.String("a"),
+			Status: aws.String("Enabled"),
+			Prefix: aws.String("tmp/"),
+			Expiration: &s3.LifecycleExpiration{
+				Days:                      aws.Int64(1),
+				ExpiredObjectDeleteMarker: aws.Bool(false),
+			},
+			NoncurrentVersionExpiration: &s3.NoncurrentVersionExpiration{NoncurrentDays: aws.Int64(0)},
+		},
+		{
+			ID:     aws.String("b"),
+			Status: aws.String("Disabled"),
+			Filter: &s3.LifecycleRuleFilter{And: &s3.LifecycleRuleAndOperator{
+				Prefix: aws.String(""),
+				Tags:   []*s3.Tag{{Key: aws.String("k"), Value: aws.String("v")}},
+			}},
+			Transitions: []*s3.Transition{
+				{Days: aws.Int64(30), StorageClass: aws.String("COLD")},
+				{Date: &date, StorageClass: aws.String("WARM")},
+			},
+		},
+		{
+			ID:     aws.String("c"),
+			Status: aws.String("Enabled"),
+			Filter: &s3.LifecycleRuleFilter{Prefix: aws.String("")},
+			AbortIncompleteMultipartUpload: &s3.AbortIncompleteMultipartUpload{
+				DaysAfterInitiation: aws.Int64(3),
+			},
+		},
+	}, prior)
+
+	var ids []string
+	for _, rule := range rules {
+		ids = append(ids, rule.ID.ValueString())
+	}
+	if fmt.Sprint(ids) != "[b a c]" {
+		t.Fatalf("unexpected rule order %v", ids)
+	}
+
+	b := rules[0]
+	if len(b.Filter) != 1 || b.Filter[0].Tags["k"] != "v" || !b.Filter[0].Prefix.IsNull() {
+		t.Errorf("unexpected filter of b: %+v", b.Filter)
+	}
+	if len(b.Transitions) != 2 || b.Transitions[0].StorageClass.ValueString() != "WARM" ||
+		b.Transitions[0].Date.ValueString() != "2030-01-01T01:00:00+01:00" || b.Transitions[1].Days.ValueInt64() != 30 {
+		t.Errorf("unexpected transitions of b: %+v", b.Transitions)
+	}
+
+	a := rules[1]
+	if len(a.Filter) != 1 || a.Filter[0].Prefix.ValueString() != "tmp/" {
+		t.Errorf("unexpected filter of a: %+v", a.Filter)
+	}
+	if len(a.Expiration) != 1 || a.Expiration[0].Days.ValueInt64() != 1 || !a.Expiration[0].Date.IsNull() {
+		t.Errorf("unexpected expiration of a: %+v", a.Expiration)
+	}
+	if a.NoncurrentVersionExpiration != nil {
+		t.Errorf("unexpected noncurrent version expiration of a: %+v", a.NoncurrentVersionExpiration)
+	}
+
+	c := rules[2]
+	if c.Filter != nil || len(c.AbortIncompleteMultipartUpload) != 1 {
+		t.Errorf("unexpected rule c: %+v", c)
+	}
+}
+
+func TestPeriodStorageClasses(t *testing.T) {
+	var period rgwPeriod
+	period.PeriodMap.Zonegroups = []rgwZonegroup{
+		{
+			DefaultPlacement: "default-placement",
+			PlacementTargets: []rgwPlacementTarget{
+				{Name: "default-placement", StorageClasses: []string{"STANDARD", "COLD"}},
+				{Name: "legacy"},
+			},
+		},
+		{
+			DefaultPlacement: "default-placement",
+			PlacementTargets: []rgwPlacementTarget{
+				{Name: "default-placement", StorageClasses: []string{"STANDARD", "GLACIER"}},
+			},
+		},
+	}
+
+	for _, test := range []struct {
+		placementRule string
+		classes       []string
+		ok            bool
+	}{
+		{"", []string{"COLD", "GLACIER", "STANDARD"}, true},
+		{"default-placement/COLD", []string{"COLD", "GLACIER", "STANDARD"}, true},
+		{"legacy", []string{"STANDARD"}, true},
+		{"missing", nil, false},
+	} {
+		classes, ok := period.storageClasses(test.placementRule)
+		if fmt.Sprint(classes) != fmt.Sprint(test.classes) || ok != test.ok {
+			t.Errorf("storageClasses(%q) = %v, %v, expected %v, %v", test.placementRule, classes, ok, test.classes, test.ok)
+		}
+	}
+}
+
+func TestBucketLifecycleConfigurationPutInvalidRule(t *testing.T) {
+	// the rule is rejected before the S3 API is called, so no client is
+	// needed
+	r := &bucketLifecycleConfigurationResource{}
+	plan := bucketLifecycleConfigurationResourceModel{
+		Bucket: types.StringValue("assets"),
+		Rules: []lifecycleRuleModel{{
+			ID:         types.StringValue("logs"),
+			Status:     types.StringValue("Enabled"),
+			Expiration: []lifecycleExpirationModel{{Days: types.Int64Null(), Date: types.StringValue("tomorrow")}},
+		}},
+	}
+
+	err := r.put(context.Background(), plan)
+	if err == nil || !regexp.MustCompile(`invalid lifecycle rule "logs": invalid date "tomorrow"`).MatchString(err.Error()) {
+		t.Errorf("got error %v", err)
+	}
+}
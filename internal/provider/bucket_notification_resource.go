@@ -0,0 +1,384 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &bucketNotificationResource{}
+	_ resource.ResourceWithConfigure      = &bucketNotificationResource{}
+	_ resource.ResourceWithImportState    = &bucketNotificationResource{}
+	_ resource.ResourceWithValidateConfig = &bucketNotificationResource{}
+)
+
+// notificationEvents are the event types of bucket notifications that
+// radosgw supports.
+var notificationEvents = []string{
+	"s3:ObjectCreated:*",
+	"s3:ObjectCreated:Put",
+	"s3:ObjectCreated:Post",
+	"s3:ObjectCreated:Copy",
+	"s3:ObjectCreated:CompleteMultipartUpload",
+	"s3:ObjectRemoved:*",
+	"s3:ObjectRemoved:Delete",
+	"s3:ObjectRemoved:DeleteMarkerCreated",
+	"s3:ObjectLifecycle:Expiration:*",
+	"s3:ObjectLifecycle:Expiration:Current",
+	"s3:ObjectLifecycle:Expiration:NonCurrent",
+	"s3:ObjectLifecycle:Expiration:DeleteMarker",
+	"s3:ObjectLifecycle:Expiration:AbortMultipartUpload",
+	"s3:ObjectLifecycle:Transition:*",
+	"s3:ObjectLifecycle:Transition:Current",
+	"s3:ObjectLifecycle:Transition:NonCurrent",
+	"s3:ObjectSynced:*",
+	"s3:ObjectSynced:Create",
+	"s3:ObjectSynced:Delete",
+	"s3:ObjectSynced:DeletionMarkerCreated",
+}
+
+// NewBucketNotificationResource is a helper function to simplify the provider implementation.
+func NewBucketNotificationResource() resource.Resource {
+	return &bucketNotificationResource{}
+}
+
+// bucketNotificationResource is the resource implementation.
+type bucketNotificationResource struct {
+	s3 s3iface.S3API
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *bucketNotificationResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
+		return
+	}
+
+	r.s3 = s3.New(data.awsSession)
+}
+
+// Metadata returns the resource type name.
+func (r *bucketNotificationResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_notification"
+}
+
+// Schema defines the schema for the resource.
+func (r *bucketNotificationResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the notifications of a bucket through the S3 API of radosgw, which send events of the objects of the bucket to topics.  The provider credentials must belong to the owner of the bucket or to a system user.  All notifications of the bucket are managed by this resource.",
+		Attributes: map[string]schema.Attribute{
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Name of the bucket.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"topic": schema.ListNestedBlock{
+				MarkdownDescription: "Notification sending events to a topic.",
+				Validators: []validator.List{
+					listvalidator.IsRequired(),
+					listvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "Unique ID of the notification, which is part of the events sent.",
+							Required:            true,
+							Validators: []validator.String{
+								stringvalidator.LengthAtLeast(1),
+							},
+						},
+						"topic_arn": schema.StringAttribute{
+							MarkdownDescription: "ARN of the topic, such as the `arn` of a `radosgw_topic`.",
+							Required:            true,
+						},
+						"events": schema.SetAttribute{
+							MarkdownDescription: "Events to send, such as `s3:ObjectCreated:*`.",
+							ElementType:         types.StringType,
+							Required:            true,
+							Validators: []validator.Set{
+								setvalidator.SizeAtLeast(1),
+								setvalidator.ValueStringsAre(stringvalidator.OneOf(notificationEvents...)),
+							},
+						},
+						"filter_prefix": schema.StringAttribute{
+							MarkdownDescription: "Prefix of the keys of the objects.",
+							Optional:            true,
+						},
+						"filter_suffix": schema.StringAttribute{
+							MarkdownDescription: "Suffix of the keys of the objects.",
+							Optional:            true,
+						},
+						"filter_regex": schema.StringAttribute{
+							MarkdownDescription: "Regular expression the keys of the objects must match.",
+							Optional:            true,
+						},
+						"filter_metadata": schema.MapAttribute{
+							MarkdownDescription: "Metadata the objects must have, by header name such as `x-amz-meta-kind`.",
+							ElementType:         types.StringType,
+							Optional:            true,
+							Validators: []validator.Map{
+								mapvalidator.KeysAre(stringvalidator.RegexMatches(metadataHeaderPattern, "must be a metadata header starting with x-amz-meta-")),
+							},
+						},
+						"filter_tags": schema.MapAttribute{
+							MarkdownDescription: "Tags the objects must have.",
+							ElementType:         types.StringType,
+							Optional:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+type bucketNotificationResourceModel struct {
+	Bucket types.String              `tfsdk:"bucket"`
+	Topics []bucketNotificationModel `tfsdk:"topic"`
+}
+
+type bucketNotificationModel struct {
+	ID             types.String      `tfsdk:"id"`
+	TopicARN       types.String      `tfsdk:"topic_arn"`
+	Events         []string          `tfsdk:"events"`
+	FilterPrefix   types.String      `tfsdk:"filter_prefix"`
+	FilterSuffix   types.String      `tfsdk:"filter_suffix"`
+	FilterRegex    types.String      `tfsdk:"filter_regex"`
+	FilterMetadata map[string]string `tfsdk:"filter_metadata"`
+	FilterTags     map[string]string `tfsdk:"filter_tags"`
+}
+
+// errorTarget describes the notifications for explaining S3 API errors.
+func (m bucketNotificationResourceModel) errorTarget() rgwErrorTarget {
+	return rgwErrorTarget{
+		resourceType: "radosgw_bucket_notification",
+		importID:     m.Bucket.ValueString(),
+		bucketPath:   path.Root("bucket"),
+	}
+}
+
+// ValidateConfig implements resource.ResourceWithValidateConfig.
+func (r *bucketNotificationResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	// topics of dynamic blocks can be unknown, which the model cannot hold
+	var topics types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("topic"), &topics)...)
+	if resp.Diagnostics.HasError() || topics.IsUnknown() {
+		return
+	}
+
+	ids := make(map[string]bool)
+	for i, topic := range topics.Elements() {
+		object, ok := topic.(types.Object)
+		if !ok || object.IsUnknown() {
+			continue
+		}
+		id, ok := object.Attributes()["id"].(types.String)
+		if !ok || id.IsNull() || id.IsUnknown() {
+			continue
+		}
+		if ids[id.ValueString()] {
+			resp.Diagnostics.AddAttributeError(path.Root("topic").AtListIndex(i).AtName("id"), "Duplicate notification ID",
+				fmt.Sprintf("The notification ID %q is used more than once, radosgw needs unique notification IDs.", id.ValueString()))
+		}
+		ids[id.ValueString()] = true
+	}
+}
+
+// Read implements resource.Resource.
+func (r *bucketNotificationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state bucketNotificationResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	config, err := getBucketNotification(ctx, r.s3, state.Bucket.ValueString())
+	if awsErrorCode(err) == "NoSuchBucket" {
+		tflog.Warn(ctx, "bucket of notifications removed outside of Terraform", map[string]any{"bucket": state.Bucket.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error fetching bucket notifications",
+			fmt.Sprintf("Could not fetch notifications of bucket %q", state.Bucket.ValueString()), err)
+		return
+	}
+	if len(config.TopicConfigurations) == 0 {
+		tflog.Warn(ctx, "bucket notifications removed outside of Terraform", map[string]any{"bucket": state.Bucket.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	topics := make([]bucketNotificationModel, 0, len(config.TopicConfigurations))
+	for _, t := range config.TopicConfigurations {
+		topics = append(topics, t.model())
+	}
+	state.Topics = orderLike(topics, state.Topics, func(t bucketNotificationModel) string {
+		return t.ID.ValueString()
+	})
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// ImportState implements resource.ResourceWithImportState.  Notifications
+// are imported by the name of their bucket.
+func (r *bucketNotificationResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("bucket"), req, resp)
+}
+
+// Create implements resource.Resource.
+func (r *bucketNotificationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan bucketNotificationResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := putBucketNotification(ctx, r.s3, plan.Bucket.ValueString(), plan.configuration()); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error setting bucket notifications",
+			fmt.Sprintf("Could not set notifications of bucket %q", plan.Bucket.ValueString()), err)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *bucketNotificationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan bucketNotificationResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := putBucketNotification(ctx, r.s3, plan.Bucket.ValueString(), plan.configuration()); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error updating bucket notifications",
+			fmt.Sprintf("Could not update notifications of bucket %q", plan.Bucket.ValueString()), err)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete implements resource.Resource.  radosgw removes all notifications of
+// a bucket when setting an empty configuration.
+func (r *bucketNotificationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state bucketNotificationResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err := putBucketNotification(ctx, r.s3, state.Bucket.ValueString(), &notificationConfiguration{})
+	if awsErrorCode(err) == "NoSuchBucket" {
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error removing bucket notifications",
+			fmt.Sprintf("Could not remove notifications of bucket %q", state.Bucket.ValueString()), err)
+		return
+	}
+}
+
+// configuration returns the notification configuration for the S3 API.
+func (m bucketNotificationResourceModel) configuration() *notificationConfiguration {
+	config := &notificationConfiguration{}
+	for _, topic := range m.Topics {
+		t := &topicConfiguration{
+			ID:       aws.String(topic.ID.ValueString()),
+			TopicArn: aws.String(topic.TopicARN.ValueString()),
+			Events:   aws.StringSlice(sortedSet(topic.Events)),
+		}
+
+		var filter notificationFilter
+		for _, rule := range []struct {
+			name  string
+			value types.String
+		}{{"prefix", topic.FilterPrefix}, {"suffix", topic.FilterSuffix}, {"regex", topic.FilterRegex}} {
+			if !rule.value.IsNull() {
+				filter.Key = filter.Key.add(rule.name, rule.value.ValueString())
+			}
+		}
+		for _, name := range sortedMapKeys(topic.FilterMetadata) {
+			filter.Metadata = filter.Metadata.add(name, topic.FilterMetadata[name])
+		}
+		for _, name := range sortedMapKeys(topic.FilterTags) {
+			filter.Tags = filter.Tags.add(name, topic.FilterTags[name])
+		}
+		if filter.Key != nil || filter.Metadata != nil || filter.Tags != nil {
+			t.Filter = &filter
+		}
+
+		config.TopicConfigurations = append(config.TopicConfigurations, t)
+	}
+	return config
+}
+
+// model returns the notification as returned by radosgw, which may leave
+// out empty filters.
+func (t *topicConfiguration) model() bucketNotificationModel {
+	m := bucketNotificationModel{
+		ID:           types.StringValue(aws.StringValue(t.ID)),
+		TopicARN:     types.StringValue(aws.StringValue(t.TopicArn)),
+		Events:       aws.StringValueSlice(t.Events),
+		FilterPrefix: types.StringNull(),
+		FilterSuffix: types.StringNull(),
+		FilterRegex:  types.StringNull(),
+	}
+	if t.Filter == nil {
+		return m
+	}
+
+	for name, value := range t.Filter.Key.values() {
+		switch name {
+		case "prefix":
+			m.FilterPrefix = optionalString(value)
+		case "suffix":
+			m.FilterSuffix = optionalString(value)
+		case "regex":
+			m.FilterRegex = optionalString(value)
+		}
+	}
+	m.FilterMetadata = t.Filter.Metadata.values()
+	m.FilterTags = t.Filter.Tags.values()
+	return m
+}
+
+// sortedMapKeys returns the keys of m in order.
+func sortedMapKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
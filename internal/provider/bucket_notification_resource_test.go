@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccBucketNotificationResource(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccBucket(t, server) },
+		Steps: []resource.TestStep{
+			// Create and Read testing, the notifications are returned sorted
+			// by ID
+			{
+				Config: providerConfig + testAccBucketNotificationResourceConfig(".jpg"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_bucket_notification.test", "topic.#", "2"),
+					resource.TestCheckResourceAttr("radosgw_bucket_notification.test", "topic.0.id", "uploads"),
+					resource.TestCheckResourceAttr("radosgw_bucket_notification.test", "topic.0.filter_metadata.x-amz-meta-kind", "image"),
+					resource.TestCheckResourceAttr("radosgw_bucket_notification.test", "topic.1.id", "deletions"),
+					testAccCheckBucketSubresource(server, "assets", "notification", regexp.MustCompile(`<S3Metadata><FilterRule>(<Value>image</Value>)?<Name>x-amz-meta-kind</Name>`)),
+					testAccCheckBucketSubresource(server, "assets", "notification", regexp.MustCompile(`<S3Tags><FilterRule>(<Value>media</Value>)?<Name>team</Name>`)),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "radosgw_bucket_notification.test",
+				ImportState:                          true,
+				ImportStateId:                        "assets",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "bucket",
+				// imported notifications are in the order returned by radosgw
+				ImportStateVerifyIgnore: []string{"topic"},
+			},
+			// Update and Read testing
+			{
+				Config: providerConfig + testAccBucketNotificationResourceConfig(".png"),
+				Check:  testAccCheckBucketSubresource(server, "assets", "notification", regexp.MustCompile(`<Value>.png</Value>`)),
+			},
+			// Removed outside of Terraform
+			{
+				PreConfig: func() {
+					server.RemoveBucketSubresource("assets", "notification")
+				},
+				Config:             providerConfig + testAccBucketNotificationResourceConfig(".png"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccBucketNotificationResource_unsupportedEvent(t *testing.T) {
+	_, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "radosgw_bucket_notification" "test" {
+  bucket = "assets"
+
+  topic {
+    id        = "restores"
+    topic_arn = "arn:aws:sns:default::events"
+    events    = ["s3:ObjectRestore:Completed"]
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`value must be\s+one of`),
+			},
+		},
+	})
+}
+
+func TestAccBucketNotificationResource_dynamic(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccBucket(t, server) },
+		Steps: []resource.TestStep{
+			// the topics are unknown until terraform_data is applied
+			{
+				Config: providerConfig + `
+resource "radosgw_topic" "test" {
+  name          = "events"
+  push_endpoint = "https://receiver.example.com/events"
+}
+
+resource "terraform_data" "topics" {
+  input = {
+    uploads   = { events = ["s3:ObjectCreated:*"], tags = { team = "media" } }
+    deletions = { events = ["s3:ObjectRemoved:*"], tags = { team = "ops" } }
+  }
+}
+
+resource "radosgw_bucket_notification" "test" {
+  bucket = "assets"
+
+  dynamic "topic" {
+    for_each = terraform_data.topics.output
+    content {
+      id          = topic.key
+      topic_arn   = radosgw_topic.test.arn
+      events      = topic.value.events
+      filter_tags = topic.value.tags
+    }
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_bucket_notification.test", "topic.#", "2"),
+					testAccCheckBucketSubresource(server, "assets", "notification", regexp.MustCompile(`<Id>uploads</Id>`)),
+				),
+			},
+		},
+	})
+}
+
+func testAccBucketNotificationResourceConfig(suffix string) string {
+	return fmt.Sprintf(`
+resource "radosgw_topic" "test" {
+  name          = "events"
+  push_endpoint = "https://receiver.example.com/events"
+}
+
+resource "radosgw_bucket_notification" "test" {
+  bucket = "assets"
+
+  topic {
+    id            = "uploads"
+    topic_arn     = radosgw_topic.test.arn
+    events        = ["s3:ObjectCreated:Put", "s3:ObjectCreated:CompleteMultipartUpload"]
+    filter_prefix = "images/"
+    filter_suffix = %q
+
+    filter_metadata = {
+      x-amz-meta-kind = "image"
+    }
+
+    filter_tags = {
+      team = "media"
+    }
+  }
+
+  topic {
+    id        = "deletions"
+    topic_arn = radosgw_topic.test.arn
+    events    = ["s3:ObjectRemoved:*"]
+  }
+}
+`, suffix)
+}
@@ -0,0 +1,277 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &bucketObjectLockConfigurationResource{}
+	_ resource.ResourceWithConfigure   = &bucketObjectLockConfigurationResource{}
+	_ resource.ResourceWithImportState = &bucketObjectLockConfigurationResource{}
+	_ resource.ResourceWithModifyPlan  = &bucketObjectLockConfigurationResource{}
+)
+
+// objectLockPermanentDetail explains why object lock is not removed.
+const objectLockPermanentDetail = "radosgw cannot disable object lock of a bucket once enabled, and keeps versioning of the bucket enabled.  " +
+	"The default retention is kept as well, to stop locking new objects remove the default_retention block instead."
+
+// NewBucketObjectLockConfigurationResource is a helper function to simplify the provider implementation.
+func NewBucketObjectLockConfigurationResource() resource.Resource {
+	return &bucketObjectLockConfigurationResource{}
+}
+
+// bucketObjectLockConfigurationResource is the resource implementation.
+type bucketObjectLockConfigurationResource struct {
+	s3 s3iface.S3API
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *bucketObjectLockConfigurationResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
+		return
+	}
+
+	r.s3 = s3.New(data.awsSession)
+}
+
+// Metadata returns the resource type name.
+func (r *bucketObjectLockConfigurationResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_object_lock_configuration"
+}
+
+// Schema defines the schema for the resource.
+func (r *bucketObjectLockConfigurationResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Enables object lock on a bucket and manages its default retention through the S3 API of radosgw.  The provider credentials must belong to the owner of the bucket or to a system user.  " +
+			"Object lock needs versioning enabled on the bucket, for example with a `radosgw_bucket_versioning` resource.  " +
+			"As object lock cannot be disabled again, destroying this resource only removes it from the state, keeping object lock and the default retention of the bucket.",
+		Attributes: map[string]schema.Attribute{
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Name of the bucket.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"default_retention": schema.ListNestedBlock{
+				MarkdownDescription: "Retention of new objects without retention of their own.  If not set, objects are only locked when requested.",
+				Validators: []validator.List{
+					listvalidator.SizeAtMost(1),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"mode": schema.StringAttribute{
+							MarkdownDescription: "Either `GOVERNANCE`, which users with the `s3:BypassGovernanceRetention` permission can bypass, or `COMPLIANCE`, which nobody can bypass.",
+							Required:            true,
+							Validators: []validator.String{
+								stringvalidator.OneOf(s3.ObjectLockRetentionMode_Values()...),
+							},
+						},
+						"days": schema.Int64Attribute{
+							MarkdownDescription: "Retention period in days.  Exactly one of `days` and `years` must be set.",
+							Optional:            true,
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+								int64validator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("years")),
+							},
+						},
+						"years": schema.Int64Attribute{
+							MarkdownDescription: "Retention period in years.",
+							Optional:            true,
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+type bucketObjectLockConfigurationResourceModel struct {
+	Bucket           types.String               `tfsdk:"bucket"`
+	DefaultRetention []objectLockRetentionModel `tfsdk:"default_retention"`
+}
+
+type objectLockRetentionModel struct {
+	Mode  types.String `tfsdk:"mode"`
+	Days  types.Int64  `tfsdk:"days"`
+	Years types.Int64  `tfsdk:"years"`
+}
+
+// errorTarget describes the object lock configuration for explaining S3 API
+// errors.
+func (m bucketObjectLockConfigurationResourceModel) errorTarget() rgwErrorTarget {
+	return rgwErrorTarget{
+		resourceType: "radosgw_bucket_object_lock_configuration",
+		importID:     m.Bucket.ValueString(),
+		bucketPath:   path.Root("bucket"),
+	}
+}
+
+// ModifyPlan implements resource.ResourceWithModifyPlan.  It warns about
+// planned destroys, which leave object lock enabled.
+func (r *bucketObjectLockConfigurationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if !req.Plan.Raw.IsNull() || req.State.Raw.IsNull() {
+		return
+	}
+
+	resp.Diagnostics.AddWarning("Object lock cannot be removed", "Destroying a radosgw_bucket_object_lock_configuration only removes it from Terraform state.  "+objectLockPermanentDetail)
+}
+
+// Read implements resource.Resource.
+func (r *bucketObjectLockConfigurationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state bucketObjectLockConfigurationResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	out, err := r.s3.GetObjectLockConfigurationWithContext(ctx, &s3.GetObjectLockConfigurationInput{
+		Bucket: aws.String(state.Bucket.ValueString()),
+	})
+	if code := awsErrorCode(err); code == "ObjectLockConfigurationNotFoundError" || code == "NoSuchBucket" {
+		tflog.Warn(ctx, "bucket object lock configuration removed outside of Terraform", map[string]any{"bucket": state.Bucket.ValueString(), "error_code": code})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error fetching bucket object lock configuration",
+			fmt.Sprintf("Could not fetch object lock configuration of bucket %q", state.Bucket.ValueString()), err)
+		return
+	}
+
+	config := out.ObjectLockConfiguration
+	if config == nil || aws.StringValue(config.ObjectLockEnabled) != s3.ObjectLockEnabledEnabled {
+		tflog.Warn(ctx, "bucket object lock disabled outside of Terraform", map[string]any{"bucket": state.Bucket.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.DefaultRetention = nil
+	if config.Rule != nil && config.Rule.DefaultRetention != nil {
+		retention := config.Rule.DefaultRetention
+		state.DefaultRetention = []objectLockRetentionModel{{
+			Mode:  types.StringValue(aws.StringValue(retention.Mode)),
+			Days:  positiveInt64(retention.Days),
+			Years: positiveInt64(retention.Years),
+		}}
+	}
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// ImportState implements resource.ResourceWithImportState.  Object lock
+// configurations are imported by the name of their bucket.
+func (r *bucketObjectLockConfigurationResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("bucket"), req, resp)
+}
+
+// Create implements resource.Resource.
+func (r *bucketObjectLockConfigurationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan bucketObjectLockConfigurationResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.put(ctx, plan); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error enabling bucket object lock",
+			fmt.Sprintf("Could not enable object lock of bucket %q", plan.Bucket.ValueString()), err)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *bucketObjectLockConfigurationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan bucketObjectLockConfigurationResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.put(ctx, plan); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error updating bucket object lock configuration",
+			fmt.Sprintf("Could not update object lock configuration of bucket %q", plan.Bucket.ValueString()), err)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// put enables object lock of the bucket with the default retention of plan.
+func (r *bucketObjectLockConfigurationResource) put(ctx context.Context, plan bucketObjectLockConfigurationResourceModel) error {
+	config := &s3.ObjectLockConfiguration{
+		ObjectLockEnabled: aws.String(s3.ObjectLockEnabledEnabled),
+	}
+	for _, retention := range plan.DefaultRetention {
+		config.Rule = &s3.ObjectLockRule{
+			DefaultRetention: &s3.DefaultRetention{
+				Mode:  aws.String(retention.Mode.ValueString()),
+				Days:  retention.Days.ValueInt64Pointer(),
+				Years: retention.Years.ValueInt64Pointer(),
+			},
+		}
+	}
+
+	_, err := r.s3.PutObjectLockConfigurationWithContext(ctx, &s3.PutObjectLockConfigurationInput{
+		Bucket:                  aws.String(plan.Bucket.ValueString()),
+		ObjectLockConfiguration: config,
+	})
+	return err
+}
+
+// Delete implements resource.Resource.  Object lock cannot be disabled, so
+// it is only removed from the state, with a warning unless the bucket is gone.
+func (r *bucketObjectLockConfigurationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state bucketObjectLockConfigurationResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.s3.GetObjectLockConfigurationWithContext(ctx, &s3.GetObjectLockConfigurationInput{
+		Bucket: aws.String(state.Bucket.ValueString()),
+	})
+	if code := awsErrorCode(err); code == "ObjectLockConfigurationNotFoundError" || code == "NoSuchBucket" {
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error fetching bucket object lock configuration",
+			fmt.Sprintf("Could not fetch object lock configuration of bucket %q", state.Bucket.ValueString()), err)
+		return
+	}
+
+	resp.Diagnostics.AddWarning("Object lock left enabled",
+		fmt.Sprintf("Object lock of bucket %q stays enabled and was only removed from Terraform state.  %s", state.Bucket.ValueString(), objectLockPermanentDetail))
+}
@@ -0,0 +1,142 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccBucketObjectLockConfigurationResource(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccBucket(t, server) },
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + testAccBucketObjectLockConfigurationResourceConfig(`
+  default_retention {
+    mode = "GOVERNANCE"
+    days = 30
+  }`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_bucket_object_lock_configuration.test", "default_retention.0.mode", "GOVERNANCE"),
+					resource.TestCheckResourceAttr("radosgw_bucket_object_lock_configuration.test", "default_retention.0.days", "30"),
+					resource.TestCheckNoResourceAttr("radosgw_bucket_object_lock_configuration.test", "default_retention.0.years"),
+					testAccCheckBucketSubresource(server, "assets", "object-lock", regexp.MustCompile(`<Days>30</Days>`)),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "radosgw_bucket_object_lock_configuration.test",
+				ImportState:                          true,
+				ImportStateId:                        "assets",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "bucket",
+			},
+			// Update and Read testing
+			{
+				Config: providerConfig + testAccBucketObjectLockConfigurationResourceConfig(`
+  default_retention {
+    mode  = "COMPLIANCE"
+    years = 1
+  }`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckBucketSubresource(server, "assets", "object-lock", regexp.MustCompile(`<Mode>COMPLIANCE</Mode>`)),
+					testAccCheckBucketSubresource(server, "assets", "object-lock", regexp.MustCompile(`<Years>1</Years>`)),
+				),
+			},
+			{
+				Config: providerConfig + testAccBucketObjectLockConfigurationResourceConfig(""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_bucket_object_lock_configuration.test", "default_retention.#", "0"),
+					testAccCheckBucketSubresource(server, "assets", "object-lock", regexp.MustCompile(`^[^R]*$`)),
+				),
+			},
+			// Versioning of buckets with object lock cannot be suspended
+			{
+				Config: providerConfig + testAccBucketObjectLockConfigurationResourceConfig("") + `
+resource "radosgw_bucket_versioning" "other" {
+  bucket = "assets"
+  status = "Suspended"
+
+  depends_on = [radosgw_bucket_object_lock_configuration.test]
+}
+`,
+				ExpectError: regexp.MustCompile(`InvalidBucketState`),
+			},
+			// Removing the bucket removes object lock
+			{
+				PreConfig: func() {
+					if err := server.API().RemoveBucket(context.Background(), admin.Bucket{Bucket: "assets"}); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:             providerConfig + testAccBucketObjectLockConfigurationResourceConfig(""),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccBucketObjectLockConfigurationResource_destroy(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccBucket(t, server) },
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + testAccBucketObjectLockConfigurationResourceConfig(`
+  default_retention {
+    mode = "GOVERNANCE"
+    days = 30
+  }`),
+			},
+		},
+		// destroying only removes object lock from the state
+		CheckDestroy: resource.ComposeAggregateTestCheckFunc(
+			testAccCheckBucketSubresource(server, "assets", "object-lock", regexp.MustCompile(`<ObjectLockEnabled>Enabled</ObjectLockEnabled>`)),
+			testAccCheckBucketSubresource(server, "assets", "object-lock", regexp.MustCompile(`<Days>30</Days>`)),
+		),
+	})
+}
+
+func TestAccBucketObjectLockConfigurationResource_unversioned(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccBucket(t, server) },
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "radosgw_bucket_object_lock_configuration" "test" {
+  bucket = "assets"
+}
+`,
+				ExpectError: regexp.MustCompile(`Object lock needs\s+versioning enabled`),
+			},
+		},
+	})
+}
+
+func testAccBucketObjectLockConfigurationResourceConfig(retention string) string {
+	return fmt.Sprintf(`
+resource "radosgw_bucket_versioning" "test" {
+  bucket = "assets"
+  status = "Enabled"
+}
+
+resource "radosgw_bucket_object_lock_configuration" "test" {
+  bucket = radosgw_bucket_versioning.test.bucket
+  %s
+}
+`, retention)
+}
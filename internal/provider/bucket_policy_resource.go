@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &bucketPolicyResource{}
+	_ resource.ResourceWithConfigure   = &bucketPolicyResource{}
+	_ resource.ResourceWithImportState = &bucketPolicyResource{}
+)
+
+// NewBucketPolicyResource is a helper function to simplify the provider implementation.
+func NewBucketPolicyResource() resource.Resource {
+	return &bucketPolicyResource{}
+}
+
+// bucketPolicyResource is the resource implementation.
+type bucketPolicyResource struct {
+	s3 s3iface.S3API
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *bucketPolicyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
+		return
+	}
+
+	r.s3 = s3.New(data.awsSession)
+}
+
+// Metadata returns the resource type name.
+func (r *bucketPolicyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_policy"
+}
+
+// Schema defines the schema for the resource.
+func (r *bucketPolicyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the policy of a bucket through the S3 API of radosgw.  The provider credentials must belong to the owner of the bucket or to a system user.",
+		Attributes: map[string]schema.Attribute{
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Name of the bucket.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"policy": schema.StringAttribute{
+				MarkdownDescription: "JSON policy document.  Only the actions and condition keys supported by radosgw are accepted, and principals must be given as `arn:aws:iam::<tenant>:user/<user>`, with an empty tenant for users without one.  Differences in formatting and ordering are not reported as changes.",
+				Required:            true,
+				CustomType:          policyDocumentType{},
+				Validators: []validator.String{
+					policyValidator{rules: bucketPolicyRules},
+				},
+			},
+		},
+	}
+}
+
+type bucketPolicyResourceModel struct {
+	Bucket types.String   `tfsdk:"bucket"`
+	Policy policyDocument `tfsdk:"policy"`
+}
+
+// errorTarget describes the bucket policy for explaining S3 API errors.
+func (m bucketPolicyResourceModel) errorTarget() rgwErrorTarget {
+	return rgwErrorTarget{
+		resourceType: "radosgw_bucket_policy",
+		importID:     m.Bucket.ValueString(),
+		bucketPath:   path.Root("bucket"),
+		policyPath:   path.Root("policy"),
+	}
+}
+
+// Read implements resource.Resource.
+func (r *bucketPolicyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state bucketPolicyResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	out, err := r.s3.GetBucketPolicyWithContext(ctx, &s3.GetBucketPolicyInput{
+		Bucket: aws.String(state.Bucket.ValueString()),
+	})
+	if code := awsErrorCode(err); code == "NoSuchBucketPolicy" || code == "NoSuchBucket" {
+		tflog.Warn(ctx, "bucket policy removed outside of Terraform", map[string]any{"bucket": state.Bucket.ValueString(), "error_code": code})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error fetching bucket policy",
+			fmt.Sprintf("Could not fetch policy of bucket %q", state.Bucket.ValueString()), err)
+		return
+	}
+
+	state.Policy = newPolicyDocument(aws.StringValue(out.Policy))
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// ImportState implements resource.ResourceWithImportState.  Bucket policies
+// are imported by the name of their bucket.
+func (r *bucketPolicyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("bucket"), req, resp)
+}
+
+// Create implements resource.Resource.
+func (r *bucketPolicyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan bucketPolicyResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.put(ctx, plan); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error setting bucket policy",
+			fmt.Sprintf("Could not set policy of bucket %q", plan.Bucket.ValueString()), err)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *bucketPolicyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan bucketPolicyResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.put(ctx, plan); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error updating bucket policy",
+			fmt.Sprintf("Could not update policy of bucket %q", plan.Bucket.ValueString()), err)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// put sets the policy of the bucket to that of plan.
+func (r *bucketPolicyResource) put(ctx context.Context, plan bucketPolicyResourceModel) error {
+	_, err := r.s3.PutBucketPolicyWithContext(ctx, &s3.PutBucketPolicyInput{
+		Bucket: aws.String(plan.Bucket.ValueString()),
+		Policy: aws.String(plan.Policy.ValueString()),
+	})
+	return err
+}
+
+// Delete implements resource.Resource.
+func (r *bucketPolicyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state bucketPolicyResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.s3.DeleteBucketPolicyWithContext(ctx, &s3.DeleteBucketPolicyInput{
+		Bucket: aws.String(state.Bucket.ValueString()),
+	})
+	if code := awsErrorCode(err); code == "NoSuchBucketPolicy" || code == "NoSuchBucket" {
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error removing bucket policy",
+			fmt.Sprintf("Could not remove policy of bucket %q", state.Bucket.ValueString()), err)
+		return
+	}
+}
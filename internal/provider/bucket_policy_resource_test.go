@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/spreadshirt/terraform-provider-radosgw/internal/rgwtest"
+)
+
+// testAccBucket creates the user demo with the bucket assets on the fake
+// radosgw, as buckets are not managed by the provider.
+func testAccBucket(t *testing.T, server *rgwtest.Server) {
+	t.Helper()
+
+	if _, err := server.API().CreateUser(context.Background(), admin.User{ID: "demo", DisplayName: "Demo user"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.CreateBucket("assets", "demo"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAccBucketPolicyResource(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccBucket(t, server) },
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + testAccBucketPolicyResourceConfig("s3:GetObject"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_bucket_policy.test", "bucket", "assets"),
+					testAccCheckBucketSubresource(server, "assets", "policy", regexp.MustCompile(`"s3:GetObject"`)),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "radosgw_bucket_policy.test",
+				ImportState:                          true,
+				ImportStateId:                        "assets",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "bucket",
+			},
+			// A policy returned in a different form is no drift
+			{
+				PreConfig: func() {
+					server.SetBucketSubresource("assets", "policy", `{
+  "Statement": {
+    "Resource": ["arn:aws:s3:::assets/*"],
+    "Action": "s3:GetObject",
+    "Principal": {"AWS": "arn:aws:iam:::user/reader"},
+    "Effect": "Allow"
+  },
+  "Version": "2012-10-17"
+}`)
+				},
+				Config:   providerConfig + testAccBucketPolicyResourceConfig("s3:GetObject"),
+				PlanOnly: true,
+			},
+			// Update and Read testing
+			{
+				Config: providerConfig + testAccBucketPolicyResourceConfig("s3:PutObject"),
+				Check:  testAccCheckBucketSubresource(server, "assets", "policy", regexp.MustCompile(`"s3:PutObject"`)),
+			},
+			// Removed outside of Terraform
+			{
+				PreConfig: func() {
+					server.RemoveBucketSubresource("assets", "policy")
+				},
+				Config:             providerConfig + testAccBucketPolicyResourceConfig("s3:PutObject"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccBucketPolicyResource_validation(t *testing.T) {
+	_, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      providerConfig + testAccBucketPolicyResourceConfig("s3:PutBucketInventoryConfiguration"),
+				ExpectError: regexp.MustCompile(`Action "s3:PutBucketInventoryConfiguration" is not supported by\s+radosgw`),
+			},
+		},
+	})
+}
+
+func TestAccBucketPolicyResource_missingBucket(t *testing.T) {
+	_, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      providerConfig + testAccBucketPolicyResourceConfig("s3:GetObject"),
+				ExpectError: regexp.MustCompile(`The bucket does not exist`),
+			},
+		},
+	})
+}
+
+func testAccBucketPolicyResourceConfig(action string) string {
+	return fmt.Sprintf(`
+resource "radosgw_bucket_policy" "test" {
+  bucket = "assets"
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [{
+      Effect    = "Allow"
+      Principal = { AWS = ["arn:aws:iam:::user/reader"] }
+      Action    = [%q]
+      Resource  = ["arn:aws:s3:::assets/*"]
+    }]
+  })
+}
+`, action)
+}
+
+// testAccCheckBucketSubresource checks that the document of a subresource
+// of a bucket on the fake radosgw matches pattern.
+func testAccCheckBucketSubresource(server *rgwtest.Server, bucket, subresource string, pattern *regexp.Regexp) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		document, ok := server.BucketSubresource(bucket, subresource)
+		if !ok {
+			return fmt.Errorf("bucket %q has no %s", bucket, subresource)
+		}
+		if !pattern.MatchString(document) {
+			return fmt.Errorf("%s of bucket %q does not match %s: %s", subresource, bucket, pattern, document)
+		}
+		return nil
+	}
+}
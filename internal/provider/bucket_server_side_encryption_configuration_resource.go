@@ -0,0 +1,344 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &bucketServerSideEncryptionConfigurationResource{}
+	_ resource.ResourceWithConfigure      = &bucketServerSideEncryptionConfigurationResource{}
+	_ resource.ResourceWithImportState    = &bucketServerSideEncryptionConfigurationResource{}
+	_ resource.ResourceWithValidateConfig = &bucketServerSideEncryptionConfigurationResource{}
+	_ resource.ResourceWithModifyPlan     = &bucketServerSideEncryptionConfigurationResource{}
+)
+
+const (
+	sseAlgorithmS3  = "AES256"
+	sseAlgorithmKMS = "aws:kms"
+)
+
+// kmsKeyIDPattern matches the key IDs radosgw passes to its KMS, such as
+// the name of a Vault transit key or the UUID of a Barbican secret.
+var kmsKeyIDPattern = regexp.MustCompile(`^[^\s/]\S*$`)
+
+// NewBucketServerSideEncryptionConfigurationResource is a helper function to simplify the provider implementation.
+func NewBucketServerSideEncryptionConfigurationResource() resource.Resource {
+	return &bucketServerSideEncryptionConfigurationResource{}
+}
+
+// bucketServerSideEncryptionConfigurationResource is the resource implementation.
+type bucketServerSideEncryptionConfigurationResource struct {
+	s3 s3iface.S3API
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *bucketServerSideEncryptionConfigurationResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
+		return
+	}
+
+	r.s3 = s3.New(data.awsSession)
+}
+
+// Metadata returns the resource type name.
+func (r *bucketServerSideEncryptionConfigurationResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_server_side_encryption_configuration"
+}
+
+// Schema defines the schema for the resource.
+func (r *bucketServerSideEncryptionConfigurationResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the default encryption of new objects in a bucket through the S3 API of radosgw.  The provider credentials must belong to the owner of the bucket or to a system user.\n\n" +
+			"radosgw encrypts objects with keys from its KMS, such as Vault, KMIP, Barbican or the testing keys of `rgw_crypt_s3_kms_encryption_keys`.  SSE-S3 needs `rgw_crypt_sse_s3_backend` to be configured, SSE-KMS `rgw_crypt_s3_kms_backend`.  " +
+			"If the configuration is removed outside of Terraform, it is planned to be set again.",
+		Attributes: map[string]schema.Attribute{
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Name of the bucket.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"rule": schema.ListNestedBlock{
+				MarkdownDescription: "Encryption rule.  radosgw supports a single rule.",
+				Validators: []validator.List{
+					listvalidator.IsRequired(),
+					listvalidator.SizeBetween(1, 1),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Blocks: map[string]schema.Block{
+						"apply_server_side_encryption_by_default": schema.ListNestedBlock{
+							MarkdownDescription: "Encryption applied to new objects uploaded without encryption headers.",
+							Validators: []validator.List{
+								listvalidator.IsRequired(),
+								listvalidator.SizeBetween(1, 1),
+							},
+							NestedObject: schema.NestedBlockObject{
+								Attributes: map[string]schema.Attribute{
+									"sse_algorithm": schema.StringAttribute{
+										MarkdownDescription: "Encryption of new objects, `AES256` for SSE-S3 or `aws:kms` for SSE-KMS.",
+										Required:            true,
+										Validators: []validator.String{
+											stringvalidator.OneOf(sseAlgorithmS3, sseAlgorithmKMS),
+										},
+									},
+									"kms_master_key_id": schema.StringAttribute{
+										MarkdownDescription: "ID of the key in the KMS of radosgw, required for `aws:kms`.  This is the name of a Vault key, the UUID of a Barbican secret or the name of a KMIP key, not an AWS ARN.",
+										Optional:            true,
+										Validators: []validator.String{
+											stringvalidator.LengthBetween(1, 2048),
+											stringvalidator.RegexMatches(kmsKeyIDPattern, `must not contain whitespace or start with "/"`),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+type bucketServerSideEncryptionConfigurationResourceModel struct {
+	Bucket types.String   `tfsdk:"bucket"`
+	Rules  []sseRuleModel `tfsdk:"rule"`
+}
+
+type sseRuleModel struct {
+	Default []sseDefaultModel `tfsdk:"apply_server_side_encryption_by_default"`
+}
+
+type sseDefaultModel struct {
+	SSEAlgorithm   types.String `tfsdk:"sse_algorithm"`
+	KMSMasterKeyID types.String `tfsdk:"kms_master_key_id"`
+}
+
+// errorTarget describes the encryption configuration for explaining S3 API
+// errors.
+func (m bucketServerSideEncryptionConfigurationResourceModel) errorTarget() rgwErrorTarget {
+	return rgwErrorTarget{
+		resourceType: "radosgw_bucket_server_side_encryption_configuration",
+		importID:     m.Bucket.ValueString(),
+		bucketPath:   path.Root("bucket"),
+	}
+}
+
+// ValidateConfig implements resource.ResourceWithValidateConfig.
+func (r *bucketServerSideEncryptionConfigurationResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	// rules and their defaults from dynamic blocks can be unknown, which the
+	// model cannot hold
+	var rules types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("rule"), &rules)...)
+	if resp.Diagnostics.HasError() || rules.IsUnknown() {
+		return
+	}
+
+	for i := range rules.Elements() {
+		var defaults types.List
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("rule").AtListIndex(i).AtName("apply_server_side_encryption_by_default"), &defaults)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if defaults.IsUnknown() {
+			continue
+		}
+		var defs []sseDefaultModel
+		resp.Diagnostics.Append(defaults.ElementsAs(ctx, &defs, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		for j, def := range defs {
+			defPath := path.Root("rule").AtListIndex(i).AtName("apply_server_side_encryption_by_default").AtListIndex(j)
+			if def.SSEAlgorithm.IsUnknown() || def.KMSMasterKeyID.IsUnknown() {
+				continue
+			}
+
+			keyPath := defPath.AtName("kms_master_key_id")
+			switch algorithm := def.SSEAlgorithm.ValueString(); {
+			case algorithm == sseAlgorithmKMS && def.KMSMasterKeyID.IsNull():
+				resp.Diagnostics.AddAttributeError(keyPath, "Missing KMS key ID",
+					"SSE-KMS needs the ID of a key in the KMS of radosgw, set kms_master_key_id.")
+			case algorithm == sseAlgorithmS3 && !def.KMSMasterKeyID.IsNull():
+				resp.Diagnostics.AddAttributeError(keyPath, "Unexpected KMS key ID",
+					"SSE-S3 uses keys managed by radosgw, kms_master_key_id can only be set with sse_algorithm \"aws:kms\".")
+			}
+
+			keyID := def.KMSMasterKeyID.ValueString()
+			if containsString(strings.Split(keyID, "/"), "..") {
+				resp.Diagnostics.AddAttributeError(keyPath, "Invalid KMS key ID",
+					fmt.Sprintf("Key ID %q must not contain \"..\" path segments, which radosgw rejects.", keyID))
+			}
+			if strings.HasPrefix(keyID, "arn:") {
+				resp.Diagnostics.AddAttributeWarning(keyPath, "KMS key ID looks like an ARN",
+					fmt.Sprintf("radosgw passes key ID %q to its KMS as is and does not resolve AWS ARNs.  Use the name or ID of the key in the KMS instead.", keyID))
+			}
+		}
+	}
+}
+
+// ModifyPlan implements resource.ResourceWithModifyPlan.  It warns when the
+// default encryption of a bucket is about to be removed.
+func (r *bucketServerSideEncryptionConfigurationResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if !req.Plan.Raw.IsNull() || req.State.Raw.IsNull() {
+		return
+	}
+
+	resp.Diagnostics.AddWarning("Bucket encryption will be removed",
+		"Destroying a radosgw_bucket_server_side_encryption_configuration stores new objects of the bucket unencrypted, unless clients request encryption.  Existing objects stay encrypted.")
+}
+
+// Read implements resource.Resource.
+func (r *bucketServerSideEncryptionConfigurationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state bucketServerSideEncryptionConfigurationResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	out, err := r.s3.GetBucketEncryptionWithContext(ctx, &s3.GetBucketEncryptionInput{
+		Bucket: aws.String(state.Bucket.ValueString()),
+	})
+	if code := awsErrorCode(err); code == "ServerSideEncryptionConfigurationNotFoundError" || code == "NoSuchBucket" {
+		tflog.Warn(ctx, "bucket encryption configuration removed outside of Terraform", map[string]any{"bucket": state.Bucket.ValueString(), "error_code": code})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error fetching bucket encryption configuration",
+			fmt.Sprintf("Could not fetch encryption configuration of bucket %q", state.Bucket.ValueString()), err)
+		return
+	}
+
+	state.Rules = nil
+	if out.ServerSideEncryptionConfiguration != nil {
+		for _, rule := range out.ServerSideEncryptionConfiguration.Rules {
+			def := rule.ApplyServerSideEncryptionByDefault
+			if def == nil {
+				continue
+			}
+			state.Rules = append(state.Rules, sseRuleModel{Default: []sseDefaultModel{{
+				SSEAlgorithm:   types.StringValue(aws.StringValue(def.SSEAlgorithm)),
+				KMSMasterKeyID: optionalString(aws.StringValue(def.KMSMasterKeyID)),
+			}}})
+		}
+	}
+	if len(state.Rules) == 0 {
+		tflog.Warn(ctx, "bucket encryption configuration removed outside of Terraform", map[string]any{"bucket": state.Bucket.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// ImportState implements resource.ResourceWithImportState.  Encryption
+// configurations are imported by the name of their bucket.
+func (r *bucketServerSideEncryptionConfigurationResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("bucket"), req, resp)
+}
+
+// Create implements resource.Resource.
+func (r *bucketServerSideEncryptionConfigurationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan bucketServerSideEncryptionConfigurationResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.put(ctx, plan); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error setting bucket encryption configuration",
+			fmt.Sprintf("Could not set encryption configuration of bucket %q", plan.Bucket.ValueString()), err)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *bucketServerSideEncryptionConfigurationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan bucketServerSideEncryptionConfigurationResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.put(ctx, plan); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error updating bucket encryption configuration",
+			fmt.Sprintf("Could not update encryption configuration of bucket %q", plan.Bucket.ValueString()), err)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// put sets the encryption configuration of the bucket to that of plan.
+func (r *bucketServerSideEncryptionConfigurationResource) put(ctx context.Context, plan bucketServerSideEncryptionConfigurationResourceModel) error {
+	rules := make([]*s3.ServerSideEncryptionRule, 0, len(plan.Rules))
+	for _, rule := range plan.Rules {
+		for _, def := range rule.Default {
+			rules = append(rules, &s3.ServerSideEncryptionRule{
+				ApplyServerSideEncryptionByDefault: &s3.ServerSideEncryptionByDefault{
+					SSEAlgorithm:   aws.String(def.SSEAlgorithm.ValueString()),
+					KMSMasterKeyID: def.KMSMasterKeyID.ValueStringPointer(),
+				},
+			})
+		}
+	}
+
+	_, err := r.s3.PutBucketEncryptionWithContext(ctx, &s3.PutBucketEncryptionInput{
+		Bucket:                            aws.String(plan.Bucket.ValueString()),
+		ServerSideEncryptionConfiguration: &s3.ServerSideEncryptionConfiguration{Rules: rules},
+	})
+	return err
+}
+
+// Delete implements resource.Resource.
+func (r *bucketServerSideEncryptionConfigurationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state bucketServerSideEncryptionConfigurationResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.s3.DeleteBucketEncryptionWithContext(ctx, &s3.DeleteBucketEncryptionInput{
+		Bucket: aws.String(state.Bucket.ValueString()),
+	})
+	if code := awsErrorCode(err); code == "ServerSideEncryptionConfigurationNotFoundError" || code == "NoSuchBucket" {
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error removing bucket encryption configuration",
+			fmt.Sprintf("Could not remove encryption configuration of bucket %q", state.Bucket.ValueString()), err)
+		return
+	}
+}
@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccBucketServerSideEncryptionConfigurationResource(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccBucket(t, server) },
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + testAccBucketServerSideEncryptionConfigurationResourceConfig("AES256", ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_bucket_server_side_encryption_configuration.test", "rule.0.apply_server_side_encryption_by_default.0.sse_algorithm", "AES256"),
+					resource.TestCheckNoResourceAttr("radosgw_bucket_server_side_encryption_configuration.test", "rule.0.apply_server_side_encryption_by_default.0.kms_master_key_id"),
+					testAccCheckBucketSubresource(server, "assets", "encryption", regexp.MustCompile(`<SSEAlgorithm>AES256</SSEAlgorithm>`)),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "radosgw_bucket_server_side_encryption_configuration.test",
+				ImportState:                          true,
+				ImportStateId:                        "assets",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "bucket",
+			},
+			// Update and Read testing
+			{
+				Config: providerConfig + testAccBucketServerSideEncryptionConfigurationResourceConfig("aws:kms", `kms_master_key_id = "assets-key"`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_bucket_server_side_encryption_configuration.test", "rule.0.apply_server_side_encryption_by_default.0.kms_master_key_id", "assets-key"),
+					testAccCheckBucketSubresource(server, "assets", "encryption", regexp.MustCompile(`<KMSMasterKeyID>assets-key</KMSMasterKeyID>`)),
+				),
+			},
+			// Removed outside of Terraform
+			{
+				PreConfig: func() {
+					server.RemoveBucketSubresource("assets", "encryption")
+				},
+				Config:             providerConfig + testAccBucketServerSideEncryptionConfigurationResourceConfig("aws:kms", `kms_master_key_id = "assets-key"`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: providerConfig + testAccBucketServerSideEncryptionConfigurationResourceConfig("aws:kms", `kms_master_key_id = "assets-key"`),
+				Check:  testAccCheckBucketSubresource(server, "assets", "encryption", regexp.MustCompile(`<SSEAlgorithm>aws:kms</SSEAlgorithm>`)),
+			},
+		},
+	})
+}
+
+func TestAccBucketServerSideEncryptionConfigurationResource_validation(t *testing.T) {
+	_, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      providerConfig + testAccBucketServerSideEncryptionConfigurationResourceConfig("aws:kms", ""),
+				ExpectError: regexp.MustCompile(`Missing KMS key ID`),
+			},
+			{
+				Config:      providerConfig + testAccBucketServerSideEncryptionConfigurationResourceConfig("AES256", `kms_master_key_id = "assets-key"`),
+				ExpectError: regexp.MustCompile(`Unexpected KMS key ID`),
+			},
+			{
+				Config:      providerConfig + testAccBucketServerSideEncryptionConfigurationResourceConfig("aws:kms", `kms_master_key_id = "keys/../other"`),
+				ExpectError: regexp.MustCompile(`Invalid KMS key ID`),
+			},
+			{
+				Config:      providerConfig + testAccBucketServerSideEncryptionConfigurationResourceConfig("aws:kms", `kms_master_key_id = "assets key"`),
+				ExpectError: regexp.MustCompile(`must not\s+contain whitespace`),
+			},
+		},
+	})
+}
+
+func TestAccBucketServerSideEncryptionConfigurationResource_dynamic(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	// the blocks are unknown until terraform_data is applied, so each step
+	// uses a new one
+	config := func(data, rule string) string {
+		return providerConfig + `
+resource "terraform_data" "` + data + `" {
+  input = [{ algorithm = "aws:kms", key = "assets-key" }]
+}
+
+resource "radosgw_bucket_server_side_encryption_configuration" "test" {
+  bucket = "assets"
+` + rule + `
+}
+`
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccBucket(t, server) },
+		Steps: []resource.TestStep{
+			{
+				Config: config("rules", `
+  dynamic "rule" {
+    for_each = terraform_data.rules.output
+    content {
+      apply_server_side_encryption_by_default {
+        sse_algorithm     = rule.value.algorithm
+        kms_master_key_id = rule.value.key
+      }
+    }
+  }
+`),
+				Check: testAccCheckBucketSubresource(server, "assets", "encryption", regexp.MustCompile(`<KMSMasterKeyID>assets-key</KMSMasterKeyID>`)),
+			},
+			{
+				Config: config("defaults", `
+  rule {
+    dynamic "apply_server_side_encryption_by_default" {
+      for_each = terraform_data.defaults.output
+      content {
+        sse_algorithm     = apply_server_side_encryption_by_default.value.algorithm
+        kms_master_key_id = apply_server_side_encryption_by_default.value.key
+      }
+    }
+  }
+`),
+				Check: testAccCheckBucketSubresource(server, "assets", "encryption", regexp.MustCompile(`<KMSMasterKeyID>assets-key</KMSMasterKeyID>`)),
+			},
+		},
+	})
+}
+
+func testAccBucketServerSideEncryptionConfigurationResourceConfig(algorithm, extra string) string {
+	return fmt.Sprintf(`
+resource "radosgw_bucket_server_side_encryption_configuration" "test" {
+  bucket = "assets"
+
+  rule {
+    apply_server_side_encryption_by_default {
+      sse_algorithm = %q
+      %s
+    }
+  }
+}
+`, algorithm, extra)
+}
@@ -0,0 +1,229 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &bucketTaggingResource{}
+	_ resource.ResourceWithConfigure      = &bucketTaggingResource{}
+	_ resource.ResourceWithImportState    = &bucketTaggingResource{}
+	_ resource.ResourceWithValidateConfig = &bucketTaggingResource{}
+)
+
+// NewBucketTaggingResource is a helper function to simplify the provider implementation.
+func NewBucketTaggingResource() resource.Resource {
+	return &bucketTaggingResource{}
+}
+
+// bucketTaggingResource is the resource implementation.
+type bucketTaggingResource struct {
+	s3 s3iface.S3API
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *bucketTaggingResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
+		return
+	}
+
+	r.s3 = s3.New(data.awsSession)
+}
+
+// Metadata returns the resource type name.
+func (r *bucketTaggingResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_tagging"
+}
+
+// Schema defines the schema for the resource.
+func (r *bucketTaggingResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the tags of a bucket through the S3 API of radosgw, such as for chargeback.  The provider credentials must belong to the owner of the bucket or to a system user.  All tags of the bucket are managed by this resource.",
+		Attributes: map[string]schema.Attribute{
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Name of the bucket.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"tags": schema.MapAttribute{
+				MarkdownDescription: "Tags of the bucket, at most 50.  Keys have up to 128 characters and must not start with `aws:`, values up to 256 characters.",
+				ElementType:         types.StringType,
+				Required:            true,
+				Validators: []validator.Map{
+					mapvalidator.SizeBetween(1, 50),
+					mapvalidator.KeysAre(stringvalidator.LengthBetween(1, 128)),
+					mapvalidator.ValueStringsAre(stringvalidator.LengthAtMost(256)),
+				},
+			},
+		},
+	}
+}
+
+type bucketTaggingResourceModel struct {
+	Bucket types.String      `tfsdk:"bucket"`
+	Tags   map[string]string `tfsdk:"tags"`
+}
+
+// ValidateConfig implements resource.ResourceWithValidateConfig.  Keys with
+// the "aws:" prefix are reserved, which radosgw rejects with a bare InvalidTag.
+func (r *bucketTaggingResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var tags types.Map
+	diags := req.Config.GetAttribute(ctx, path.Root("tags"), &tags)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || tags.IsNull() || tags.IsUnknown() {
+		return
+	}
+
+	for key := range tags.Elements() {
+		if strings.HasPrefix(strings.ToLower(key), "aws:") {
+			resp.Diagnostics.AddAttributeError(path.Root("tags").AtMapKey(key), "Reserved tag key",
+				fmt.Sprintf("Tag key %q must not start with \"aws:\", which is reserved.", key))
+		}
+	}
+}
+
+// errorTarget describes the tags for explaining S3 API errors.
+func (m bucketTaggingResourceModel) errorTarget() rgwErrorTarget {
+	return rgwErrorTarget{
+		resourceType: "radosgw_bucket_tagging",
+		importID:     m.Bucket.ValueString(),
+		bucketPath:   path.Root("bucket"),
+	}
+}
+
+// Read implements resource.Resource.
+func (r *bucketTaggingResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state bucketTaggingResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	out, err := r.s3.GetBucketTaggingWithContext(ctx, &s3.GetBucketTaggingInput{
+		Bucket: aws.String(state.Bucket.ValueString()),
+	})
+	if code := awsErrorCode(err); code == "NoSuchTagSet" || code == "NoSuchBucket" {
+		tflog.Warn(ctx, "bucket tags removed outside of Terraform", map[string]any{"bucket": state.Bucket.ValueString(), "error_code": code})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error fetching bucket tags",
+			fmt.Sprintf("Could not fetch tags of bucket %q", state.Bucket.ValueString()), err)
+		return
+	}
+	if len(out.TagSet) == 0 {
+		tflog.Warn(ctx, "bucket tags removed outside of Terraform", map[string]any{"bucket": state.Bucket.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.Tags = make(map[string]string, len(out.TagSet))
+	for _, tag := range out.TagSet {
+		state.Tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+	}
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// ImportState implements resource.ResourceWithImportState.  Bucket tags are
+// imported by the name of their bucket.
+func (r *bucketTaggingResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("bucket"), req, resp)
+}
+
+// Create implements resource.Resource.
+func (r *bucketTaggingResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan bucketTaggingResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.put(ctx, plan); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error setting bucket tags",
+			fmt.Sprintf("Could not set tags of bucket %q", plan.Bucket.ValueString()), err)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *bucketTaggingResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan bucketTaggingResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.put(ctx, plan); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error updating bucket tags",
+			fmt.Sprintf("Could not update tags of bucket %q", plan.Bucket.ValueString()), err)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// put sets the tags of the bucket to those of plan.
+func (r *bucketTaggingResource) put(ctx context.Context, plan bucketTaggingResourceModel) error {
+	tags := make([]*s3.Tag, 0, len(plan.Tags))
+	for _, key := range sortedMapKeys(plan.Tags) {
+		tags = append(tags, &s3.Tag{Key: aws.String(key), Value: aws.String(plan.Tags[key])})
+	}
+
+	_, err := r.s3.PutBucketTaggingWithContext(ctx, &s3.PutBucketTaggingInput{
+		Bucket:  aws.String(plan.Bucket.ValueString()),
+		Tagging: &s3.Tagging{TagSet: tags},
+	})
+	return err
+}
+
+// Delete implements resource.Resource.
+func (r *bucketTaggingResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state bucketTaggingResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.s3.DeleteBucketTaggingWithContext(ctx, &s3.DeleteBucketTaggingInput{
+		Bucket: aws.String(state.Bucket.ValueString()),
+	})
+	if code := awsErrorCode(err); code == "NoSuchTagSet" || code == "NoSuchBucket" {
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error removing bucket tags",
+			fmt.Sprintf("Could not remove tags of bucket %q", state.Bucket.ValueString()), err)
+		return
+	}
+}
@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccBucketTaggingResource(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccBucket(t, server) },
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + testAccBucketTaggingResourceConfig("web"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_bucket_tagging.test", "tags.%", "2"),
+					resource.TestCheckResourceAttr("radosgw_bucket_tagging.test", "tags.team", "web"),
+					testAccCheckBucketSubresource(server, "assets", "tagging", regexp.MustCompile(`<Key>cost-center</Key>`)),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "radosgw_bucket_tagging.test",
+				ImportState:                          true,
+				ImportStateId:                        "assets",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "bucket",
+			},
+			// Update and Read testing
+			{
+				Config: providerConfig + testAccBucketTaggingResourceConfig("platform"),
+				Check:  testAccCheckBucketSubresource(server, "assets", "tagging", regexp.MustCompile(`<Value>platform</Value>`)),
+			},
+			// Removed outside of Terraform
+			{
+				PreConfig: func() {
+					server.RemoveBucketSubresource("assets", "tagging")
+				},
+				Config:             providerConfig + testAccBucketTaggingResourceConfig("platform"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccBucketTaggingResource_validation(t *testing.T) {
+	_, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "radosgw_bucket_tagging" "test" {
+  bucket = "assets"
+  tags = {
+    "aws:team" = "web"
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`Reserved tag key`),
+			},
+		},
+	})
+}
+
+func testAccBucketTaggingResourceConfig(team string) string {
+	return fmt.Sprintf(`
+resource "radosgw_bucket_tagging" "test" {
+  bucket = "assets"
+  tags = {
+    team          = %q
+    "cost-center" = "1234"
+  }
+}
+`, team)
+}
@@ -0,0 +1,291 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &bucketVersioningResource{}
+	_ resource.ResourceWithConfigure   = &bucketVersioningResource{}
+	_ resource.ResourceWithImportState = &bucketVersioningResource{}
+	_ resource.ResourceWithModifyPlan  = &bucketVersioningResource{}
+)
+
+// mfaPattern matches the serial number of an MFA device and a token, as sent
+// in the x-amz-mfa header.
+var mfaPattern = regexp.MustCompile(`^\S+ [0-9]{6}$`)
+
+// NewBucketVersioningResource is a helper function to simplify the provider implementation.
+func NewBucketVersioningResource() resource.Resource {
+	return &bucketVersioningResource{}
+}
+
+// bucketVersioningResource is the resource implementation.
+type bucketVersioningResource struct {
+	s3 s3iface.S3API
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *bucketVersioningResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
+		return
+	}
+
+	r.s3 = s3.New(data.awsSession)
+}
+
+// Metadata returns the resource type name.
+func (r *bucketVersioningResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_versioning"
+}
+
+// Schema defines the schema for the resource.
+func (r *bucketVersioningResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the versioning state of a bucket through the S3 API of radosgw.  The provider credentials must belong to the owner of the bucket or to a system user.  " +
+			"Versioning cannot be turned off again once enabled, so destroying this resource suspends versioning.",
+		Attributes: map[string]schema.Attribute{
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Name of the bucket.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"status": schema.StringAttribute{
+				MarkdownDescription: "Either `Enabled` or `Suspended`.  Buckets with object lock cannot suspend versioning.",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(s3.BucketVersioningStatus_Values()...),
+				},
+			},
+			"mfa_delete": schema.BoolAttribute{
+				MarkdownDescription: "Whether deleting object versions and changing the versioning state needs a multi-factor authentication token.  Changing it needs `mfa`.  Defaults to `false`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"mfa": schema.StringAttribute{
+				MarkdownDescription: "Serial number of the MFA device of the provider user and current token, separated by a space.  Only needed to change `mfa_delete` or the status of buckets with `mfa_delete` enabled.  It is not kept in the state, so destroying the resource leaves versioning of buckets with `mfa_delete` enabled as is.  Needs Terraform 1.11 or later.",
+				Optional:            true,
+				Sensitive:           true,
+				WriteOnly:           true,
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(mfaPattern, "must be the serial number and token separated by a space"),
+				},
+			},
+		},
+	}
+}
+
+type bucketVersioningResourceModel struct {
+	Bucket    types.String `tfsdk:"bucket"`
+	Status    types.String `tfsdk:"status"`
+	MFADelete types.Bool   `tfsdk:"mfa_delete"`
+
+	// MFA is always null, as mfa is only in the configuration.
+	MFA types.String `tfsdk:"mfa"`
+}
+
+// errorTarget describes the versioning state for explaining S3 API errors.
+func (m bucketVersioningResourceModel) errorTarget() rgwErrorTarget {
+	return rgwErrorTarget{
+		resourceType: "radosgw_bucket_versioning",
+		importID:     m.Bucket.ValueString(),
+		bucketPath:   path.Root("bucket"),
+	}
+}
+
+// mfaDeleteStatus returns the MFA delete state in the form of the S3 API.
+func mfaDeleteStatus(enabled bool) string {
+	if enabled {
+		return s3.MFADeleteEnabled
+	}
+	return s3.MFADeleteDisabled
+}
+
+// ModifyPlan implements resource.ResourceWithModifyPlan.  It reports a
+// missing token when planning changes that radosgw only accepts with one.
+func (r *bucketVersioningResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan bucketVersioningResourceModel
+	var mfa types.String
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("mfa"), &mfa)...)
+	if resp.Diagnostics.HasError() || !mfa.IsNull() || plan.MFADelete.IsUnknown() {
+		return
+	}
+
+	var priorMFADelete bool
+	if !req.State.Raw.IsNull() {
+		var state bucketVersioningResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		priorMFADelete = state.MFADelete.ValueBool()
+	}
+
+	if plan.MFADelete.ValueBool() != priorMFADelete {
+		resp.Diagnostics.AddAttributeError(path.Root("mfa"), "Missing MFA token",
+			"radosgw only changes mfa_delete of a bucket with a token of the MFA device of the provider user.  Set mfa to the serial number of the device and the current token, separated by a space.")
+	}
+}
+
+// Read implements resource.Resource.
+func (r *bucketVersioningResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state bucketVersioningResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	out, err := r.s3.GetBucketVersioningWithContext(ctx, &s3.GetBucketVersioningInput{
+		Bucket: aws.String(state.Bucket.ValueString()),
+	})
+	if awsErrorCode(err) == "NoSuchBucket" {
+		tflog.Warn(ctx, "bucket of versioning removed outside of Terraform", map[string]any{"bucket": state.Bucket.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error fetching bucket versioning",
+			fmt.Sprintf("Could not fetch versioning of bucket %q", state.Bucket.ValueString()), err)
+		return
+	}
+
+	// buckets that were never versioned have no status, such as a bucket
+	// created again under the same name
+	if aws.StringValue(out.Status) == "" {
+		tflog.Warn(ctx, "bucket versioning removed outside of Terraform", map[string]any{"bucket": state.Bucket.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.Status = types.StringValue(aws.StringValue(out.Status))
+	state.MFADelete = types.BoolValue(aws.StringValue(out.MFADelete) == s3.MFADeleteStatusEnabled)
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// ImportState implements resource.ResourceWithImportState.  The versioning
+// state is imported by the name of its bucket.
+func (r *bucketVersioningResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("bucket"), req, resp)
+}
+
+// Create implements resource.Resource.
+func (r *bucketVersioningResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan bucketVersioningResourceModel
+	var mfa types.String
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("mfa"), &mfa)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.put(ctx, plan, mfa); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error setting bucket versioning",
+			fmt.Sprintf("Could not set versioning of bucket %q", plan.Bucket.ValueString()), err)
+		return
+	}
+
+	diags := resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *bucketVersioningResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan bucketVersioningResourceModel
+	var mfa types.String
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("mfa"), &mfa)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.put(ctx, plan, mfa); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error updating bucket versioning",
+			fmt.Sprintf("Could not update versioning of bucket %q", plan.Bucket.ValueString()), err)
+		return
+	}
+
+	diags := resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// put sets the versioning state of the bucket to that of plan, with the MFA
+// token mfa if not null.
+func (r *bucketVersioningResource) put(ctx context.Context, plan bucketVersioningResourceModel, mfa types.String) error {
+	_, err := r.s3.PutBucketVersioningWithContext(ctx, &s3.PutBucketVersioningInput{
+		Bucket: aws.String(plan.Bucket.ValueString()),
+		MFA:    mfa.ValueStringPointer(),
+		VersioningConfiguration: &s3.VersioningConfiguration{
+			Status:    aws.String(plan.Status.ValueString()),
+			MFADelete: aws.String(mfaDeleteStatus(plan.MFADelete.ValueBool())),
+		},
+	})
+	return err
+}
+
+// Delete implements resource.Resource.  Versioning cannot be turned off, so
+// it is suspended instead, which keeps the existing object versions.
+func (r *bucketVersioningResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state bucketVersioningResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.Status.ValueString() != s3.BucketVersioningStatusEnabled {
+		return
+	}
+	if state.MFADelete.ValueBool() {
+		resp.Diagnostics.AddWarning("Bucket versioning left enabled",
+			fmt.Sprintf("Versioning of bucket %q stays enabled, as suspending it needs a token of the MFA device with mfa_delete enabled, which is not kept in Terraform state.  It was removed from Terraform state.", state.Bucket.ValueString()))
+		return
+	}
+
+	state.Status = types.StringValue(s3.BucketVersioningStatusSuspended)
+	err := r.put(ctx, state, types.StringNull())
+	switch awsErrorCode(err) {
+	case "NoSuchBucket":
+		return
+	case "InvalidBucketState":
+		resp.Diagnostics.AddWarning("Bucket versioning left enabled",
+			fmt.Sprintf("Versioning of bucket %q stays enabled, as radosgw does not suspend versioning of buckets with object lock.  It was removed from Terraform state.", state.Bucket.ValueString()))
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error suspending bucket versioning",
+			fmt.Sprintf("Could not suspend versioning of bucket %q", state.Bucket.ValueString()), err)
+		return
+	}
+
+	resp.Diagnostics.AddWarning("Bucket versioning suspended",
+		fmt.Sprintf("radosgw cannot turn off versioning of a bucket once enabled, so versioning of bucket %q was suspended instead.  Existing object versions are kept.", state.Bucket.ValueString()))
+}
@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccBucketVersioningResource(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccBucket(t, server) },
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + testAccBucketVersioningResourceConfig("Enabled", ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_bucket_versioning.test", "status", "Enabled"),
+					resource.TestCheckResourceAttr("radosgw_bucket_versioning.test", "mfa_delete", "false"),
+					testAccCheckBucketSubresource(server, "assets", "versioning", regexp.MustCompile(`<Status>Enabled</Status>`)),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "radosgw_bucket_versioning.test",
+				ImportState:                          true,
+				ImportStateId:                        "assets",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "bucket",
+			},
+			// Update and Read testing
+			{
+				Config: providerConfig + testAccBucketVersioningResourceConfig("Suspended", ""),
+				Check:  testAccCheckBucketSubresource(server, "assets", "versioning", regexp.MustCompile(`<Status>Suspended</Status>`)),
+			},
+			// Removed outside of Terraform
+			{
+				PreConfig: func() {
+					server.RemoveBucketSubresource("assets", "versioning")
+				},
+				Config:             providerConfig + testAccBucketVersioningResourceConfig("Suspended", ""),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: providerConfig + testAccBucketVersioningResourceConfig("Enabled", ""),
+			},
+		},
+		// versioning is suspended as it cannot be turned off
+		CheckDestroy: testAccCheckBucketSubresource(server, "assets", "versioning", regexp.MustCompile(`<Status>Suspended</Status>`)),
+	})
+}
+
+func TestAccBucketVersioningResource_mfaDelete(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	mfaDeleteConfig := func(token string) string {
+		return providerConfig + testAccBucketVersioningResourceConfig("Enabled", fmt.Sprintf(`
+  mfa_delete = true
+  mfa        = "arn:aws:iam:::mfa/demo %s"`, token))
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		// mfa is write-only
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_11_0),
+		},
+		PreCheck: func() { testAccBucket(t, server) },
+		Steps: []resource.TestStep{
+			// MFA delete needs a token
+			{
+				Config:      providerConfig + testAccBucketVersioningResourceConfig("Enabled", "mfa_delete = true"),
+				ExpectError: regexp.MustCompile(`Missing MFA token`),
+			},
+			{
+				Config: mfaDeleteConfig("123456"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("radosgw_bucket_versioning.test", "mfa"),
+					testAccCheckBucketSubresource(server, "assets", "versioning", regexp.MustCompile(`<MfaDelete>Enabled</MfaDelete>`)),
+				),
+			},
+			// the token is not kept, so a new one changes nothing
+			{
+				Config:   mfaDeleteConfig("654321"),
+				PlanOnly: true,
+			},
+		},
+		// versioning is left enabled as suspending it needs a token
+		CheckDestroy: testAccCheckBucketSubresource(server, "assets", "versioning", regexp.MustCompile(`<Status>Enabled</Status>`)),
+	})
+}
+
+func testAccBucketVersioningResourceConfig(status, extra string) string {
+	return fmt.Sprintf(`
+resource "radosgw_bucket_versioning" "test" {
+  bucket = "assets"
+  status = %q
+  %s
+}
+`, status, extra)
+}
@@ -0,0 +1,439 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &bucketWebsiteConfigurationResource{}
+	_ resource.ResourceWithConfigure      = &bucketWebsiteConfigurationResource{}
+	_ resource.ResourceWithImportState    = &bucketWebsiteConfigurationResource{}
+	_ resource.ResourceWithValidateConfig = &bucketWebsiteConfigurationResource{}
+)
+
+var (
+	// noSlashPattern matches index document suffixes, which radosgw rejects
+	// if they contain a slash.
+	noSlashPattern = regexp.MustCompile(`^[^/]*$`)
+
+	httpErrorCodePattern    = regexp.MustCompile(`^[45][0-9]{2}$`)
+	httpRedirectCodePattern = regexp.MustCompile(`^3[0-9]{2}$`)
+)
+
+// NewBucketWebsiteConfigurationResource is a helper function to simplify the provider implementation.
+func NewBucketWebsiteConfigurationResource() resource.Resource {
+	return &bucketWebsiteConfigurationResource{}
+}
+
+// bucketWebsiteConfigurationResource is the resource implementation.
+type bucketWebsiteConfigurationResource struct {
+	s3 s3iface.S3API
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *bucketWebsiteConfigurationResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
+		return
+	}
+
+	r.s3 = s3.New(data.awsSession)
+}
+
+// Metadata returns the resource type name.
+func (r *bucketWebsiteConfigurationResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_bucket_website_configuration"
+}
+
+// Schema defines the schema for the resource.
+func (r *bucketWebsiteConfigurationResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	protocolValidators := []validator.String{
+		stringvalidator.OneOf("http", "https"),
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages the static website configuration of a bucket through the S3 API of radosgw.  The provider credentials must belong to the owner of the bucket or to a system user.\n\n" +
+			"Websites are only served if the `rgw_enable_static_website` option of radosgw is set, usually on a separate endpoint with `rgw_dns_s3website_name`.",
+		Attributes: map[string]schema.Attribute{
+			"bucket": schema.StringAttribute{
+				MarkdownDescription: "Name of the bucket.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"index_document": schema.StringAttribute{
+				MarkdownDescription: "Suffix appended to requests for directories, such as `index.html`.  Exactly one of `index_document` and `redirect_all_requests_to` must be set.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+					stringvalidator.RegexMatches(noSlashPattern, `must not contain "/"`),
+				},
+			},
+			"error_document": schema.StringAttribute{
+				MarkdownDescription: "Key of the object returned for 4xx errors.",
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+					stringvalidator.ConflictsWith(path.MatchRoot("redirect_all_requests_to")),
+				},
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"redirect_all_requests_to": schema.ListNestedBlock{
+				MarkdownDescription: "Redirects all requests to another host.",
+				Validators: []validator.List{
+					listvalidator.SizeAtMost(1),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"host_name": schema.StringAttribute{
+							MarkdownDescription: "Host to redirect to.",
+							Required:            true,
+							Validators: []validator.String{
+								stringvalidator.LengthAtLeast(1),
+							},
+						},
+						"protocol": schema.StringAttribute{
+							MarkdownDescription: "Protocol of the redirects, `http` or `https`.  Defaults to the protocol of the request.",
+							Optional:            true,
+							Validators:          protocolValidators,
+						},
+					},
+				},
+			},
+			"routing_rule": schema.ListNestedBlock{
+				MarkdownDescription: "Rule redirecting matching requests.  The first matching rule applies.  Routing rules can't be combined with `redirect_all_requests_to`.",
+				Validators: []validator.List{
+					listvalidator.ConflictsWith(path.MatchRoot("redirect_all_requests_to")),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Blocks: map[string]schema.Block{
+						"condition": schema.ListNestedBlock{
+							MarkdownDescription: "Condition for the redirect.  Without it, the rule matches all requests.",
+							Validators: []validator.List{
+								listvalidator.SizeAtMost(1),
+							},
+							NestedObject: schema.NestedBlockObject{
+								Attributes: map[string]schema.Attribute{
+									"key_prefix_equals": schema.StringAttribute{
+										MarkdownDescription: "Prefix of the keys the rule applies to.",
+										Optional:            true,
+									},
+									"http_error_code_returned_equals": schema.StringAttribute{
+										MarkdownDescription: "HTTP error code, such as `404`, on which the rule applies.",
+										Optional:            true,
+										Validators: []validator.String{
+											stringvalidator.RegexMatches(httpErrorCodePattern, "must be an HTTP error code, such as 404"),
+										},
+									},
+								},
+							},
+						},
+						"redirect": schema.ListNestedBlock{
+							MarkdownDescription: "Redirect of matching requests.",
+							Validators: []validator.List{
+								listvalidator.IsRequired(),
+								listvalidator.SizeBetween(1, 1),
+							},
+							NestedObject: schema.NestedBlockObject{
+								Attributes: map[string]schema.Attribute{
+									"host_name": schema.StringAttribute{
+										MarkdownDescription: "Host to redirect to.  Defaults to the host of the request.",
+										Optional:            true,
+									},
+									"protocol": schema.StringAttribute{
+										MarkdownDescription: "Protocol of the redirect, `http` or `https`.  Defaults to the protocol of the request.",
+										Optional:            true,
+										Validators:          protocolValidators,
+									},
+									"http_redirect_code": schema.StringAttribute{
+										MarkdownDescription: "HTTP status code of the redirect, such as `301`.  Defaults to `301`.",
+										Optional:            true,
+										Validators: []validator.String{
+											stringvalidator.RegexMatches(httpRedirectCodePattern, "must be an HTTP redirect code, such as 301"),
+										},
+									},
+									"replace_key_prefix_with": schema.StringAttribute{
+										MarkdownDescription: "Replacement of `key_prefix_equals` in the key of the redirect.",
+										Optional:            true,
+										Validators: []validator.String{
+											stringvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("replace_key_with")),
+										},
+									},
+									"replace_key_with": schema.StringAttribute{
+										MarkdownDescription: "Key of the redirect.",
+										Optional:            true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+type bucketWebsiteConfigurationResourceModel struct {
+	Bucket                types.String         `tfsdk:"bucket"`
+	IndexDocument         types.String         `tfsdk:"index_document"`
+	ErrorDocument         types.String         `tfsdk:"error_document"`
+	RedirectAllRequestsTo []websiteRedirectAll `tfsdk:"redirect_all_requests_to"`
+	RoutingRules          []websiteRoutingRule `tfsdk:"routing_rule"`
+}
+
+type websiteRedirectAll struct {
+	HostName types.String `tfsdk:"host_name"`
+	Protocol types.String `tfsdk:"protocol"`
+}
+
+type websiteRoutingRule struct {
+	Condition []websiteCondition `tfsdk:"condition"`
+	Redirect  []websiteRedirect  `tfsdk:"redirect"`
+}
+
+type websiteCondition struct {
+	KeyPrefixEquals             types.String `tfsdk:"key_prefix_equals"`
+	HTTPErrorCodeReturnedEquals types.String `tfsdk:"http_error_code_returned_equals"`
+}
+
+type websiteRedirect struct {
+	HostName             types.String `tfsdk:"host_name"`
+	Protocol             types.String `tfsdk:"protocol"`
+	HTTPRedirectCode     types.String `tfsdk:"http_redirect_code"`
+	ReplaceKeyPrefixWith types.String `tfsdk:"replace_key_prefix_with"`
+	ReplaceKeyWith       types.String `tfsdk:"replace_key_with"`
+}
+
+// errorTarget describes the website configuration for explaining S3 API
+// errors.
+func (m bucketWebsiteConfigurationResourceModel) errorTarget() rgwErrorTarget {
+	return rgwErrorTarget{
+		resourceType: "radosgw_bucket_website_configuration",
+		importID:     m.Bucket.ValueString(),
+		bucketPath:   path.Root("bucket"),
+	}
+}
+
+// ValidateConfig implements resource.ResourceWithValidateConfig.
+func (r *bucketWebsiteConfigurationResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	// blocks from dynamic blocks can be unknown, which the model cannot
+	// hold, so only the attributes checked are read
+	var indexDocument types.String
+	var redirectAllRequestsTo types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("index_document"), &indexDocument)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("redirect_all_requests_to"), &redirectAllRequestsTo)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if indexDocument.IsUnknown() || redirectAllRequestsTo.IsUnknown() {
+		return
+	}
+	if indexDocument.IsNull() == (len(redirectAllRequestsTo.Elements()) == 0) {
+		resp.Diagnostics.AddAttributeError(path.Root("index_document"), "Invalid website configuration",
+			"Exactly one of index_document and redirect_all_requests_to must be set.")
+	}
+}
+
+// Read implements resource.Resource.
+func (r *bucketWebsiteConfigurationResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state bucketWebsiteConfigurationResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	out, err := r.s3.GetBucketWebsiteWithContext(ctx, &s3.GetBucketWebsiteInput{
+		Bucket: aws.String(state.Bucket.ValueString()),
+	})
+	if code := awsErrorCode(err); code == "NoSuchWebsiteConfiguration" || code == "NoSuchBucket" {
+		tflog.Warn(ctx, "bucket website configuration removed outside of Terraform", map[string]any{"bucket": state.Bucket.ValueString(), "error_code": code})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error fetching bucket website configuration",
+			fmt.Sprintf("Could not fetch website configuration of bucket %q", state.Bucket.ValueString()), err)
+		return
+	}
+
+	state.IndexDocument = types.StringNull()
+	if out.IndexDocument != nil {
+		state.IndexDocument = optionalString(aws.StringValue(out.IndexDocument.Suffix))
+	}
+	state.ErrorDocument = types.StringNull()
+	if out.ErrorDocument != nil {
+		state.ErrorDocument = optionalString(aws.StringValue(out.ErrorDocument.Key))
+	}
+	state.RedirectAllRequestsTo = nil
+	if out.RedirectAllRequestsTo != nil {
+		state.RedirectAllRequestsTo = []websiteRedirectAll{{
+			HostName: types.StringValue(aws.StringValue(out.RedirectAllRequestsTo.HostName)),
+			Protocol: optionalString(aws.StringValue(out.RedirectAllRequestsTo.Protocol)),
+		}}
+	}
+	state.RoutingRules = websiteRoutingRulesFromS3(out.RoutingRules)
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// websiteRoutingRulesFromS3 converts the routing rules of a GetBucketWebsite
+// response to their model.
+func websiteRoutingRulesFromS3(rules []*s3.RoutingRule) []websiteRoutingRule {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	result := make([]websiteRoutingRule, 0, len(rules))
+	for _, rule := range rules {
+		var model websiteRoutingRule
+		if c := rule.Condition; c != nil && (c.KeyPrefixEquals != nil || c.HttpErrorCodeReturnedEquals != nil) {
+			model.Condition = []websiteCondition{{
+				KeyPrefixEquals:             types.StringPointerValue(c.KeyPrefixEquals),
+				HTTPErrorCodeReturnedEquals: optionalString(aws.StringValue(c.HttpErrorCodeReturnedEquals)),
+			}}
+		}
+		redirect := rule.Redirect
+		if redirect == nil {
+			redirect = &s3.Redirect{}
+		}
+		model.Redirect = []websiteRedirect{{
+			HostName:             optionalString(aws.StringValue(redirect.HostName)),
+			Protocol:             optionalString(aws.StringValue(redirect.Protocol)),
+			HTTPRedirectCode:     optionalString(aws.StringValue(redirect.HttpRedirectCode)),
+			ReplaceKeyPrefixWith: types.StringPointerValue(redirect.ReplaceKeyPrefixWith),
+			ReplaceKeyWith:       types.StringPointerValue(redirect.ReplaceKeyWith),
+		}}
+		result = append(result, model)
+	}
+	return result
+}
+
+// ImportState implements resource.ResourceWithImportState.  Website
+// configurations are imported by the name of their bucket.
+func (r *bucketWebsiteConfigurationResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("bucket"), req, resp)
+}
+
+// Create implements resource.Resource.
+func (r *bucketWebsiteConfigurationResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan bucketWebsiteConfigurationResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.put(ctx, plan); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error setting bucket website configuration",
+			fmt.Sprintf("Could not set website configuration of bucket %q", plan.Bucket.ValueString()), err)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *bucketWebsiteConfigurationResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan bucketWebsiteConfigurationResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.put(ctx, plan); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error updating bucket website configuration",
+			fmt.Sprintf("Could not update website configuration of bucket %q", plan.Bucket.ValueString()), err)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// put sets the website configuration of the bucket to that of plan.
+func (r *bucketWebsiteConfigurationResource) put(ctx context.Context, plan bucketWebsiteConfigurationResourceModel) error {
+	config := &s3.WebsiteConfiguration{}
+	if !plan.IndexDocument.IsNull() {
+		config.IndexDocument = &s3.IndexDocument{Suffix: plan.IndexDocument.ValueStringPointer()}
+	}
+	if !plan.ErrorDocument.IsNull() {
+		config.ErrorDocument = &s3.ErrorDocument{Key: plan.ErrorDocument.ValueStringPointer()}
+	}
+	for _, redirect := range plan.RedirectAllRequestsTo {
+		config.RedirectAllRequestsTo = &s3.RedirectAllRequestsTo{
+			HostName: redirect.HostName.ValueStringPointer(),
+			Protocol: redirect.Protocol.ValueStringPointer(),
+		}
+	}
+	for _, rule := range plan.RoutingRules {
+		routingRule := &s3.RoutingRule{Redirect: &s3.Redirect{}}
+		for _, c := range rule.Condition {
+			routingRule.Condition = &s3.Condition{
+				KeyPrefixEquals:             c.KeyPrefixEquals.ValueStringPointer(),
+				HttpErrorCodeReturnedEquals: c.HTTPErrorCodeReturnedEquals.ValueStringPointer(),
+			}
+		}
+		for _, redirect := range rule.Redirect {
+			routingRule.Redirect = &s3.Redirect{
+				HostName:             redirect.HostName.ValueStringPointer(),
+				Protocol:             redirect.Protocol.ValueStringPointer(),
+				HttpRedirectCode:     redirect.HTTPRedirectCode.ValueStringPointer(),
+				ReplaceKeyPrefixWith: redirect.ReplaceKeyPrefixWith.ValueStringPointer(),
+				ReplaceKeyWith:       redirect.ReplaceKeyWith.ValueStringPointer(),
+			}
+		}
+		config.RoutingRules = append(config.RoutingRules, routingRule)
+	}
+
+	_, err := r.s3.PutBucketWebsiteWithContext(ctx, &s3.PutBucketWebsiteInput{
+		Bucket:               aws.String(plan.Bucket.ValueString()),
+		WebsiteConfiguration: config,
+	})
+	return err
+}
+
+// Delete implements resource.Resource.
+func (r *bucketWebsiteConfigurationResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state bucketWebsiteConfigurationResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.s3.DeleteBucketWebsiteWithContext(ctx, &s3.DeleteBucketWebsiteInput{
+		Bucket: aws.String(state.Bucket.ValueString()),
+	})
+	if code := awsErrorCode(err); code == "NoSuchWebsiteConfiguration" || code == "NoSuchBucket" {
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error removing bucket website configuration",
+			fmt.Sprintf("Could not remove website configuration of bucket %q", state.Bucket.ValueString()), err)
+		return
+	}
+}
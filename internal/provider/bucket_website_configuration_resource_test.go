@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccBucketWebsiteConfigurationResource(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccBucket(t, server) },
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + testAccBucketWebsiteConfigurationResourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_bucket_website_configuration.test", "index_document", "index.html"),
+					resource.TestCheckResourceAttr("radosgw_bucket_website_configuration.test", "routing_rule.#", "1"),
+					resource.TestCheckResourceAttr("radosgw_bucket_website_configuration.test", "routing_rule.0.redirect.0.replace_key_prefix_with", "documents/"),
+					testAccCheckBucketSubresource(server, "assets", "website", regexp.MustCompile(`<Suffix>index.html</Suffix>`)),
+					testAccCheckBucketSubresource(server, "assets", "website", regexp.MustCompile(`<KeyPrefixEquals>docs/</KeyPrefixEquals>`)),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "radosgw_bucket_website_configuration.test",
+				ImportState:                          true,
+				ImportStateId:                        "assets",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "bucket",
+			},
+			// Update and Read testing
+			{
+				Config: providerConfig + `
+resource "radosgw_bucket_website_configuration" "test" {
+  bucket = "assets"
+
+  redirect_all_requests_to {
+    host_name = "www.example.com"
+    protocol  = "https"
+  }
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckNoResourceAttr("radosgw_bucket_website_configuration.test", "index_document"),
+					testAccCheckBucketSubresource(server, "assets", "website", regexp.MustCompile(`<HostName>www.example.com</HostName>`)),
+				),
+			},
+			// Removed outside of Terraform
+			{
+				PreConfig: func() {
+					server.RemoveBucketSubresource("assets", "website")
+				},
+				Config:             providerConfig + testAccBucketWebsiteConfigurationResourceConfig,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccBucketWebsiteConfigurationResource_dynamic(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccBucket(t, server) },
+		Steps: []resource.TestStep{
+			// the routing rules are unknown until terraform_data is applied
+			{
+				Config: providerConfig + `
+resource "terraform_data" "redirects" {
+  input = { "docs/" = "documents/" }
+}
+
+resource "radosgw_bucket_website_configuration" "test" {
+  bucket         = "assets"
+  index_document = "index.html"
+
+  dynamic "routing_rule" {
+    for_each = terraform_data.redirects.output
+    content {
+      condition {
+        key_prefix_equals = routing_rule.key
+      }
+      redirect {
+        replace_key_prefix_with = routing_rule.value
+      }
+    }
+  }
+}
+`,
+				Check: testAccCheckBucketSubresource(server, "assets", "website", regexp.MustCompile(`<ReplaceKeyPrefixWith>documents/</ReplaceKeyPrefixWith>`)),
+			},
+		},
+	})
+}
+
+func TestAccBucketWebsiteConfigurationResource_validation(t *testing.T) {
+	_, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "radosgw_bucket_website_configuration" "test" {
+  bucket = "assets"
+}
+`,
+				ExpectError: regexp.MustCompile(`Exactly one of index_document and redirect_all_requests_to must be set`),
+			},
+			{
+				Config: providerConfig + `
+resource "radosgw_bucket_website_configuration" "test" {
+  bucket         = "assets"
+  index_document = "docs/index.html"
+}
+`,
+				ExpectError: regexp.MustCompile(`must not contain "/"`),
+			},
+		},
+	})
+}
+
+const testAccBucketWebsiteConfigurationResourceConfig = `
+resource "radosgw_bucket_website_configuration" "test" {
+  bucket         = "assets"
+  index_document = "index.html"
+  error_document = "error.html"
+
+  routing_rule {
+    condition {
+      key_prefix_equals = "docs/"
+    }
+    redirect {
+      replace_key_prefix_with = "documents/"
+    }
+  }
+}
+`
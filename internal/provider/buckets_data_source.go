@@ -5,9 +5,7 @@ package provider
 
 import (
 	"context"
-	"fmt"
 
-	"github.com/ceph/go-ceph/rgw/admin"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -22,7 +20,7 @@ func NewBucketsDataSource() datasource.DataSource {
 
 // bucketsDataSource defines the data source implementation.
 type bucketsDataSource struct {
-	client *admin.API
+	client adminClient
 }
 
 func (d *bucketsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
@@ -52,22 +50,12 @@ func (d *bucketsDataSource) Schema(ctx context.Context, req datasource.SchemaReq
 }
 
 func (d *bucketsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
-	// Prevent panic if the provider has not been configured.
-	if req.ProviderData == nil {
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
 		return
 	}
 
-	client, ok := req.ProviderData.(*admin.API)
-	if !ok {
-		resp.Diagnostics.AddError(
-			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *admin.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
-		)
-
-		return
-	}
-
-	d.client = client
+	d.client = data.client
 }
 
 type bucketsDataSourceModel struct {
@@ -84,10 +72,7 @@ func (d *bucketsDataSource) Read(ctx context.Context, req datasource.ReadRequest
 
 	buckets, err := d.client.ListBucketsWithStat(ctx)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Unable to list buckets",
-			err.Error(),
-		)
+		addRGWError(&resp.Diagnostics, rgwErrorTarget{}, "Unable to list buckets", "Could not list buckets", err)
 		return
 	}
 
@@ -0,0 +1,42 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccBucketsDataSource(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck: func() {
+			if _, err := server.API().CreateUser(context.Background(), admin.User{ID: "demo", DisplayName: "Demo user"}); err != nil {
+				t.Fatal(err)
+			}
+			for _, bucket := range []string{"assets", "backups"} {
+				if err := server.CreateBucket(bucket, "demo"); err != nil {
+					t.Fatal(err)
+				}
+			}
+		},
+		Steps: []resource.TestStep{
+			// Read testing
+			{
+				Config: providerConfig + `data "radosgw_buckets" "test" {}`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.radosgw_buckets.test", "buckets.#", "2"),
+					resource.TestCheckResourceAttr("data.radosgw_buckets.test", "buckets.0.bucket", "assets"),
+					resource.TestCheckResourceAttr("data.radosgw_buckets.test", "buckets.0.owner", "demo"),
+					resource.TestCheckResourceAttr("data.radosgw_buckets.test", "buckets.1.bucket", "backups"),
+				),
+			},
+		},
+	})
+}
@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// userCacheTTL is how long users fetched by the caching client are reused.
+//
+// It is kept short so that changes made outside of Terraform are still
+// picked up by long running applies.
+const userCacheTTL = 30 * time.Second
+
+// cachingClient caches users returned by GetUser, so that the many resources
+// belonging to the same user only fetch it once during a plan or apply.
+//
+// Concurrent lookups of the same user share a single request, and all
+// mutating calls drop the cached user they affect.
+type cachingClient struct {
+	adminClient
+
+	ttl time.Duration
+	now func() time.Time
+
+	mu    sync.Mutex
+	users map[string]*cachedUser
+}
+
+// cachedUser is a user lookup that is in flight or done.
+type cachedUser struct {
+	// done is closed once user and err are set.
+	done chan struct{}
+
+	user admin.User
+	err  error
+
+	// expires is zero while the lookup is in flight.
+	expires time.Time
+}
+
+var _ adminClient = &cachingClient{}
+
+func newCachingClient(client adminClient, ttl time.Duration) *cachingClient {
+	return &cachingClient{
+		adminClient: client,
+		ttl:         ttl,
+		now:         time.Now,
+		users:       make(map[string]*cachedUser),
+	}
+}
+
+// GetUser implements adminClient.
+//
+// Only lookups by user ID are cached, lookups by access key and with stats
+// are passed through.
+func (c *cachingClient) GetUser(ctx context.Context, user admin.User) (admin.User, error) {
+	if user.ID == "" || len(user.Keys) > 0 || (user.GenerateStat != nil && *user.GenerateStat) {
+		return c.adminClient.GetUser(ctx, user)
+	}
+
+	c.mu.Lock()
+	entry, ok := c.users[user.ID]
+	if ok && !entry.expires.IsZero() && c.now().After(entry.expires) {
+		ok = false
+	}
+	if !ok {
+		entry = &cachedUser{done: make(chan struct{})}
+		c.users[user.ID] = entry
+		c.mu.Unlock()
+
+		entry.user, entry.err = c.adminClient.GetUser(ctx, user)
+
+		c.mu.Lock()
+		entry.expires = c.now().Add(c.ttl)
+		// errors are not cached, the next lookup tries again
+		if entry.err != nil && c.users[user.ID] == entry {
+			delete(c.users, user.ID)
+		}
+		close(entry.done)
+		c.mu.Unlock()
+	} else {
+		c.mu.Unlock()
+		tflog.Trace(ctx, "using cached user", map[string]any{"user_id": user.ID})
+	}
+
+	select {
+	case <-entry.done:
+	case <-ctx.Done():
+		return admin.User{}, ctx.Err()
+	}
+
+	return cloneUser(entry.user), entry.err
+}
+
+// invalidate drops the cached user with the given ID, or all cached users if
+// the ID is not known.
+func (c *cachingClient) invalidate(uid string) {
+	// keys and subusers are addressed as "user:subuser" in some calls
+	uid, _, _ = strings.Cut(uid, ":")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if uid == "" {
+		c.users = make(map[string]*cachedUser)
+		return
+	}
+	delete(c.users, uid)
+}
+
+// CreateUser implements adminClient.
+func (c *cachingClient) CreateUser(ctx context.Context, user admin.User) (admin.User, error) {
+	defer c.invalidate(user.ID)
+	return c.adminClient.CreateUser(ctx, user)
+}
+
+// ModifyUser implements adminClient.
+func (c *cachingClient) ModifyUser(ctx context.Context, user admin.User) (admin.User, error) {
+	defer c.invalidate(user.ID)
+	return c.adminClient.ModifyUser(ctx, user)
+}
+
+// RemoveUser implements adminClient.
+func (c *cachingClient) RemoveUser(ctx context.Context, user admin.User) error {
+	defer c.invalidate(user.ID)
+	return c.adminClient.RemoveUser(ctx, user)
+}
+
+// CreateSubuser implements adminClient.
+func (c *cachingClient) CreateSubuser(ctx context.Context, user admin.User, subuser admin.SubuserSpec) error {
+	defer c.invalidate(user.ID)
+	return c.adminClient.CreateSubuser(ctx, user, subuser)
+}
+
+// ModifySubuser implements adminClient.
+func (c *cachingClient) ModifySubuser(ctx context.Context, user admin.User, subuser admin.SubuserSpec) error {
+	defer c.invalidate(user.ID)
+	return c.adminClient.ModifySubuser(ctx, user, subuser)
+}
+
+// RemoveSubuser implements adminClient.
+func (c *cachingClient) RemoveSubuser(ctx context.Context, user admin.User, subuser admin.SubuserSpec) error {
+	defer c.invalidate(user.ID)
+	return c.adminClient.RemoveSubuser(ctx, user, subuser)
+}
+
+// CreateKey implements adminClient.
+func (c *cachingClient) CreateKey(ctx context.Context, key admin.UserKeySpec) (*[]admin.UserKeySpec, error) {
+	defer c.invalidate(key.UID)
+	return c.adminClient.CreateKey(ctx, key)
+}
+
+// RemoveKey implements adminClient.
+func (c *cachingClient) RemoveKey(ctx context.Context, key admin.UserKeySpec) error {
+	defer c.invalidate(key.UID)
+	return c.adminClient.RemoveKey(ctx, key)
+}
+
+// AddUserCap implements adminClient.
+func (c *cachingClient) AddUserCap(ctx context.Context, uid, userCap string) ([]admin.UserCapSpec, error) {
+	defer c.invalidate(uid)
+	return c.adminClient.AddUserCap(ctx, uid, userCap)
+}
+
+// RemoveUserCap implements adminClient.
+func (c *cachingClient) RemoveUserCap(ctx context.Context, uid, userCap string) ([]admin.UserCapSpec, error) {
+	defer c.invalidate(uid)
+	return c.adminClient.RemoveUserCap(ctx, uid, userCap)
+}
+
+// SetUserQuota implements adminClient.
+func (c *cachingClient) SetUserQuota(ctx context.Context, quota admin.QuotaSpec) error {
+	defer c.invalidate(quota.UID)
+	return c.adminClient.SetUserQuota(ctx, quota)
+}
+
+// SetIndividualBucketQuota implements adminClient.
+func (c *cachingClient) SetIndividualBucketQuota(ctx context.Context, quota admin.QuotaSpec) error {
+	defer c.invalidate(quota.UID)
+	return c.adminClient.SetIndividualBucketQuota(ctx, quota)
+}
+
+// cloneUser copies the slices of user, so that callers can't modify the
+// cached user.
+func cloneUser(user admin.User) admin.User {
+	user.Subusers = append([]admin.SubuserSpec(nil), user.Subusers...)
+	user.Keys = append([]admin.UserKeySpec(nil), user.Keys...)
+	user.SwiftKeys = append([]admin.SwiftKeySpec(nil), user.SwiftKeys...)
+	user.Caps = append([]admin.UserCapSpec(nil), user.Caps...)
+	return user
+}
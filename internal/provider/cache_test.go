@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+)
+
+// countingClient counts GetUser calls, all other calls panic.
+type countingClient struct {
+	adminClient
+
+	calls atomic.Int32
+	err   error
+	// block, if set, delays GetUser until it is closed.
+	block chan struct{}
+}
+
+func (c *countingClient) GetUser(ctx context.Context, user admin.User) (admin.User, error) {
+	c.calls.Add(1)
+	if c.block != nil {
+		<-c.block
+	}
+	if c.err != nil {
+		return admin.User{}, c.err
+	}
+	return admin.User{ID: user.ID, Keys: []admin.UserKeySpec{{User: user.ID, AccessKey: "AK"}}}, nil
+}
+
+func (c *countingClient) ModifyUser(ctx context.Context, user admin.User) (admin.User, error) {
+	return user, nil
+}
+
+func (c *countingClient) CreateKey(ctx context.Context, key admin.UserKeySpec) (*[]admin.UserKeySpec, error) {
+	return &[]admin.UserKeySpec{key}, nil
+}
+
+func TestCachingClient(t *testing.T) {
+	ctx := context.Background()
+	backend := &countingClient{}
+	client := newCachingClient(backend, time.Minute)
+	now := time.Now()
+	client.now = func() time.Time { return now }
+
+	getUser := func(uid string, wantCalls int32) {
+		t.Helper()
+		user, err := client.GetUser(ctx, admin.User{ID: uid})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user.ID != uid {
+			t.Errorf("got user %q, want %q", user.ID, uid)
+		}
+		if calls := backend.calls.Load(); calls != wantCalls {
+			t.Errorf("got %d GetUser calls, want %d", calls, wantCalls)
+		}
+	}
+
+	getUser("demo", 1)
+	getUser("demo", 1)
+	getUser("other", 2)
+
+	// callers can't modify the cached user
+	user, _ := client.GetUser(ctx, admin.User{ID: "demo"})
+	user.Keys[0].AccessKey = "modified"
+	getUser("demo", 2)
+	if user, _ := client.GetUser(ctx, admin.User{ID: "demo"}); user.Keys[0].AccessKey != "AK" {
+		t.Errorf("cached user was modified: %+v", user)
+	}
+
+	// mutations drop the affected user only
+	if _, err := client.ModifyUser(ctx, admin.User{ID: "demo"}); err != nil {
+		t.Fatal(err)
+	}
+	getUser("demo", 3)
+	getUser("other", 3)
+
+	// keys of subusers drop their parent user
+	if _, err := client.CreateKey(ctx, admin.UserKeySpec{UID: "demo:readonly"}); err != nil {
+		t.Fatal(err)
+	}
+	getUser("demo", 4)
+
+	// expired users are fetched again
+	now = now.Add(2 * time.Minute)
+	getUser("demo", 5)
+	getUser("demo", 5)
+
+	// lookups by access key are not cached
+	if _, err := client.GetUser(ctx, admin.User{Keys: []admin.UserKeySpec{{AccessKey: "AK"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if calls := backend.calls.Load(); calls != 6 {
+		t.Errorf("got %d GetUser calls, want 6", calls)
+	}
+}
+
+func TestCachingClient_errors(t *testing.T) {
+	ctx := context.Background()
+	backend := &countingClient{err: admin.ErrNoSuchUser}
+	client := newCachingClient(backend, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetUser(ctx, admin.User{ID: "demo"}); !errors.Is(err, admin.ErrNoSuchUser) {
+			t.Fatalf("got error %v, want %v", err, admin.ErrNoSuchUser)
+		}
+	}
+	if calls := backend.calls.Load(); calls != 2 {
+		t.Errorf("got %d GetUser calls, want 2", calls)
+	}
+}
+
+func TestCachingClient_concurrent(t *testing.T) {
+	ctx := context.Background()
+	backend := &countingClient{block: make(chan struct{})}
+	client := newCachingClient(backend, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetUser(ctx, admin.User{ID: "demo"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	// wait for the first lookup to start before letting it finish
+	for backend.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(backend.block)
+	wg.Wait()
+
+	if calls := backend.calls.Load(); calls != 1 {
+		t.Errorf("got %d GetUser calls, want 1", calls)
+	}
+}
@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// adminClient is the part of the radosgw admin API used by the provider.
+//
+// It is implemented by *admin.API, and can be wrapped to add behaviour to
+// all calls or replaced by a fake in tests.
+type adminClient interface {
+	// users
+	GetUser(ctx context.Context, user admin.User) (admin.User, error)
+	GetUsers(ctx context.Context) (*[]string, error)
+	CreateUser(ctx context.Context, user admin.User) (admin.User, error)
+	ModifyUser(ctx context.Context, user admin.User) (admin.User, error)
+	RemoveUser(ctx context.Context, user admin.User) error
+
+	// subusers
+	CreateSubuser(ctx context.Context, user admin.User, subuser admin.SubuserSpec) error
+	ModifySubuser(ctx context.Context, user admin.User, subuser admin.SubuserSpec) error
+	RemoveSubuser(ctx context.Context, user admin.User, subuser admin.SubuserSpec) error
+
+	// keys
+	CreateKey(ctx context.Context, key admin.UserKeySpec) (*[]admin.UserKeySpec, error)
+	RemoveKey(ctx context.Context, key admin.UserKeySpec) error
+
+	// caps
+	AddUserCap(ctx context.Context, uid, userCap string) ([]admin.UserCapSpec, error)
+	RemoveUserCap(ctx context.Context, uid, userCap string) ([]admin.UserCapSpec, error)
+
+	// quotas
+	GetUserQuota(ctx context.Context, quota admin.QuotaSpec) (admin.QuotaSpec, error)
+	SetUserQuota(ctx context.Context, quota admin.QuotaSpec) error
+	SetIndividualBucketQuota(ctx context.Context, quota admin.QuotaSpec) error
+
+	// buckets
+	ListBuckets(ctx context.Context) ([]string, error)
+	ListBucketsWithStat(ctx context.Context) ([]admin.Bucket, error)
+	ListUsersBuckets(ctx context.Context, uid string) ([]string, error)
+	GetBucketInfo(ctx context.Context, bucket admin.Bucket) (admin.Bucket, error)
+	RemoveBucket(ctx context.Context, bucket admin.Bucket) error
+	LinkBucket(ctx context.Context, link admin.BucketLinkInput) error
+	UnlinkBucket(ctx context.Context, link admin.BucketLinkInput) error
+}
+
+var _ adminClient = &admin.API{}
+
+// radosgwProviderData is passed from the provider to its resources and data
+// sources.
+type radosgwProviderData struct {
+	// client is the admin API client.
+	client adminClient
+
+	// rawAdmin calls the admin API where go-ceph has no methods.
+	rawAdmin *rawAdminClient
+
+	// endpoint, accessKeyID and secretAccessKey are the radosgw endpoint
+	// and credentials the provider is configured with, which are also used
+	// for the S3 API.
+	endpoint        string
+	accessKeyID     string
+	secretAccessKey string
+
+	// awsSession is the session for the S3 API and the other AWS APIs of
+	// radosgw.
+	awsSession *session.Session
+
+	// adoptExisting is whether resources take over existing objects on
+	// create, unless they set adopt_existing themselves.
+	adoptExisting bool
+}
+
+// configureProviderData returns the provider data passed to Configure of a
+// resource or data source.  It returns nil if the provider has not been
+// configured yet, or if the provider data is invalid, which is added to
+// diags.
+func configureProviderData(providerData any, diags *diag.Diagnostics) *radosgwProviderData {
+	// Prevent panic if the provider has not been configured.
+	if providerData == nil {
+		return nil
+	}
+
+	data, ok := providerData.(*radosgwProviderData)
+	if !ok {
+		diags.AddError(
+			"Unexpected Configure Type",
+			fmt.Sprintf("Expected *radosgwProviderData, got: %T. Please report this issue to the provider developers.", providerData),
+		)
+		return nil
+	}
+
+	return data
+}
+
+// credentialsTenant returns the tenant of the user owning the access key
+// accessKeyID.  Looking up the user needs the users=read cap.
+func credentialsTenant(ctx context.Context, client adminClient, accessKeyID string) (string, error) {
+	user, err := client.GetUser(ctx, admin.User{Keys: []admin.UserKeySpec{{AccessKey: accessKeyID}}})
+	if err != nil {
+		return "", err
+	}
+
+	tenant, _ := splitUserID(user.ID)
+	return tenant, nil
+}
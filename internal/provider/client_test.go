@@ -0,0 +1,24 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+func TestConfigureProviderData(t *testing.T) {
+	var diags diag.Diagnostics
+	if data := configureProviderData(nil, &diags); data != nil || diags.HasError() {
+		t.Errorf("unconfigured provider: got %v, %v", data, diags)
+	}
+
+	want := &radosgwProviderData{client: &admin.API{}}
+	if data := configureProviderData(want, &diags); data != want || diags.HasError() {
+		t.Errorf("configured provider: got %v, %v", data, diags)
+	}
+
+	if data := configureProviderData(&admin.API{}, &diags); data != nil || !diags.HasError() {
+		t.Errorf("invalid provider data: got %v, %v", data, diags)
+	}
+}
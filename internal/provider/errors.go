@@ -0,0 +1,168 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// quotaErrorCodes are the radosgw error codes of exceeded quotas, which
+// go-ceph has no errors for.
+var quotaErrorCodes = []string{"QuotaExceeded", "TooManyBuckets"}
+
+// rgwErrorTarget describes what an admin API call operates on, so that its
+// errors can be attached to the attributes concerned and come with hints
+// specific to the resource.
+type rgwErrorTarget struct {
+	// resourceType and importID tell how to import the object into
+	// Terraform, if it already exists.
+	resourceType string
+	importID     string
+
+	// userPath, subuserPath, keyPath and bucketPath are the attributes
+	// holding the user, subuser, access key and bucket, empty if the
+	// resource has none.
+	userPath    path.Path
+	subuserPath path.Path
+	keyPath     path.Path
+	bucketPath  path.Path
+
+	// policyPath is the attribute holding a policy document.
+	policyPath path.Path
+
+	// endpointPath is the attribute holding the push endpoint of a topic.
+	endpointPath path.Path
+
+	// grantPath is the attribute holding the grants of an ACL.
+	grantPath path.Path
+
+	// rolePath is the attribute holding the name of an IAM role.
+	rolePath path.Path
+
+	// caps are the caps the user of the provider credentials needs for the
+	// IAM API, such as "roles=*".
+	caps string
+}
+
+// errorCodeOf returns the radosgw error code of err, or "" if err is not an
+// error response by radosgw.
+//
+// go-ceph does not export its error type, but formats it starting with the
+// error code.  Errors of the S3 API carry their code.
+func errorCodeOf(err error) string {
+	if code := awsErrorCode(err); code != "" {
+		return code
+	}
+
+	var reasonErr interface {
+		error
+		Is(error) bool
+	}
+	if !errors.As(err, &reasonErr) {
+		return ""
+	}
+	code, _, _ := strings.Cut(reasonErr.Error(), " ")
+	return code
+}
+
+// explainRGWError returns a hint how to resolve err and the attribute it
+// concerns, if err is a well-known radosgw error.
+func explainRGWError(target rgwErrorTarget, err error) (string, path.Path) {
+	switch {
+	case errors.Is(err, admin.ErrUserExists):
+		return existsHint("The user already exists.", target), target.userPath
+	case errors.Is(err, admin.ErrSubuserExists):
+		return existsHint("The subuser already exists.", target), target.subuserPath
+	case errors.Is(err, admin.ErrNoSuchUser):
+		return "The user does not exist.  Check tenant and user ID, and create the user first, for example with a radosgw_user resource.", target.userPath
+	case errors.Is(err, admin.ErrKeyExists):
+		return existsHint("The access key already exists, possibly for another user.  Choose a different access key, or import the existing key.", target), target.keyPath
+	case errors.Is(err, admin.ErrNoSuchKey):
+		return "The key does not exist, it may have been removed outside of Terraform.", target.keyPath
+	case errors.Is(err, admin.ErrInvalidAccessKey), errors.Is(err, admin.ErrInvalidSecretKey):
+		return "The key was rejected by radosgw.  Check that the access key and secret key are not empty and contain no invalid characters.", target.keyPath
+	case errors.Is(err, admin.ErrEmailExists):
+		return "The email address is already used by another user.", target.userPath
+	case errors.Is(err, admin.ErrBucketNotEmpty):
+		return "The bucket still contains objects.  Remove them first, as radosgw does not delete buckets with objects.", target.bucketPath
+	case errors.Is(err, admin.ErrNoSuchBucket):
+		return "The bucket does not exist, it may have been removed outside of Terraform.", target.bucketPath
+	case errors.Is(err, admin.ErrAccessDenied):
+		return "The provider credentials are not allowed to do this.  Make sure the access_key_id of the provider belongs to a user with the needed admin caps, for example \"users=*;buckets=*\".", path.Empty()
+	case errors.Is(err, admin.ErrSignatureDoesNotMatch):
+		return "The request signature was rejected.  Check the secret_access_key of the provider.", path.Empty()
+	}
+
+	code := errorCodeOf(err)
+	switch code {
+	case "NoSuchBucket":
+		return "The bucket does not exist.  Buckets are not managed by this provider, create it with an S3 client first.", target.bucketPath
+	case "AccessDenied":
+		if target.caps != "" {
+			return fmt.Sprintf("The provider credentials are not allowed to do this.  Their user needs the %q cap, which can be added with \"radosgw-admin caps add --uid=<user> --caps='%s'\".", target.caps, target.caps), path.Empty()
+		}
+		return "The provider credentials are not allowed to do this.  The S3 API is called with the credentials of the provider, so their user must own the bucket or be a system user.", path.Empty()
+	case "SignatureDoesNotMatch":
+		return "The request signature was rejected.  Check the secret_access_key of the provider.", path.Empty()
+	case "InvalidArgument":
+		if len(target.endpointPath.Steps()) > 0 {
+			return "radosgw rejected the topic.  Check that the push endpoint is reachable from radosgw, that AMQP endpoints have an amqp-exchange and that credentials are only sent over TLS.", target.endpointPath
+		}
+		if len(target.grantPath.Steps()) > 0 {
+			return "radosgw rejected the ACL.  Check that the users of the grants exist, written \"<tenant>$<user>\" for users of a tenant.", target.grantPath
+		}
+	case "InvalidBucketState":
+		return "The bucket does not allow this in its current state.  Object lock needs versioning enabled on the bucket first, for example with a radosgw_bucket_versioning resource the object lock configuration depends on, and buckets with object lock cannot suspend versioning.", target.bucketPath
+	case "EntityAlreadyExists":
+		return existsHint("The role already exists in the tenant of the provider credentials.", target), target.rolePath
+	case "NoSuchEntity":
+		if len(target.rolePath.Steps()) == 0 {
+			return "The user does not exist.  Check tenant and user, and create the user first, for example with a radosgw_user resource.", target.userPath
+		}
+		return "The role does not exist in the tenant of the provider credentials, it may have been removed outside of Terraform.", target.rolePath
+	case "DeleteConflict":
+		return "The role still has permission policies, which radosgw requires to be removed first.  Manage them with radosgw_iam_role_policy resources, or remove them outside of Terraform.", target.rolePath
+	case "MalformedPolicyDocument":
+		return "radosgw rejected the policy.  Check that it is a JSON policy document with at least one statement.", target.policyPath
+	case "MalformedPolicy":
+		return "radosgw rejected the policy.  Check that it only uses actions, condition keys and principals supported by radosgw.", target.policyPath
+	}
+	for _, quotaCode := range quotaErrorCodes {
+		if code == quotaCode {
+			return "A quota of the user was exceeded.  Raise the quota or max buckets of the user, or remove data it no longer needs.", target.userPath
+		}
+	}
+
+	return "", path.Empty()
+}
+
+// existsHint adds how to import the existing object to hint.
+func existsHint(hint string, target rgwErrorTarget) string {
+	if target.resourceType == "" || target.importID == "" {
+		return hint
+	}
+	return fmt.Sprintf("%s  To manage it with Terraform, import it with:\n\n    terraform import %s.<name> '%s'", hint, target.resourceType, target.importID)
+}
+
+// addRGWError adds a diagnostic for the admin API error err to diags.
+//
+// The detail describes what failed, the error and, for well-known errors, how
+// to resolve it.  Such errors are attached to the attribute they concern.
+func addRGWError(diags *diag.Diagnostics, target rgwErrorTarget, summary, detail string, err error) {
+	detail = fmt.Sprintf("%s: %s", detail, err)
+
+	hint, attr := explainRGWError(target, err)
+	if hint != "" {
+		detail += "\n\n" + hint
+	}
+
+	if len(attr.Steps()) == 0 {
+		diags.AddError(summary, detail)
+		return
+	}
+	diags.AddAttributeError(attr, summary, detail)
+}
@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+func TestAddRGWError(t *testing.T) {
+	server, _ := testAccServer(t)
+	ctx := context.Background()
+
+	// real errors from the fake radosgw, as go-ceph does not export its error type
+	if _, err := server.API().CreateUser(ctx, admin.User{ID: "demo", DisplayName: "Demo user"}); err != nil {
+		t.Fatal(err)
+	}
+	_, userExists := server.API().CreateUser(ctx, admin.User{ID: "demo", DisplayName: "Demo user"})
+	_, noSuchUser := server.API().GetUser(ctx, admin.User{ID: "missing"})
+
+	target := rgwErrorTarget{
+		resourceType: "radosgw_user",
+		importID:     "demo",
+		userPath:     path.Root("user_id"),
+	}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantPath   path.Path
+		wantDetail string
+	}{
+		{"user exists", userExists, path.Root("user_id"), "terraform import radosgw_user.<name> 'demo'"},
+		{"wrapped no such user", fmt.Errorf("wrapped: %w", noSuchUser), path.Root("user_id"), "The user does not exist."},
+		{"unknown", errors.New("connection refused"), path.Empty(), "Could not do it: connection refused"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			addRGWError(&diags, target, "Error", "Could not do it", tt.err)
+			if len(diags) != 1 {
+				t.Fatalf("got %d diagnostics, want 1", len(diags))
+			}
+
+			var gotPath path.Path
+			if withPath, ok := diags[0].(diag.DiagnosticWithPath); ok {
+				gotPath = withPath.Path()
+			}
+			if gotPath.String() != tt.wantPath.String() {
+				t.Errorf("got path %s, want %s", gotPath, tt.wantPath)
+			}
+			if !strings.Contains(diags[0].Detail(), tt.wantDetail) {
+				t.Errorf("detail %q does not contain %q", diags[0].Detail(), tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestErrorCodeOf(t *testing.T) {
+	server, _ := testAccServer(t)
+
+	_, err := server.API().GetUser(context.Background(), admin.User{ID: "missing"})
+	if got := errorCodeOf(fmt.Errorf("wrapped: %w", err)); got != "NoSuchUser" {
+		t.Errorf("got code %q, want NoSuchUser", got)
+	}
+	if got := errorCodeOf(awserr.New("NoSuchBucketPolicy", "The bucket policy does not exist", nil)); got != "NoSuchBucketPolicy" {
+		t.Errorf("got code %q, want NoSuchBucketPolicy", got)
+	}
+	if got := errorCodeOf(errors.New("NoSuchUser")); got != "" {
+		t.Errorf("got code %q for a non-radosgw error", got)
+	}
+}
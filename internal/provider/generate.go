@@ -0,0 +1,236 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// GenerateOptions configures Generate.
+type GenerateOptions struct {
+	// Endpoint, AccessKeyID and SecretAccessKey are the radosgw admin
+	// endpoint and credentials, as for the provider.
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// Generate writes Terraform configuration for all users, subusers and keys
+// of radosgw to w, with import blocks to import them into the state.
+//
+// Secret keys are not written, they are read from radosgw on import.  The key
+// of the credentials is skipped, so that Terraform does not remove it.
+func Generate(ctx context.Context, w io.Writer, opts GenerateOptions) error {
+	client, err := admin.New(opts.Endpoint, opts.AccessKeyID, opts.SecretAccessKey, &loggingHTTPClient{client: http.DefaultClient})
+	if err != nil {
+		return fmt.Errorf("creating radosgw admin client: %w", err)
+	}
+
+	file, err := generateConfig(ctx, client, opts.AccessKeyID)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.WriteTo(w)
+	return err
+}
+
+// GenerateFile writes the configuration of Generate to the file at path.  It
+// is written to a temporary file next to it first, which only replaces the
+// file once complete, so that failures leave an existing file as it is.
+func GenerateFile(ctx context.Context, path string, opts GenerateOptions) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	// removing fails once the file is renamed, which is fine
+	defer os.Remove(tmp.Name())
+
+	if err := Generate(ctx, tmp, opts); err != nil {
+		tmp.Close()
+		return err
+	}
+	// temporary files are only readable by their owner
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// generateConfig returns the configuration with import blocks for all users,
+// subusers and keys, except for the key with ownAccessKey.
+func generateConfig(ctx context.Context, client adminClient, ownAccessKey string) (*hclwrite.File, error) {
+	uids, err := client.GetUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing users: %w", err)
+	}
+	sort.Strings(*uids)
+
+	g := &generator{
+		file:         hclwrite.NewEmptyFile(),
+		names:        make(map[string]bool),
+		ownAccessKey: ownAccessKey,
+	}
+	g.comment("Generated by terraform-provider-radosgw generate.  Run \"terraform plan\" to import the objects below.")
+
+	for _, uid := range *uids {
+		user, err := client.GetUser(ctx, admin.User{ID: uid})
+		if err != nil {
+			return nil, fmt.Errorf("fetching user %q: %w", uid, err)
+		}
+		g.addUser(user)
+	}
+
+	return g.file, nil
+}
+
+// generator writes resources with import blocks to file.
+type generator struct {
+	file *hclwrite.File
+	// names holds the resource names in use.
+	names map[string]bool
+	// ownAccessKey is the access key of the credentials used.
+	ownAccessKey string
+}
+
+// invalidNameChars matches characters not allowed in resource names.
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// name returns an unused resource name derived from id.
+func (g *generator) name(id string) string {
+	base := invalidNameChars.ReplaceAllString(id, "_")
+	if base == "" || !(base[0] == '_' || base[0] >= 'a' && base[0] <= 'z' || base[0] >= 'A' && base[0] <= 'Z') {
+		base = "_" + base
+	}
+
+	name := base
+	for i := 2; g.names[name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	g.names[name] = true
+	return name
+}
+
+// comment appends a comment line.
+func (g *generator) comment(format string, args ...any) {
+	g.file.Body().AppendUnstructuredTokens(hclwrite.Tokens{{
+		Type:  hclsyntax.TokenComment,
+		Bytes: []byte("# " + fmt.Sprintf(format, args...) + "\n"),
+	}})
+}
+
+// resource appends a resource with an import block importing it as id and
+// returns the body of the resource.
+func (g *generator) resource(resourceType, name, id string) *hclwrite.Body {
+	body := g.file.Body()
+	body.AppendNewline()
+
+	importBlock := body.AppendNewBlock("import", nil).Body()
+	importBlock.SetAttributeTraversal("to", hcl.Traversal{
+		hcl.TraverseRoot{Name: resourceType},
+		hcl.TraverseAttr{Name: name},
+	})
+	importBlock.SetAttributeValue("id", cty.StringVal(id))
+
+	body.AppendNewline()
+	return body.AppendNewBlock("resource", []string{resourceType, name}).Body()
+}
+
+// reference returns the traversal of an attribute of a resource.
+func reference(resourceType, name, attribute string) hcl.Traversal {
+	return hcl.Traversal{
+		hcl.TraverseRoot{Name: resourceType},
+		hcl.TraverseAttr{Name: name},
+		hcl.TraverseAttr{Name: attribute},
+	}
+}
+
+// addUser appends the user, its subusers and its keys.
+func (g *generator) addUser(user admin.User) {
+	tenant, userID := splitUserID(user.ID)
+
+	userName := g.name(user.ID)
+	if len(user.Caps) > 0 {
+		g.file.Body().AppendNewline()
+		caps := make([]string, 0, len(user.Caps))
+		for _, userCap := range user.Caps {
+			caps = append(caps, userCap.Type+"="+userCap.Perm)
+		}
+		g.comment("Caps of user %q are not managed by this provider: %s", user.ID, strings.Join(caps, ";"))
+	}
+
+	body := g.resource("radosgw_user", userName, user.ID)
+	if tenant != "" {
+		body.SetAttributeValue("tenant", cty.StringVal(tenant))
+	}
+	body.SetAttributeValue("user_id", cty.StringVal(userID))
+	body.SetAttributeValue("display_name", cty.StringVal(user.DisplayName))
+	if isSuspended(user) {
+		body.SetAttributeValue("suspended", cty.True)
+	}
+
+	// subuser names by subuser, to reference them from keys
+	subuserNames := make(map[string]string, len(user.Subusers))
+	for _, subuser := range user.Subusers {
+		subuser = mapSubuser(user.ID, subuser)
+		id := userRef{Tenant: tenant, UserID: userID, Subuser: subuser.Name}
+
+		name := g.name(userName + "_" + subuser.Name)
+		subuserNames[subuser.Name] = name
+
+		body := g.resource("radosgw_subuser", name, id.String())
+		if tenant != "" {
+			body.SetAttributeTraversal("tenant", reference("radosgw_user", userName, "tenant"))
+		}
+		body.SetAttributeTraversal("user_id", reference("radosgw_user", userName, "user_id"))
+		body.SetAttributeValue("subuser", cty.StringVal(subuser.Name))
+		body.SetAttributeValue("access", cty.StringVal(string(subuser.Access)))
+	}
+
+	keys := append([]admin.UserKeySpec(nil), user.Keys...)
+	sort.Slice(keys, func(i, j int) bool { return keys[i].AccessKey < keys[j].AccessKey })
+	for _, key := range keys {
+		if key.AccessKey == g.ownAccessKey {
+			g.file.Body().AppendNewline()
+			g.comment("Key %q of %q is skipped, as it is used to generate this configuration.", key.AccessKey, key.User)
+			continue
+		}
+
+		owner := parseUserRef(key.User)
+		name := g.name(key.User + "_key")
+
+		body := g.resource("radosgw_key", name, key.AccessKey)
+		if tenant != "" {
+			body.SetAttributeTraversal("tenant", reference("radosgw_user", userName, "tenant"))
+		}
+		body.SetAttributeTraversal("user", reference("radosgw_user", userName, "user_id"))
+		if owner.Subuser != "" {
+			if subuserName, ok := subuserNames[owner.Subuser]; ok {
+				body.SetAttributeTraversal("subuser", reference("radosgw_subuser", subuserName, "subuser"))
+			} else {
+				body.SetAttributeValue("subuser", cty.StringVal(owner.Subuser))
+			}
+		}
+	}
+
+	for _, key := range user.SwiftKeys {
+		g.file.Body().AppendNewline()
+		g.comment("Swift key of %q is skipped, radosgw_key only supports S3 keys.", key.User)
+	}
+}
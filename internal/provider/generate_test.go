@@ -0,0 +1,240 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+
+	"github.com/spreadshirt/terraform-provider-radosgw/internal/rgwtest"
+)
+
+// testGenerateUsers creates users, subusers and keys to generate
+// configuration for.
+func testGenerateUsers(t *testing.T, server *rgwtest.Server) {
+	t.Helper()
+
+	ctx := context.Background()
+	api := server.API()
+	suspended := 1
+	if _, err := api.CreateUser(ctx, admin.User{ID: "demo", DisplayName: "Demo user", Suspended: &suspended}); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.CreateSubuser(ctx, admin.User{ID: "demo"}, admin.SubuserSpec{Name: "readonly", Access: admin.SubuserAccessRead}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.CreateKey(ctx, admin.UserKeySpec{UID: "demo", SubUser: "readonly", KeyType: "s3", AccessKey: "READONLYKEY", SecretKey: "readonly-secret"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.AddUserCap(ctx, "demo", "buckets=read"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.CreateUser(ctx, admin.User{ID: "other", Tenant: "acme", DisplayName: "Tenanted user"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.CreateSubuser(ctx, admin.User{ID: "acme$other"}, admin.SubuserSpec{Name: "app", Access: admin.SubuserAccessFull}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.CreateKey(ctx, admin.UserKeySpec{UID: "acme$other", SubUser: "app", KeyType: "s3", AccessKey: "APPKEY", SecretKey: "app-secret"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenerateConfig(t *testing.T) {
+	server, _ := testAccServer(t)
+	testGenerateUsers(t, server)
+
+	// keys generated by radosgw are random, so remove them
+	ctx := context.Background()
+	for _, uid := range []string{"demo", "acme$other", rgwtest.AdminUserID} {
+		user, err := server.API().GetUser(ctx, admin.User{ID: uid})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, key := range user.Keys {
+			if key.AccessKey == "READONLYKEY" || key.AccessKey == "APPKEY" || uid == rgwtest.AdminUserID {
+				continue
+			}
+			if err := server.API().RemoveKey(ctx, admin.UserKeySpec{UID: uid, AccessKey: key.AccessKey, KeyType: "s3"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	file, err := generateConfig(ctx, server.API(), server.AccessKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# Generated by terraform-provider-radosgw generate.  Run "terraform plan" to import the objects below.
+
+import {
+  to = radosgw_user.acme_other
+  id = "acme$other"
+}
+
+resource "radosgw_user" "acme_other" {
+  tenant       = "acme"
+  user_id      = "other"
+  display_name = "Tenanted user"
+}
+
+import {
+  to = radosgw_subuser.acme_other_app
+  id = "acme$other:app"
+}
+
+resource "radosgw_subuser" "acme_other_app" {
+  tenant  = radosgw_user.acme_other.tenant
+  user_id = radosgw_user.acme_other.user_id
+  subuser = "app"
+  access  = "full"
+}
+
+import {
+  to = radosgw_key.acme_other_app_key
+  id = "APPKEY"
+}
+
+resource "radosgw_key" "acme_other_app_key" {
+  tenant  = radosgw_user.acme_other.tenant
+  user    = radosgw_user.acme_other.user_id
+  subuser = radosgw_subuser.acme_other_app.subuser
+}
+
+# Caps of user "admin" are not managed by this provider: buckets=*;info=read;metadata=*;roles=*;usage=*;user-policy=*;users=*
+
+import {
+  to = radosgw_user.admin
+  id = "admin"
+}
+
+resource "radosgw_user" "admin" {
+  user_id      = "admin"
+  display_name = "Admin"
+}
+
+# Key "` + server.AccessKey + `" of "admin" is skipped, as it is used to generate this configuration.
+
+# Caps of user "demo" are not managed by this provider: buckets=read
+
+import {
+  to = radosgw_user.demo
+  id = "demo"
+}
+
+resource "radosgw_user" "demo" {
+  user_id      = "demo"
+  display_name = "Demo user"
+  suspended    = true
+}
+
+import {
+  to = radosgw_subuser.demo_readonly
+  id = "demo:readonly"
+}
+
+resource "radosgw_subuser" "demo_readonly" {
+  user_id = radosgw_user.demo.user_id
+  subuser = "readonly"
+  access  = "read"
+}
+
+import {
+  to = radosgw_key.demo_readonly_key
+  id = "READONLYKEY"
+}
+
+resource "radosgw_key" "demo_readonly_key" {
+  user    = radosgw_user.demo.user_id
+  subuser = radosgw_subuser.demo_readonly.subuser
+}
+`
+	if got := string(file.Bytes()); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestAccGenerate(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+	testGenerateUsers(t, server)
+
+	file, err := generateConfig(context.Background(), server.API(), server.AccessKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := providerConfig + string(file.Bytes())
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_5_0),
+		},
+		Steps: []resource.TestStep{
+			// the imported objects must match the generated configuration
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_user.demo", "suspended", "true"),
+					resource.TestCheckResourceAttr("radosgw_subuser.acme_other_app", "tenant", "acme"),
+					resource.TestCheckResourceAttr("radosgw_key.acme_other_app_key", "tenant", "acme"),
+					resource.TestCheckResourceAttr("radosgw_key.acme_other_app_key", "secret_key", "app-secret"),
+					resource.TestCheckResourceAttr("radosgw_key.demo_readonly_key", "secret_key", "readonly-secret"),
+				),
+			},
+			{
+				Config:   config,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestGenerateFile(t *testing.T) {
+	server, _ := testAccServer(t)
+	testGenerateUsers(t, server)
+
+	opts := GenerateOptions{Endpoint: server.URL, AccessKeyID: server.AccessKey, SecretAccessKey: server.SecretKey}
+	path := filepath.Join(t.TempDir(), "radosgw_import.tf")
+	if err := os.WriteFile(path, []byte("# previous\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// a failure partway through leaves the existing file as it is
+	restore := server.Fail(func(r *http.Request) bool {
+		return r.URL.Query().Get("uid") == "demo"
+	}, http.StatusInternalServerError, "UnknownError")
+	if err := GenerateFile(context.Background(), path, opts); err == nil {
+		t.Fatal("expected an error")
+	}
+	restore()
+	if got, err := os.ReadFile(path); err != nil || string(got) != "# previous\n" {
+		t.Errorf("file changed after failure: %q, %v", got, err)
+	}
+
+	if err := GenerateFile(context.Background(), path, opts); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), `resource "radosgw_key" "demo_readonly_key"`) {
+		t.Errorf("file misses the generated configuration:\n%s", got)
+	}
+
+	// no temporary files are left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected only %s, got %d files", filepath.Base(path), len(entries))
+	}
+}
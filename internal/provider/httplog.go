@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// httpLogSubsystem is the tflog subsystem of the radosgw request logs.  Its
+// level can be set separately with TF_LOG_PROVIDER_RADOSGW_HTTP.
+const httpLogSubsystem = "http"
+
+// httpLogLevelEnvs are the environment variables setting the level of the
+// radosgw request logs, most specific first.
+var httpLogLevelEnvs = []string{"TF_LOG_PROVIDER_RADOSGW_HTTP", "TF_LOG_PROVIDER", "TF_LOG"}
+
+// redacted replaces secrets in logs.
+const redacted = "<redacted>"
+
+// secretQueryParams are query parameters with secrets.
+var secretQueryParams = []string{"secret-key", "secret_key"}
+
+// secretHeaders are request headers with secrets.
+var secretHeaders = []string{"Authorization", "X-Amz-Security-Token", "X-Amz-Mfa"}
+
+// secretJSONPattern matches secret keys in response bodies.
+var secretJSONPattern = regexp.MustCompile(`("secret_key"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// secretXMLPattern matches secret keys and session tokens in XML response
+// bodies of the IAM and STS APIs.
+var secretXMLPattern = regexp.MustCompile(`(<(?:SecretAccessKey|SessionToken)>)[^<]*(</(?:SecretAccessKey|SessionToken)>)`)
+
+// urlPasswordPattern matches the passwords of URLs in response bodies, such
+// as push endpoints of topics, plain and URL encoded.
+var urlPasswordPattern = regexp.MustCompile(`(://[^:/@\s"'<>&]*:|%3A%2F%2F[^%@\s"'<>&]*%3A)[^@\s"'<>&]+?(@|%40)`)
+
+// loggingHTTPClient logs all requests to radosgw and their responses at
+// TRACE level, with secrets redacted.
+type loggingHTTPClient struct {
+	client admin.HTTPClient
+}
+
+var _ admin.HTTPClient = &loggingHTTPClient{}
+
+// Do implements admin.HTTPClient.
+func (c *loggingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	// tflog does not expose its level, and buffering the response body is
+	// wasted on requests that are not logged
+	if !httpTraceEnabled() {
+		return c.client.Do(req)
+	}
+
+	ctx := tflog.NewSubsystem(req.Context(), httpLogSubsystem, tflog.WithLevelFromEnv("TF_LOG_PROVIDER_RADOSGW_HTTP"))
+	ctx = tflog.SubsystemSetField(ctx, httpLogSubsystem, "http_method", req.Method)
+	ctx = tflog.SubsystemSetField(ctx, httpLogSubsystem, "http_path", req.URL.Path)
+	ctx = tflog.SubsystemSetField(ctx, httpLogSubsystem, "http_query", redactQuery(req.URL.Query()))
+
+	tflog.SubsystemTrace(ctx, httpLogSubsystem, "sending radosgw request", map[string]any{
+		"http_request_headers": redactHeaders(req.Header),
+	})
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	latency := time.Since(start)
+	if err != nil {
+		tflog.SubsystemTrace(ctx, httpLogSubsystem, "radosgw request failed", map[string]any{
+			"http_latency": latency.String(),
+			"error":        err.Error(),
+		})
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	fields := map[string]any{
+		"http_status":        resp.StatusCode,
+		"http_latency":       latency.String(),
+		"http_response_body": redactBody(body),
+	}
+	if code := rgwErrorCode(resp.StatusCode, body); code != "" {
+		fields["rgw_error_code"] = code
+	}
+	tflog.SubsystemTrace(ctx, httpLogSubsystem, "received radosgw response", fields)
+
+	return resp, nil
+}
+
+// httpTraceEnabled reports whether the radosgw request logs are written, which
+// they are only at TRACE level.  Terraform treats TF_LOG=JSON as TRACE.
+func httpTraceEnabled() bool {
+	for _, env := range httpLogLevelEnvs {
+		if level := os.Getenv(env); level != "" {
+			return strings.EqualFold(level, "TRACE") || strings.EqualFold(level, "JSON")
+		}
+	}
+	return false
+}
+
+// redactQuery returns the encoded query with secret parameters redacted.
+func redactQuery(query url.Values) string {
+	for _, param := range secretQueryParams {
+		if query.Has(param) {
+			query.Set(param, redacted)
+		}
+	}
+	// keep the placeholder readable
+	return strings.ReplaceAll(query.Encode(), url.QueryEscape(redacted), redacted)
+}
+
+// redactHeaders returns the headers with secret headers redacted.
+func redactHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		headers[name] = strings.Join(values, ", ")
+	}
+	for _, name := range secretHeaders {
+		if _, ok := headers[name]; ok {
+			headers[name] = redacted
+		}
+	}
+	return headers
+}
+
+// redactBody returns the response body with secret keys, session tokens and
+// passwords in URLs redacted.
+func redactBody(body []byte) string {
+	s := secretJSONPattern.ReplaceAllString(string(body), `${1}"`+redacted+`"`)
+	s = secretXMLPattern.ReplaceAllString(s, "${1}"+redacted+"${2}")
+	return urlPasswordPattern.ReplaceAllString(s, "${1}"+redacted+"${2}")
+}
+
+// redactURL returns rawURL with its password redacted.
+func redactURL(rawURL string) string {
+	return urlPasswordPattern.ReplaceAllString(rawURL, "${1}"+redacted+"${2}")
+}
+
+// rgwErrorCode returns the error code of an error response by radosgw.
+func rgwErrorCode(status int, body []byte) string {
+	if status < http.StatusMultipleChoices {
+		return ""
+	}
+	var rgwError struct {
+		Code string `json:"Code"`
+	}
+	if err := json.Unmarshal(body, &rgwError); err != nil {
+		return ""
+	}
+	return rgwError.Code
+}
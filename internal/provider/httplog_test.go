@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestRedactQuery(t *testing.T) {
+	query := url.Values{
+		"uid":        {"demo"},
+		"access-key": {"AK"},
+		"secret-key": {"very-secret"},
+	}
+	want := "access-key=AK&secret-key=<redacted>&uid=demo"
+	if got := redactQuery(query); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{
+		"Authorization": {"AWS4-HMAC-SHA256 Credential=AK/..., Signature=abc"},
+		"X-Amz-Date":    {"20240101T000000Z"},
+	}
+	got := redactHeaders(header)
+	if got["Authorization"] != redacted {
+		t.Errorf("Authorization header was not redacted: %q", got["Authorization"])
+	}
+	if got["X-Amz-Date"] != "20240101T000000Z" {
+		t.Errorf("X-Amz-Date header was modified: %q", got["X-Amz-Date"])
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	body := `{"user_id":"demo","keys":[{"user":"demo","access_key":"AK","secret_key":"very\"secret"}],"swift_keys":[{"user":"demo:swift","secret_key" : "other"}]}`
+	want := `{"user_id":"demo","keys":[{"user":"demo","access_key":"AK","secret_key":"<redacted>"}],"swift_keys":[{"user":"demo:swift","secret_key" : "<redacted>"}]}`
+	if got := redactBody([]byte(body)); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestRedactBodyXML(t *testing.T) {
+	body := `<AssumeRoleResponse><AssumeRoleResult><Credentials><AccessKeyId>AK</AccessKeyId><SecretAccessKey>very/secret+key</SecretAccessKey><SessionToken>token==</SessionToken></Credentials></AssumeRoleResult></AssumeRoleResponse>`
+	want := `<AssumeRoleResponse><AssumeRoleResult><Credentials><AccessKeyId>AK</AccessKeyId><SecretAccessKey><redacted></SecretAccessKey><SessionToken><redacted></SessionToken></Credentials></AssumeRoleResult></AssumeRoleResponse>`
+	if got := redactBody([]byte(body)); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestRedactBodyURLPasswords(t *testing.T) {
+	body := `<value>{"EndpointAddress":"amqp://user:pa:ss@broker:5672","EndpointArgs":"push-endpoint=amqp%3A%2F%2Fuser%3Apa%3Ass%40broker%3A5672&verify-ssl=true"}</value><other>https://broker/path</other>`
+	want := `<value>{"EndpointAddress":"amqp://user:<redacted>@broker:5672","EndpointArgs":"push-endpoint=amqp%3A%2F%2Fuser%3A<redacted>%40broker%3A5672&verify-ssl=true"}</value><other>https://broker/path</other>`
+	if got := redactBody([]byte(body)); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestRGWErrorCode(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   string
+	}{
+		{http.StatusOK, `{"Code":"ignored"}`, ""},
+		{http.StatusNotFound, `{"Code":"NoSuchUser","RequestId":"tx1","HostId":"h"}`, "NoSuchUser"},
+		{http.StatusInternalServerError, `<html>oops</html>`, ""},
+	}
+	for _, tt := range tests {
+		if got := rgwErrorCode(tt.status, []byte(tt.body)); got != tt.want {
+			t.Errorf("rgwErrorCode(%d, %s) = %q, want %q", tt.status, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPTraceEnabled(t *testing.T) {
+	tests := []struct {
+		env  map[string]string
+		want bool
+	}{
+		{map[string]string{}, false},
+		{map[string]string{"TF_LOG": "DEBUG"}, false},
+		{map[string]string{"TF_LOG": "trace"}, true},
+		{map[string]string{"TF_LOG": "JSON"}, true},
+		{map[string]string{"TF_LOG": "TRACE", "TF_LOG_PROVIDER": "INFO"}, false},
+		{map[string]string{"TF_LOG": "TRACE", "TF_LOG_PROVIDER_RADOSGW_HTTP": "OFF"}, false},
+		{map[string]string{"TF_LOG": "INFO", "TF_LOG_PROVIDER_RADOSGW_HTTP": "TRACE"}, true},
+	}
+	for _, tt := range tests {
+		for _, env := range httpLogLevelEnvs {
+			t.Setenv(env, tt.env[env])
+		}
+		if got := httpTraceEnabled(); got != tt.want {
+			t.Errorf("httpTraceEnabled() with %v = %v, want %v", tt.env, got, tt.want)
+		}
+	}
+}
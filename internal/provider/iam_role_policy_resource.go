@@ -0,0 +1,278 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &iamRolePolicyResource{}
+	_ resource.ResourceWithConfigure   = &iamRolePolicyResource{}
+	_ resource.ResourceWithImportState = &iamRolePolicyResource{}
+)
+
+// NewIAMRolePolicyResource is a helper function to simplify the provider implementation.
+func NewIAMRolePolicyResource() resource.Resource {
+	return &iamRolePolicyResource{}
+}
+
+// iamRolePolicyResource is the resource implementation.
+type iamRolePolicyResource struct {
+	iam iamiface.IAMAPI
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *iamRolePolicyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
+		return
+	}
+
+	r.iam = iam.New(data.awsSession)
+}
+
+// Metadata returns the resource type name.
+func (r *iamRolePolicyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_iam_role_policy"
+}
+
+// Schema defines the schema for the resource.
+func (r *iamRolePolicyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages an inline permission policy of an IAM role, which grants the sessions of the role access to buckets, topics and roles.  " +
+			"Like the role, the policy is managed in the tenant of the provider credentials.",
+		Attributes: map[string]schema.Attribute{
+			"role": schema.StringAttribute{
+				MarkdownDescription: "Name of the role, for example from a radosgw_iam_role resource.",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, 64),
+					stringvalidator.RegexMatches(roleNamePattern, `must only contain letters, digits and the characters "_+=,.@-"`),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"tenant": schema.StringAttribute{
+				MarkdownDescription: "Tenant of the role, which must be the tenant of the provider credentials, if any.  Defaults to the tenant of the provider credentials.",
+				Optional:            true,
+				Computed:            true,
+				Validators:          tenantValidators(),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the policy, unique among the policies of the role.",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, 128),
+					stringvalidator.RegexMatches(roleNamePattern, `must only contain letters, digits and the characters "_+=,.@-"`),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"policy": schema.StringAttribute{
+				MarkdownDescription: "JSON permission policy, whose statements have no principals.  Differences in formatting and ordering are not reported as changes.",
+				Required:            true,
+				CustomType:          policyDocumentType{},
+				Validators: []validator.String{
+					policyValidator{rules: rolePolicyRules},
+				},
+			},
+		},
+	}
+}
+
+type iamRolePolicyResourceModel struct {
+	Role   types.String   `tfsdk:"role"`
+	Tenant types.String   `tfsdk:"tenant"`
+	Name   types.String   `tfsdk:"name"`
+	Policy policyDocument `tfsdk:"policy"`
+}
+
+// errorTarget describes the policy for explaining IAM API errors.
+func (m iamRolePolicyResourceModel) errorTarget() rgwErrorTarget {
+	return rgwErrorTarget{
+		resourceType: "radosgw_iam_role_policy",
+		importID:     joinUserID(m.Tenant.ValueString(), m.Role.ValueString()) + ":" + m.Name.ValueString(),
+		rolePath:     path.Root("role"),
+		caps:         "roles=*",
+		policyPath:   path.Root("policy"),
+	}
+}
+
+// Read implements resource.Resource.
+func (r *iamRolePolicyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state iamRolePolicyResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tenant, err := r.roleTenant(ctx, state.Role.ValueString())
+	if err == nil {
+		// the tenant of imported policies is only known from the import ID
+		if !checkRoleTenant(&resp.Diagnostics, state.Tenant, state.Role.ValueString(), tenant) {
+			return
+		}
+		state.Tenant = optionalString(tenant)
+	}
+
+	var out *iam.GetRolePolicyOutput
+	if err == nil {
+		out, err = r.iam.GetRolePolicyWithContext(ctx, &iam.GetRolePolicyInput{
+			RoleName:   aws.String(state.Role.ValueString()),
+			PolicyName: aws.String(state.Name.ValueString()),
+		})
+	}
+	if awsErrorCode(err) == iam.ErrCodeNoSuchEntityException {
+		tflog.Warn(ctx, "role policy removed outside of Terraform", map[string]any{
+			"role":   state.Role.ValueString(),
+			"policy": state.Name.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error fetching role policy",
+			fmt.Sprintf("Could not fetch policy %q of role %q", state.Name.ValueString(), state.Role.ValueString()), err)
+		return
+	}
+
+	state.Policy = newPolicyDocument(decodeIAMPolicyDocument(aws.StringValue(out.PolicyDocument)))
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// roleTenant returns the tenant the role is managed in.
+func (r *iamRolePolicyResource) roleTenant(ctx context.Context, role string) (string, error) {
+	out, err := r.iam.GetRoleWithContext(ctx, &iam.GetRoleInput{
+		RoleName: aws.String(role),
+	})
+	if err != nil {
+		return "", err
+	}
+	return roleARNTenant(aws.StringValue(out.Role.Arn)), nil
+}
+
+// ImportState implements resource.ResourceWithImportState.  Policies are
+// imported as "<role>:<name>", with the role optionally prefixed with its
+// tenant as "<tenant>$<role>:<name>".
+func (r *iamRolePolicyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	id, name, found := strings.Cut(req.ID, ":")
+	tenant, role := splitUserID(id)
+	if !found || !roleNamePattern.MatchString(role) || strings.Contains(tenant, "$") || !roleNamePattern.MatchString(name) {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Could not parse import ID %q.\n\nThe import ID must be of the form \"<role>:<name>\" or \"<tenant>$<role>:<name>\".", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("role"), role)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("tenant"), optionalString(tenant))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), name)...)
+}
+
+// Create implements resource.Resource.
+func (r *iamRolePolicyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan iamRolePolicyResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tenant, err := r.roleTenant(ctx, plan.Role.ValueString())
+	if err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error setting role policy",
+			fmt.Sprintf("Could not fetch role %q", plan.Role.ValueString()), err)
+		return
+	}
+	if !checkRoleTenant(&resp.Diagnostics, plan.Tenant, plan.Role.ValueString(), tenant) {
+		return
+	}
+	plan.Tenant = optionalString(tenant)
+
+	if err := r.put(ctx, plan); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error setting role policy",
+			fmt.Sprintf("Could not set policy %q of role %q", plan.Name.ValueString(), plan.Role.ValueString()), err)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *iamRolePolicyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan iamRolePolicyResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.put(ctx, plan); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error updating role policy",
+			fmt.Sprintf("Could not update policy %q of role %q", plan.Name.ValueString(), plan.Role.ValueString()), err)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// put sets the policy of the role to that of plan, replacing a policy with
+// the same name.
+func (r *iamRolePolicyResource) put(ctx context.Context, plan iamRolePolicyResourceModel) error {
+	_, err := r.iam.PutRolePolicyWithContext(ctx, &iam.PutRolePolicyInput{
+		RoleName:       aws.String(plan.Role.ValueString()),
+		PolicyName:     aws.String(plan.Name.ValueString()),
+		PolicyDocument: aws.String(plan.Policy.ValueString()),
+	})
+	return err
+}
+
+// Delete implements resource.Resource.
+func (r *iamRolePolicyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state iamRolePolicyResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.iam.DeleteRolePolicyWithContext(ctx, &iam.DeleteRolePolicyInput{
+		RoleName:   aws.String(state.Role.ValueString()),
+		PolicyName: aws.String(state.Name.ValueString()),
+	})
+	if awsErrorCode(err) == iam.ErrCodeNoSuchEntityException {
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error removing role policy",
+			fmt.Sprintf("Could not remove policy %q of role %q", state.Name.ValueString(), state.Role.ValueString()), err)
+		return
+	}
+}
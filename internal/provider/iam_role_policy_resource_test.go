@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/spreadshirt/terraform-provider-radosgw/internal/rgwtest"
+)
+
+func TestAccIAMRolePolicyResource(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + testAccIAMRolePolicyResourceConfig("s3:GetObject"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_iam_role_policy.test", "role", "web"),
+					resource.TestCheckResourceAttr("radosgw_iam_role_policy.test", "name", "read-assets"),
+					testAccCheckRolePolicy(server, "web", "read-assets", regexp.MustCompile(`"s3:GetObject"`)),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "radosgw_iam_role_policy.test",
+				ImportState:                          true,
+				ImportStateId:                        "web:read-assets",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "name",
+			},
+			// Update and Read testing
+			{
+				Config: providerConfig + testAccIAMRolePolicyResourceConfig("s3:PutObject"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckRolePolicy(server, "web", "read-assets", regexp.MustCompile(`"s3:PutObject"`)),
+				),
+			},
+			// Removed outside of Terraform
+			{
+				PreConfig: func() {
+					server.DeleteRole("", "web")
+				},
+				Config:             providerConfig + testAccIAMRolePolicyResourceConfig("s3:PutObject"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccIAMRolePolicyResource_tenant(t *testing.T) {
+	server, _ := testAccServer(t)
+	providerConfig := testAccTenantProviderConfig(t, server, "acme")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + testAccIAMRolePolicyResourceConfig("s3:GetObject"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_iam_role_policy.test", "tenant", "acme"),
+				),
+			},
+			{
+				ResourceName:                         "radosgw_iam_role_policy.test",
+				ImportState:                          true,
+				ImportStateId:                        "acme$web:read-assets",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "name",
+			},
+			{
+				ResourceName:  "radosgw_iam_role_policy.test",
+				ImportState:   true,
+				ImportStateId: "other$web:read-assets",
+				ExpectError:   regexp.MustCompile(`Role of another tenant`),
+			},
+		},
+	})
+}
+
+func TestAccIAMRolePolicyResource_tenantMismatch(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + strings.Replace(testAccIAMRolePolicyResourceConfig("s3:GetObject"),
+					"  role = radosgw_iam_role.web.name\n  name = \"read-assets\"\n",
+					"  role   = radosgw_iam_role.web.name\n  tenant = \"acme\"\n  name   = \"read-assets\"\n", 1),
+				ExpectError: regexp.MustCompile(`Role of another tenant`),
+			},
+		},
+	})
+
+	if _, policies, ok := server.Role("", "web"); ok && len(policies) > 0 {
+		t.Errorf("got policies %v of role web, want none", policies)
+	}
+}
+
+func TestAccIAMRolePolicyResource_validation(t *testing.T) {
+	_, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "radosgw_iam_role_policy" "test" {
+  role = "web"
+  name = "read-assets"
+
+  policy = jsonencode({
+    Version   = "2012-10-17"
+    Statement = [{ Effect = "Allow", Action = "s3:GetObject", Resource = "arn:aws:s3:::assets/*", Principal = { AWS = "arn:aws:iam:::user/demo" } }]
+  })
+}
+`,
+				ExpectError: regexp.MustCompile(`Principal`),
+			},
+			{
+				Config: providerConfig + `
+resource "radosgw_iam_role_policy" "test" {
+  role = "web"
+  name = "read-assets"
+
+  policy = jsonencode({
+    Version   = "2012-10-17"
+    Statement = [{ Effect = "Allow", Action = "s3:GetObject", Resource = "arn:aws:s3:::assets/*" }]
+  })
+}
+`,
+				ExpectError: regexp.MustCompile(`The role does not exist`),
+			},
+		},
+	})
+}
+
+func testAccIAMRolePolicyResourceConfig(action string) string {
+	return fmt.Sprintf(`
+resource "radosgw_iam_role" "web" {
+  name = "web"
+
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [{
+      Effect    = "Allow"
+      Action    = "sts:AssumeRole"
+      Principal = { AWS = "arn:aws:iam:::user/demo" }
+    }]
+  })
+}
+
+resource "radosgw_iam_role_policy" "test" {
+  role = radosgw_iam_role.web.name
+  name = "read-assets"
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [{
+      Effect   = "Allow"
+      Action   = %q
+      Resource = "arn:aws:s3:::assets/*"
+    }]
+  })
+}
+`, action)
+}
+
+// testAccCheckRolePolicy checks a permission policy of a role on the fake
+// radosgw.
+func testAccCheckRolePolicy(server *rgwtest.Server, role, name string, pattern *regexp.Regexp) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		_, policies, ok := server.Role("", role)
+		if !ok {
+			return fmt.Errorf("role %q does not exist", role)
+		}
+		policy, ok := policies[name]
+		if !ok {
+			return fmt.Errorf("role %q has no policy %q", role, name)
+		}
+		if !pattern.MatchString(policy) {
+			return fmt.Errorf("policy %q of role %q does not match %s: %s", name, role, pattern, policy)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,404 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &iamRoleResource{}
+	_ resource.ResourceWithConfigure   = &iamRoleResource{}
+	_ resource.ResourceWithImportState = &iamRoleResource{}
+	_ resource.ResourceWithModifyPlan  = &iamRoleResource{}
+)
+
+var (
+	// roleNamePattern matches the names of roles and of their permission
+	// policies, which radosgw checks like IAM.
+	roleNamePattern = regexp.MustCompile(`^[\w+=,.@-]+$`)
+
+	// rolePathPattern matches role paths, which start and end with "/".
+	rolePathPattern = regexp.MustCompile(`^/([\x21-\x7E]*/)?$`)
+)
+
+// NewIAMRoleResource is a helper function to simplify the provider implementation.
+func NewIAMRoleResource() resource.Resource {
+	return &iamRoleResource{}
+}
+
+// iamRoleResource is the resource implementation.
+type iamRoleResource struct {
+	iam iamiface.IAMAPI
+
+	// client and accessKeyID look up the tenant of the provider credentials.
+	client      adminClient
+	accessKeyID string
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *iamRoleResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
+		return
+	}
+
+	r.iam = iam.New(data.awsSession)
+	r.client = data.client
+	r.accessKeyID = data.accessKeyID
+}
+
+// Metadata returns the resource type name.
+func (r *iamRoleResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_iam_role"
+}
+
+// Schema defines the schema for the resource.
+func (r *iamRoleResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages an IAM role through the IAM API of radosgw, which users and web identities can assume with STS `AssumeRole` and `AssumeRoleWithWebIdentity`.  " +
+			"Roles belong to the tenant of the provider credentials, whose user needs the `roles=*` cap.",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the role.",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, 64),
+					stringvalidator.RegexMatches(roleNamePattern, `must only contain letters, digits and the characters "_+=,.@-"`),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"path": schema.StringAttribute{
+				MarkdownDescription: "Path of the role, which is part of its ARN.  Defaults to `/`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("/"),
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, 512),
+					stringvalidator.RegexMatches(rolePathPattern, `must start and end with "/"`),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"tenant": schema.StringAttribute{
+				MarkdownDescription: "Tenant of the role, which must be the tenant of the provider credentials, if any.  It is checked when planning if their user has the `users=read` cap, and otherwise when creating the role.  Defaults to the tenant of the provider credentials.",
+				Optional:            true,
+				Computed:            true,
+				Validators:          tenantValidators(),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"assume_role_policy": schema.StringAttribute{
+				MarkdownDescription: "JSON trust policy of the role, which allows principals to assume it.  Users are given as `arn:aws:iam::<tenant>:user/<user>` and OpenID Connect providers of web identities as `Federated` principals `arn:aws:iam::<tenant>:oidc-provider/<url>`.  Differences in formatting and ordering are not reported as changes.",
+				Required:            true,
+				CustomType:          policyDocumentType{},
+				Validators: []validator.String{
+					policyValidator{rules: trustPolicyRules},
+				},
+			},
+			"max_session_duration": schema.Int64Attribute{
+				MarkdownDescription: "Maximum duration in seconds of sessions of the role, between 3600 and 43200.  Defaults to 3600.",
+				Optional:            true,
+				Computed:            true,
+				Default:             int64default.StaticInt64(3600),
+				Validators: []validator.Int64{
+					int64validator.Between(3600, 43200),
+				},
+			},
+			"arn": schema.StringAttribute{
+				MarkdownDescription: "ARN of the role, `arn:aws:iam::<tenant>:role<path><name>`.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"role_id": schema.StringAttribute{
+				MarkdownDescription: "Unique ID of the role.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"create_date": schema.StringAttribute{
+				MarkdownDescription: "Time the role was created, as RFC 3339 timestamp.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+type iamRoleResourceModel struct {
+	Name               types.String   `tfsdk:"name"`
+	Path               types.String   `tfsdk:"path"`
+	Tenant             types.String   `tfsdk:"tenant"`
+	AssumeRolePolicy   policyDocument `tfsdk:"assume_role_policy"`
+	MaxSessionDuration types.Int64    `tfsdk:"max_session_duration"`
+	ARN                types.String   `tfsdk:"arn"`
+	RoleID             types.String   `tfsdk:"role_id"`
+	CreateDate         types.String   `tfsdk:"create_date"`
+}
+
+// errorTarget describes the role for explaining IAM API errors.
+func (m iamRoleResourceModel) errorTarget() rgwErrorTarget {
+	return rgwErrorTarget{
+		resourceType: "radosgw_iam_role",
+		importID:     joinUserID(m.Tenant.ValueString(), m.Name.ValueString()),
+		rolePath:     path.Root("name"),
+		caps:         "roles=*",
+		policyPath:   path.Root("assume_role_policy"),
+	}
+}
+
+// setRole sets the attributes of m from a role returned by radosgw.
+func (m *iamRoleResourceModel) setRole(role *iam.Role) {
+	m.Name = types.StringValue(aws.StringValue(role.RoleName))
+	m.Path = types.StringValue(aws.StringValue(role.Path))
+	m.Tenant = optionalString(roleARNTenant(aws.StringValue(role.Arn)))
+	m.AssumeRolePolicy = newPolicyDocument(decodeIAMPolicyDocument(aws.StringValue(role.AssumeRolePolicyDocument)))
+	m.MaxSessionDuration = types.Int64Value(aws.Int64Value(role.MaxSessionDuration))
+	m.ARN = types.StringValue(aws.StringValue(role.Arn))
+	m.RoleID = types.StringValue(aws.StringValue(role.RoleId))
+	m.CreateDate = types.StringNull()
+	if role.CreateDate != nil {
+		m.CreateDate = types.StringValue(role.CreateDate.UTC().Format(time.RFC3339))
+	}
+}
+
+// roleARNTenant returns the tenant of a role ARN,
+// "arn:aws:iam::<tenant>:role<path><name>".
+func roleARNTenant(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 {
+		return ""
+	}
+	return parts[4]
+}
+
+// checkRoleTenant adds an error to diags and returns false if the configured
+// tenant, if known, is not the tenant the role is managed in.
+func checkRoleTenant(diags *diag.Diagnostics, configured types.String, role, tenant string) bool {
+	if configured.IsNull() || configured.IsUnknown() || configured.ValueString() == tenant {
+		return true
+	}
+
+	diags.AddAttributeError(path.Root("tenant"), "Role of another tenant",
+		fmt.Sprintf("Role %q is managed in tenant %q of the provider credentials, not in tenant %q.  radosgw only manages roles in the tenant of the caller, so configure the provider with credentials of a user of tenant %q.",
+			role, tenant, configured.ValueString(), configured.ValueString()))
+	return false
+}
+
+// decodeIAMPolicyDocument returns a policy document of an IAM API response.
+// radosgw returns them as is, AWS URL-encoded.
+func decodeIAMPolicyDocument(document string) string {
+	if strings.HasPrefix(strings.TrimSpace(document), "{") {
+		return document
+	}
+	if decoded, err := url.QueryUnescape(document); err == nil {
+		return decoded
+	}
+	return document
+}
+
+// ModifyPlan implements resource.ResourceWithModifyPlan.  It checks the
+// configured tenant of new roles against the tenant of the provider
+// credentials, as radosgw creates roles in the tenant of the caller.
+func (r *iamRoleResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || !req.State.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var plan iamRoleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || plan.Tenant.IsNull() || plan.Tenant.IsUnknown() {
+		return
+	}
+
+	tenant, err := credentialsTenant(ctx, r.client, r.accessKeyID)
+	if err != nil {
+		// without the users cap, Create checks the tenant of the new role
+		tflog.Debug(ctx, "could not look up the tenant of the provider credentials", map[string]any{"error": err.Error()})
+		return
+	}
+
+	checkRoleTenant(&resp.Diagnostics, plan.Tenant, plan.Name.ValueString(), tenant)
+}
+
+// Read implements resource.Resource.
+func (r *iamRoleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state iamRoleResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	out, err := r.iam.GetRoleWithContext(ctx, &iam.GetRoleInput{
+		RoleName: aws.String(state.Name.ValueString()),
+	})
+	if awsErrorCode(err) == iam.ErrCodeNoSuchEntityException {
+		tflog.Warn(ctx, "role removed outside of Terraform", map[string]any{"role": state.Name.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error fetching role",
+			fmt.Sprintf("Could not fetch role %q", state.Name.ValueString()), err)
+		return
+	}
+
+	// the tenant of imported roles is only known from the import ID
+	if !checkRoleTenant(&resp.Diagnostics, state.Tenant, state.Name.ValueString(), roleARNTenant(aws.StringValue(out.Role.Arn))) {
+		return
+	}
+
+	state.setRole(out.Role)
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// ImportState implements resource.ResourceWithImportState.  Roles are
+// imported by their name, optionally prefixed with their tenant as
+// "<tenant>$<name>" like users.
+func (r *iamRoleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	tenant, name := splitUserID(req.ID)
+	if !roleNamePattern.MatchString(name) || strings.Contains(tenant, "$") {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Could not parse import ID %q.\n\nThe import ID must be of the form \"<name>\" or \"<tenant>$<name>\".", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), name)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("tenant"), optionalString(tenant))...)
+}
+
+// Create implements resource.Resource.
+func (r *iamRoleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan iamRoleResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	out, err := r.iam.CreateRoleWithContext(ctx, &iam.CreateRoleInput{
+		RoleName:                 aws.String(plan.Name.ValueString()),
+		Path:                     aws.String(plan.Path.ValueString()),
+		AssumeRolePolicyDocument: aws.String(plan.AssumeRolePolicy.ValueString()),
+		MaxSessionDuration:       aws.Int64(plan.MaxSessionDuration.ValueInt64()),
+	})
+	if err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error creating role",
+			fmt.Sprintf("Could not create role %q", plan.Name.ValueString()), err)
+		return
+	}
+
+	// radosgw creates the role in the tenant of the caller, whatever tenant
+	// is configured, and ModifyPlan cannot check it if the credentials may
+	// not look up their user
+	if !checkRoleTenant(&resp.Diagnostics, plan.Tenant, plan.Name.ValueString(), roleARNTenant(aws.StringValue(out.Role.Arn))) {
+		if _, err := r.iam.DeleteRoleWithContext(ctx, &iam.DeleteRoleInput{RoleName: out.Role.RoleName}); err != nil {
+			addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error deleting role",
+				fmt.Sprintf("Could not delete role %q created in another tenant", plan.Name.ValueString()), err)
+		}
+		return
+	}
+
+	policy := plan.AssumeRolePolicy
+	plan.setRole(out.Role)
+	// keep the trust policy as configured, radosgw may return it reformatted
+	plan.AssumeRolePolicy = policy
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *iamRoleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state iamRoleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.AssumeRolePolicy.Equal(state.AssumeRolePolicy) {
+		_, err := r.iam.UpdateAssumeRolePolicyWithContext(ctx, &iam.UpdateAssumeRolePolicyInput{
+			RoleName:       aws.String(plan.Name.ValueString()),
+			PolicyDocument: aws.String(plan.AssumeRolePolicy.ValueString()),
+		})
+		if err != nil {
+			addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error updating role",
+				fmt.Sprintf("Could not update trust policy of role %q", plan.Name.ValueString()), err)
+			return
+		}
+	}
+
+	if !plan.MaxSessionDuration.Equal(state.MaxSessionDuration) {
+		_, err := r.iam.UpdateRoleWithContext(ctx, &iam.UpdateRoleInput{
+			RoleName:           aws.String(plan.Name.ValueString()),
+			MaxSessionDuration: aws.Int64(plan.MaxSessionDuration.ValueInt64()),
+		})
+		if err != nil {
+			addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error updating role",
+				fmt.Sprintf("Could not update maximum session duration of role %q", plan.Name.ValueString()), err)
+			return
+		}
+	}
+
+	diags := resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete implements resource.Resource.
+func (r *iamRoleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state iamRoleResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.iam.DeleteRoleWithContext(ctx, &iam.DeleteRoleInput{
+		RoleName: aws.String(state.Name.ValueString()),
+	})
+	if awsErrorCode(err) == iam.ErrCodeNoSuchEntityException {
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error deleting role",
+			fmt.Sprintf("Could not delete role %q", state.Name.ValueString()), err)
+		return
+	}
+}
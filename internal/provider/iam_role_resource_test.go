@@ -0,0 +1,269 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/spreadshirt/terraform-provider-radosgw/internal/rgwtest"
+)
+
+func TestAccIAMRoleResource(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + testAccIAMRoleResourceConfig("sts:AssumeRole", 3600),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_iam_role.test", "arn", "arn:aws:iam:::role/apps/web"),
+					resource.TestCheckResourceAttr("radosgw_iam_role.test", "path", "/apps/"),
+					resource.TestCheckNoResourceAttr("radosgw_iam_role.test", "tenant"),
+					resource.TestCheckResourceAttr("radosgw_iam_role.test", "max_session_duration", "3600"),
+					resource.TestCheckResourceAttrSet("radosgw_iam_role.test", "role_id"),
+					resource.TestCheckResourceAttrSet("radosgw_iam_role.test", "create_date"),
+					testAccCheckRoleTrustPolicy(server, "", "web", regexp.MustCompile(`"sts:AssumeRole"`)),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "radosgw_iam_role.test",
+				ImportState:                          true,
+				ImportStateId:                        "web",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "name",
+			},
+			// Update and Read testing
+			{
+				Config: providerConfig + testAccIAMRoleResourceConfig("sts:TagSession", 7200),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_iam_role.test", "max_session_duration", "7200"),
+					testAccCheckRoleTrustPolicy(server, "", "web", regexp.MustCompile(`"sts:TagSession"`)),
+				),
+			},
+			// Removed outside of Terraform
+			{
+				PreConfig: func() {
+					server.DeleteRole("", "web")
+				},
+				Config:             providerConfig + testAccIAMRoleResourceConfig("sts:TagSession", 7200),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccIAMRoleResource_tenant(t *testing.T) {
+	server, _ := testAccServer(t)
+
+	// roles belong to the tenant of the provider credentials
+	providerConfig := testAccTenantProviderConfig(t, server, "acme")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + testAccIAMRoleResourceConfig("sts:AssumeRole", 3600),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_iam_role.test", "tenant", "acme"),
+					resource.TestCheckResourceAttr("radosgw_iam_role.test", "arn", "arn:aws:iam::acme:role/apps/web"),
+					testAccCheckRoleTrustPolicy(server, "acme", "web", regexp.MustCompile(`"sts:AssumeRole"`)),
+				),
+			},
+			{
+				ResourceName:                         "radosgw_iam_role.test",
+				ImportState:                          true,
+				ImportStateId:                        "acme$web",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "name",
+			},
+			{
+				ResourceName:  "radosgw_iam_role.test",
+				ImportState:   true,
+				ImportStateId: "other$web",
+				ExpectError:   regexp.MustCompile(`Role of another tenant`),
+			},
+			// configuring the tenant of the credentials changes nothing
+			{
+				Config:   providerConfig + testAccIAMRoleResourceTenantConfig("acme"),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccIAMRoleResource_tenantMismatch(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// the tenant is checked before creating the role
+			{
+				Config:      providerConfig + testAccIAMRoleResourceTenantConfig("acme"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Role of another tenant`),
+			},
+		},
+	})
+
+	if _, _, ok := server.Role("", "web"); ok {
+		t.Error("role web was created in the tenant of the provider credentials")
+	}
+}
+
+func TestAccIAMRoleResource_tenantMismatchWithoutUsersCap(t *testing.T) {
+	server, _ := testAccServer(t)
+
+	// credentials with only the roles cap cannot look up their tenant
+	providerConfig := testAccTenantProviderConfig(t, server, "acme")
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      providerConfig + testAccIAMRoleResourceTenantConfig("other"),
+				ExpectError: regexp.MustCompile(`Role of another tenant`),
+			},
+		},
+	})
+
+	// the role created in the tenant of the credentials is removed again
+	if _, _, ok := server.Role("acme", "web"); ok {
+		t.Error("role web was left in the tenant of the provider credentials")
+	}
+}
+
+func TestAccIAMRoleResource_validation(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	// a user without the roles cap may not manage roles
+	user, err := server.API().CreateUser(context.Background(), admin.User{ID: "ops", DisplayName: "Operator"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	restrictedConfig := fmt.Sprintf(`
+provider "radosgw" {
+  endpoint          = %q
+  access_key_id     = %q
+  secret_access_key = %q
+}
+`, server.URL, user.Keys[0].AccessKey, user.Keys[0].SecretKey)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      providerConfig + testAccIAMRoleResourceConfig("s3:GetObject", 3600),
+				ExpectError: regexp.MustCompile(`"s3:GetObject" is not\s+supported\s+by\s+radosgw\s+in\s+a\s+trust\s+policy`),
+			},
+			{
+				Config:      providerConfig + testAccIAMRoleResourceConfig("sts:AssumeRole", 60),
+				ExpectError: regexp.MustCompile(`value must be between 3600 and 43200`),
+			},
+			{
+				Config: providerConfig + `
+resource "radosgw_iam_role" "test" {
+  name = "web"
+  path = "apps"
+
+  assume_role_policy = jsonencode({
+    Version   = "2012-10-17"
+    Statement = [{ Effect = "Allow", Action = "sts:AssumeRole", Principal = { AWS = "arn:aws:iam:::user/demo" } }]
+  })
+}
+`,
+				ExpectError: regexp.MustCompile(`must start and end with "/"`),
+			},
+			{
+				Config:      restrictedConfig + testAccIAMRoleResourceConfig("sts:AssumeRole", 3600),
+				ExpectError: regexp.MustCompile(`roles=\*`),
+			},
+		},
+	})
+}
+
+func testAccIAMRoleResourceConfig(action string, maxSessionDuration int) string {
+	return fmt.Sprintf(`
+resource "radosgw_iam_role" "test" {
+  name                 = "web"
+  path                 = "/apps/"
+  max_session_duration = %d
+
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [{
+      Effect    = "Allow"
+      Action    = %q
+      Principal = { AWS = "arn:aws:iam:::user/demo" }
+    }]
+  })
+}
+`, maxSessionDuration, action)
+}
+
+func testAccIAMRoleResourceTenantConfig(tenant string) string {
+	return fmt.Sprintf(`
+resource "radosgw_iam_role" "test" {
+  name                 = "web"
+  tenant               = %q
+  path                 = "/apps/"
+  max_session_duration = 3600
+
+  assume_role_policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [{
+      Effect    = "Allow"
+      Action    = "sts:AssumeRole"
+      Principal = { AWS = "arn:aws:iam:::user/demo" }
+    }]
+  })
+}
+`, tenant)
+}
+
+// testAccTenantProviderConfig creates a user of tenant with the roles cap
+// and returns a provider configuration with its credentials.
+func testAccTenantProviderConfig(t *testing.T, server *rgwtest.Server, tenant string) string {
+	t.Helper()
+
+	user, err := server.API().CreateUser(context.Background(), admin.User{
+		ID:          "ops",
+		Tenant:      tenant,
+		DisplayName: "Operator",
+		UserCaps:    "roles=*",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprintf(`
+provider "radosgw" {
+  endpoint          = %q
+  access_key_id     = %q
+  secret_access_key = %q
+}
+`, server.URL, user.Keys[0].AccessKey, user.Keys[0].SecretKey)
+}
+
+// testAccCheckRoleTrustPolicy checks the trust policy of a role on the fake
+// radosgw.
+func testAccCheckRoleTrustPolicy(server *rgwtest.Server, tenant, name string, pattern *regexp.Regexp) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		policy, _, ok := server.Role(tenant, name)
+		if !ok {
+			return fmt.Errorf("role %q does not exist", name)
+		}
+		if !pattern.MatchString(policy) {
+			return fmt.Errorf("trust policy of role %q does not match %s: %s", name, pattern, policy)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,179 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// joinUserID returns the user ID as used by the admin API, which prefixes
+// the user with its tenant as "<tenant>$<user>" if there is one.
+func joinUserID(tenant, user string) string {
+	if tenant == "" {
+		return user
+	}
+	return tenant + "$" + user
+}
+
+// splitUserID splits a user ID as returned by the admin API into its tenant
+// and user.
+func splitUserID(uid string) (tenant, user string) {
+	tenant, user, found := strings.Cut(uid, "$")
+	if !found {
+		return "", uid
+	}
+	return tenant, user
+}
+
+// userRef refers to a user, or to one of its subusers if Subuser is set.
+//
+// The admin API writes it as "[<tenant>$]<user>[:<subuser>]", for example as
+// the owner of keys and the name of subusers.
+type userRef struct {
+	Tenant  string `json:"tenant"`
+	UserID  string `json:"user_id"`
+	Subuser string `json:"subuser"`
+}
+
+// parseUserRef parses a user or subuser as written by the admin API.
+func parseUserRef(s string) userRef {
+	uid, subuser, _ := strings.Cut(s, ":")
+	tenant, user := splitUserID(uid)
+	return userRef{Tenant: tenant, UserID: user, Subuser: subuser}
+}
+
+// uid returns the ID of the user as used by the admin API.
+func (ref userRef) uid() string {
+	return joinUserID(ref.Tenant, ref.UserID)
+}
+
+// String returns the user or subuser in the "[<tenant>$]<user>[:<subuser>]"
+// format of the admin API.
+func (ref userRef) String() string {
+	if ref.Subuser == "" {
+		return ref.uid()
+	}
+	return ref.uid() + ":" + ref.Subuser
+}
+
+// arn returns the ARN of the user or subuser as used by radosgw in policy
+// principals, "arn:aws:iam::<tenant>:user/<user>[:<subuser>]" with an empty
+// tenant for users without one.
+func (ref userRef) arn() string {
+	name := ref.UserID
+	if ref.Subuser != "" {
+		name += ":" + ref.Subuser
+	}
+	return fmt.Sprintf("arn:aws:iam::%s:user/%s", ref.Tenant, name)
+}
+
+// subuserImportFormats describes the import IDs accepted by parseSubuserImportID.
+const subuserImportFormats = `"<user>:<subuser>", "<tenant>$<user>:<subuser>" or ` +
+	`{"tenant": "<tenant>", "user_id": "<user>", "subuser": "<subuser>"}`
+
+// parseSubuserImportID parses the import ID of a radosgw_subuser.
+func parseSubuserImportID(importID string) (userRef, error) {
+	var id userRef
+
+	if strings.HasPrefix(strings.TrimSpace(importID), "{") {
+		decoder := json.NewDecoder(strings.NewReader(importID))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&id); err != nil {
+			return userRef{}, fmt.Errorf("invalid JSON import ID: %w", err)
+		}
+	} else {
+		if !strings.Contains(importID, ":") {
+			return userRef{}, fmt.Errorf("missing \":\" between user and subuser")
+		}
+		id = parseUserRef(importID)
+	}
+
+	if id.Subuser == "" {
+		return userRef{}, fmt.Errorf("missing subuser")
+	}
+	if err := validateUserRef(id); err != nil {
+		return userRef{}, err
+	}
+
+	return id, nil
+}
+
+// parseUserImportID parses the import ID "[<tenant>$]<user>" of a
+// radosgw_user.
+func parseUserImportID(importID string) (userRef, error) {
+	tenant, user := splitUserID(importID)
+	id := userRef{Tenant: tenant, UserID: user}
+	if err := validateUserRef(id); err != nil {
+		return userRef{}, err
+	}
+	return id, nil
+}
+
+// validateUserRef checks that the parts of ref are set and do not contain
+// the separators of the admin API.
+func validateUserRef(ref userRef) error {
+	if ref.UserID == "" {
+		return fmt.Errorf("missing user")
+	}
+	if strings.ContainsAny(ref.UserID, "$:") || strings.ContainsAny(ref.Subuser, "$:") || strings.ContainsAny(ref.Tenant, "$:") {
+		return fmt.Errorf(`tenant, user and subuser must not contain "$" or ":"`)
+	}
+	return nil
+}
+
+// userIdentityModel is the resource identity of a radosgw_user.
+type userIdentityModel struct {
+	Tenant types.String `tfsdk:"tenant"`
+	UserID types.String `tfsdk:"user_id"`
+}
+
+// ref returns the user identified by m.
+func (m userIdentityModel) ref() userRef {
+	return userRef{Tenant: m.Tenant.ValueString(), UserID: m.UserID.ValueString()}
+}
+
+// subuserIdentityModel is the resource identity of a radosgw_subuser.
+type subuserIdentityModel struct {
+	Tenant  types.String `tfsdk:"tenant"`
+	UserID  types.String `tfsdk:"user_id"`
+	Subuser types.String `tfsdk:"subuser"`
+}
+
+// ref returns the subuser identified by m.
+func (m subuserIdentityModel) ref() userRef {
+	return userRef{Tenant: m.Tenant.ValueString(), UserID: m.UserID.ValueString(), Subuser: m.Subuser.ValueString()}
+}
+
+// keyIdentityModel is the resource identity of a radosgw_key.
+type keyIdentityModel struct {
+	Tenant    types.String `tfsdk:"tenant"`
+	UserID    types.String `tfsdk:"user_id"`
+	Subuser   types.String `tfsdk:"subuser"`
+	AccessKey types.String `tfsdk:"access_key"`
+}
+
+// owner returns the user or subuser owning the key identified by m.
+func (m keyIdentityModel) owner() userRef {
+	return userRef{Tenant: m.Tenant.ValueString(), UserID: m.UserID.ValueString(), Subuser: m.Subuser.ValueString()}
+}
+
+// newKeyIdentity returns the identity of the key with accessKey owned by
+// owner.
+func newKeyIdentity(owner userRef, accessKey string) keyIdentityModel {
+	return keyIdentityModel{
+		Tenant:    optionalString(owner.Tenant),
+		UserID:    types.StringValue(owner.UserID),
+		Subuser:   optionalString(owner.Subuser),
+		AccessKey: types.StringValue(accessKey),
+	}
+}
+
+// optionalString returns s, or null if s is empty.
+func optionalString(s string) types.String {
+	if s == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(s)
+}
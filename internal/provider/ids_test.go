@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"testing"
+)
+
+func TestParseSubuserImportID(t *testing.T) {
+	tests := []struct {
+		importID string
+		want     userRef
+		wantErr  bool
+	}{
+		{importID: "demo:readonly", want: userRef{UserID: "demo", Subuser: "readonly"}},
+		{importID: "acme$demo:readonly", want: userRef{Tenant: "acme", UserID: "demo", Subuser: "readonly"}},
+		{importID: `{"user_id": "demo", "subuser": "readonly"}`, want: userRef{UserID: "demo", Subuser: "readonly"}},
+		{importID: `{"tenant": "acme", "user_id": "demo", "subuser": "readonly"}`, want: userRef{Tenant: "acme", UserID: "demo", Subuser: "readonly"}},
+		{importID: "demo", wantErr: true},
+		{importID: "demo:", wantErr: true},
+		{importID: ":readonly", wantErr: true},
+		{importID: "acme$:readonly", wantErr: true},
+		{importID: "demo:read:only", wantErr: true},
+		{importID: `{"user_id": "demo"}`, wantErr: true},
+		{importID: `{"user": "demo", "subuser": "readonly"}`, wantErr: true},
+		{importID: `{"user_id": "demo",`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.importID, func(t *testing.T) {
+			got, err := parseSubuserImportID(tt.importID)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseUserImportID(t *testing.T) {
+	tests := []struct {
+		importID string
+		want     userRef
+		wantErr  bool
+	}{
+		{importID: "demo", want: userRef{UserID: "demo"}},
+		{importID: "acme$demo", want: userRef{Tenant: "acme", UserID: "demo"}},
+		{importID: "", wantErr: true},
+		{importID: "acme$", wantErr: true},
+		{importID: "demo:readonly", wantErr: true},
+		{importID: "acme$demo$other", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.importID, func(t *testing.T) {
+			got, err := parseUserImportID(tt.importID)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUserRef(t *testing.T) {
+	for _, s := range []string{"demo", "demo:readonly", "acme$demo", "acme$demo:readonly"} {
+		t.Run(s, func(t *testing.T) {
+			if got := parseUserRef(s).String(); got != s {
+				t.Errorf("got %q after parsing and formatting", got)
+			}
+		})
+	}
+
+	ref := parseUserRef("acme$demo:readonly")
+	if want := (userRef{Tenant: "acme", UserID: "demo", Subuser: "readonly"}); ref != want {
+		t.Errorf("expected %+v, got %+v", want, ref)
+	}
+	if ref.uid() != "acme$demo" {
+		t.Errorf("got uid %q, want %q", ref.uid(), "acme$demo")
+	}
+}
+
+func TestUserRefARN(t *testing.T) {
+	tests := map[string]string{
+		"demo":               "arn:aws:iam:::user/demo",
+		"demo:readonly":      "arn:aws:iam:::user/demo:readonly",
+		"acme$demo":          "arn:aws:iam::acme:user/demo",
+		"acme$demo:readonly": "arn:aws:iam::acme:user/demo:readonly",
+	}
+	for s, want := range tests {
+		t.Run(s, func(t *testing.T) {
+			arn := parseUserRef(s).arn()
+			if arn != want {
+				t.Errorf("got %q, want %q", arn, want)
+			}
+			if !principalARNPattern.MatchString(arn) {
+				t.Errorf("%q is not accepted as principal", arn)
+			}
+		})
+	}
+}
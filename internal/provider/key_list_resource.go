@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ list.ListResource              = &keyListResource{}
+	_ list.ListResourceWithConfigure = &keyListResource{}
+)
+
+// NewKeyListResource is a helper function to simplify the provider implementation.
+func NewKeyListResource() list.ListResource {
+	return &keyListResource{}
+}
+
+// keyListResource lists the S3 keys of radosgw.
+type keyListResource struct {
+	client adminClient
+
+	// ownAccessKey is the access key the provider is configured with.
+	ownAccessKey string
+}
+
+// Configure implements list.ListResourceWithConfigure.
+func (r *keyListResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
+		return
+	}
+
+	r.client = data.client
+	r.ownAccessKey = data.accessKeyID
+}
+
+// Metadata returns the resource type name.
+func (r *keyListResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_key"
+}
+
+// ListResourceConfigSchema defines the schema for list blocks.
+func (r *keyListResource) ListResourceConfigSchema(_ context.Context, _ list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists the S3 keys of radosgw users and subusers, including those of tenanted users.  The key the provider is configured with is not listed either, so that it is not managed by accident.",
+		Attributes: map[string]schema.Attribute{
+			"tenant": schema.StringAttribute{
+				MarkdownDescription: "Only list keys of users of this tenant.",
+				Optional:            true,
+			},
+			"user": schema.StringAttribute{
+				MarkdownDescription: "Only list keys of this user and its subusers.",
+				Optional:            true,
+			},
+			"subuser": schema.StringAttribute{
+				MarkdownDescription: "Only list keys of subusers with this name.",
+				Optional:            true,
+			},
+			"prefix": schema.StringAttribute{
+				MarkdownDescription: "Only list keys whose access key starts with this prefix.",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+type keyListConfigModel struct {
+	Tenant  types.String `tfsdk:"tenant"`
+	User    types.String `tfsdk:"user"`
+	Subuser types.String `tfsdk:"subuser"`
+	Prefix  types.String `tfsdk:"prefix"`
+}
+
+// List implements list.ListResource.
+func (r *keyListResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	var config keyListConfigModel
+	diags := req.Config.Get(ctx, &config)
+	if diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	include := func(uid string) bool {
+		tenant, userID := splitUserID(uid)
+		return (config.Tenant.IsNull() || tenant == config.Tenant.ValueString()) &&
+			(config.User.IsNull() || userID == config.User.ValueString())
+	}
+
+	stream.Results = func(push func(list.ListResult) bool) {
+		err := listUsers(ctx, r.client, include, func(user admin.User) bool {
+			for _, key := range user.Keys {
+				owner := parseUserRef(key.User)
+				if key.AccessKey == r.ownAccessKey ||
+					!config.Subuser.IsNull() && owner.Subuser != config.Subuser.ValueString() ||
+					!strings.HasPrefix(key.AccessKey, config.Prefix.ValueString()) {
+					continue
+				}
+
+				state := importedKeyState(key)
+
+				result := req.NewListResult(ctx)
+				result.DisplayName = key.AccessKey + " (" + key.User + ")"
+				result.Diagnostics.Append(result.Identity.Set(ctx, state.identity())...)
+				if req.IncludeResource {
+					result.Diagnostics.Append(result.Resource.Set(ctx, state)...)
+				}
+
+				if !push(result) {
+					return false
+				}
+			}
+			return true
+		})
+		if err != nil {
+			push(listErrorResult("Error listing keys", err))
+		}
+	}
+}
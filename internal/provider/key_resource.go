@@ -3,12 +3,18 @@ package provider
 import (
 	"context"
 	"fmt"
-	"strings"
+	"sync"
 
 	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -16,6 +22,7 @@ var (
 	_ resource.Resource                = &keyResource{}
 	_ resource.ResourceWithConfigure   = &keyResource{}
 	_ resource.ResourceWithImportState = &keyResource{}
+	_ resource.ResourceWithIdentity    = &keyResource{}
 )
 
 // NewKeyResource is a helper function to simplify the provider implementation.
@@ -25,27 +32,21 @@ func NewKeyResource() resource.Resource {
 
 // keyResource is the resource implementation.
 type keyResource struct {
-	client *admin.API
+	client adminClient
+
+	// adoptExisting is the provider default for adopting existing keys.
+	adoptExisting bool
 }
 
 // Configure implements resource.ResourceWithConfigure.
 func (r *keyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Prevent panic if the provider has not been configured.
-	if req.ProviderData == nil {
-		return
-	}
-
-	client, ok := req.ProviderData.(*admin.API)
-	if !ok {
-		resp.Diagnostics.AddError(
-			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *admin.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
-		)
-
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
 		return
 	}
 
-	r.client = client
+	r.client = data.client
+	r.adoptExisting = data.adoptExisting
 }
 
 // Metadata returns the resource type name.
@@ -57,31 +58,145 @@ func (r *keyResource) Metadata(_ context.Context, req resource.MetadataRequest,
 func (r *keyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
+			"tenant": schema.StringAttribute{
+				MarkdownDescription: "Tenant of the user, if any.",
+				Optional:            true,
+				Validators:          tenantValidators(),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
 			"user": schema.StringAttribute{
-				Required: true,
+				MarkdownDescription: "ID of the user owning the key, without its tenant.",
+				Required:            true,
+				Validators:          rgwIDValidators(),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"subuser": schema.StringAttribute{
-				Optional: true,
+				Optional:   true,
+				Validators: rgwIDValidators(),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"access_key": schema.StringAttribute{
 				Optional:  true,
 				Computed:  true,
 				Sensitive: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"secret_key": schema.StringAttribute{
 				Optional:  true,
 				Computed:  true,
 				Sensitive: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"adopt_existing": adoptExistingAttribute("key"),
+		},
+	}
+}
+
+// IdentitySchema defines the identity of the resource.
+func (r *keyResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"tenant": identityschema.StringAttribute{
+				Description:       "Tenant of the user, if any.",
+				OptionalForImport: true,
+			},
+			"user_id": identityschema.StringAttribute{
+				Description:       "User owning the key.  Speeds up the import, as only the keys of this user are searched.",
+				OptionalForImport: true,
+			},
+			"subuser": identityschema.StringAttribute{
+				Description:       "Subuser owning the key, if any.",
+				OptionalForImport: true,
+			},
+			"access_key": identityschema.StringAttribute{
+				RequiredForImport: true,
 			},
 		},
 	}
 }
 
 type keyResourceModel struct {
+	Tenant    types.String `tfsdk:"tenant"`
 	User      types.String `tfsdk:"user"`
 	Subuser   types.String `tfsdk:"subuser"`
 	AccessKey types.String `tfsdk:"access_key"`
 	SecretKey types.String `tfsdk:"secret_key"`
+
+	AdoptExisting types.Bool `tfsdk:"adopt_existing"`
+}
+
+// owner returns the user or subuser owning the key.  Without tenant, user
+// may still include the tenant as "<tenant>$<user>" in state written before
+// the tenant attribute existed.
+func (m keyResourceModel) owner() userRef {
+	ref := userRef{Tenant: m.Tenant.ValueString(), UserID: m.User.ValueString(), Subuser: m.Subuser.ValueString()}
+	if ref.Tenant == "" {
+		ref.Tenant, ref.UserID = splitUserID(ref.UserID)
+	}
+	return ref
+}
+
+// uid returns the ID of the user owning the key as used by the admin API.
+func (m keyResourceModel) uid() string {
+	return m.owner().uid()
+}
+
+// setOwner sets the tenant, user and subuser from the owner of a key as
+// returned by the admin API.
+func (m *keyResourceModel) setOwner(owner string) {
+	ref := parseUserRef(owner)
+	m.Tenant = optionalString(ref.Tenant)
+	m.User = types.StringValue(ref.UserID)
+	m.Subuser = optionalString(ref.Subuser)
+}
+
+// identity returns the resource identity of the key.
+func (m keyResourceModel) identity() keyIdentityModel {
+	return newKeyIdentity(m.owner(), m.AccessKey.ValueString())
+}
+
+// errorTarget describes the key for explaining admin API errors.
+func (m keyResourceModel) errorTarget() rgwErrorTarget {
+	return rgwErrorTarget{
+		resourceType: "radosgw_key",
+		importID:     m.AccessKey.ValueString(),
+		userPath:     path.Root("user"),
+		subuserPath:  path.Root("subuser"),
+		keyPath:      path.Root("access_key"),
+	}
+}
+
+// keyCreationLocks holds a mutex per user to serialize key creation.
+var (
+	keyCreationLocksMu sync.Mutex
+	keyCreationLocks   = make(map[string]*sync.Mutex)
+)
+
+// lockKeyCreation locks key creation for user and returns the function to
+// unlock it again.
+func lockKeyCreation(user string) func() {
+	keyCreationLocksMu.Lock()
+	mu, ok := keyCreationLocks[user]
+	if !ok {
+		mu = &sync.Mutex{}
+		keyCreationLocks[user] = mu
+	}
+	keyCreationLocksMu.Unlock()
+
+	mu.Lock()
+	return mu.Unlock
 }
 
 // Create creates the resource and sets the initial Terraform state.
@@ -93,26 +208,51 @@ func (r *keyResource) Create(ctx context.Context, req resource.CreateRequest, re
 		return
 	}
 
-	user, err := r.client.GetUser(ctx, admin.User{ID: plan.User.ValueString()})
+	// new keys are found by comparing the keys before and after creation,
+	// so concurrent key creation for the same user must not interleave
+	unlock := lockKeyCreation(plan.uid())
+	defer unlock()
+
+	user, err := r.client.GetUser(ctx, admin.User{ID: plan.uid()})
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error fetching user",
-			"Could not fetch user: "+err.Error(),
-		)
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error fetching user",
+			fmt.Sprintf("Could not fetch user %q", plan.uid()), err)
 		return
 	}
 	seen := make(map[string]bool, len(user.Keys))
-	for _, key := range user.Keys {
+	var existing *admin.UserKeySpec
+	for i, key := range user.Keys {
 		seen[key.AccessKey] = true
+		if key.AccessKey == plan.AccessKey.ValueString() && key.User == plan.owner().String() {
+			existing = &user.Keys[i]
+		}
+	}
+
+	if existing != nil && shouldAdopt(plan.AdoptExisting, r.adoptExisting) {
+		if err := r.adopt(ctx, &plan, *existing, &resp.Diagnostics); err != nil {
+			addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error adopting key",
+				fmt.Sprintf("Could not adopt existing key %q", plan.AccessKey.ValueString()), err)
+			return
+		}
+
+		diags = resp.State.Set(ctx, plan)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		diags = resp.Identity.Set(ctx, plan.identity())
+		resp.Diagnostics.Append(diags...)
+		return
 	}
 
 	newKey := admin.UserKeySpec{
-		User:      plan.User.ValueString(),
+		User:      plan.uid(),
 		SubUser:   plan.Subuser.ValueString(),
 		AccessKey: plan.AccessKey.ValueString(),
 		SecretKey: plan.SecretKey.ValueString(),
 
-		UID:     plan.User.ValueString(),
+		UID:     plan.uid(),
 		KeyType: "s3",
 	}
 	if newKey.AccessKey == "" || newKey.SecretKey == "" {
@@ -122,10 +262,8 @@ func (r *keyResource) Create(ctx context.Context, req resource.CreateRequest, re
 
 	keys, err := r.client.CreateKey(ctx, newKey)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating key",
-			"Could not create key: "+err.Error(),
-		)
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error creating key",
+			fmt.Sprintf("Could not create key for user %q", plan.uid()), err)
 		return
 	}
 
@@ -134,14 +272,7 @@ func (r *keyResource) Create(ctx context.Context, req resource.CreateRequest, re
 			continue
 		}
 
-		parts := strings.SplitN(key.User, ":", 2)
-		if len(parts) == 2 {
-			plan.User = types.StringValue(parts[0])
-			plan.Subuser = types.StringValue(parts[1])
-		} else {
-			plan.User = types.StringValue(key.User)
-		}
-
+		plan.setOwner(key.User)
 		plan.AccessKey = types.StringValue(key.AccessKey)
 		plan.SecretKey = types.StringValue(key.SecretKey)
 
@@ -153,6 +284,40 @@ func (r *keyResource) Create(ctx context.Context, req resource.CreateRequest, re
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	diags = resp.Identity.Set(ctx, plan.identity())
+	resp.Diagnostics.Append(diags...)
+}
+
+// adopt takes over the existing key of plan.  If its secret key differs from
+// the plan, the key is replaced by one with the planned secret key.
+func (r *keyResource) adopt(ctx context.Context, plan *keyResourceModel, existing admin.UserKeySpec, diags *diag.Diagnostics) error {
+	var changed []string
+	if plan.SecretKey.IsUnknown() {
+		plan.SecretKey = types.StringValue(existing.SecretKey)
+	} else if existing.SecretKey != plan.SecretKey.ValueString() {
+		// creating an existing access key replaces its secret, so the key
+		// survives if this fails
+		key := admin.UserKeySpec{
+			UID:       plan.uid(),
+			SubUser:   plan.Subuser.ValueString(),
+			AccessKey: plan.AccessKey.ValueString(),
+			SecretKey: plan.SecretKey.ValueString(),
+			KeyType:   "s3",
+		}
+		if _, err := r.client.CreateKey(ctx, key); err != nil {
+			return err
+		}
+		changed = append(changed, "secret_key")
+	}
+
+	tflog.Warn(ctx, "adopted existing key", map[string]any{"user": plan.owner().String(), "fields": changed})
+	diags.AddWarning(
+		"Adopted existing key",
+		fmt.Sprintf("Key %q of %q already existed and is now managed by Terraform.%s", plan.AccessKey.ValueString(), plan.owner().String(), adoptedChanges(changed)),
+	)
+
+	return nil
 }
 
 // Read refreshes the Terraform state with the latest data.
@@ -164,20 +329,14 @@ func (r *keyResource) Read(ctx context.Context, req resource.ReadRequest, resp *
 		return
 	}
 
-	user, err := r.client.GetUser(ctx, admin.User{ID: state.User.ValueString()})
+	user, err := r.client.GetUser(ctx, admin.User{ID: state.uid()})
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error fetching user for key retrieval",
-			fmt.Sprintf("Could not fetch user %q for key retrieval: %s", state.User.ValueString(), err.Error()),
-		)
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error fetching user for key retrieval",
+			fmt.Sprintf("Could not fetch user %q for key retrieval", state.uid()), err)
 		return
 	}
 
-	expectedUser := state.User.ValueString()
-	if !state.Subuser.IsNull() {
-		expectedUser = state.User.ValueString() + ":" + state.Subuser.ValueString()
-	}
-
+	expectedUser := state.owner().String()
 	var found bool
 	var matchingKey admin.UserKeySpec
 	for _, key := range user.Keys {
@@ -196,14 +355,7 @@ func (r *keyResource) Read(ctx context.Context, req resource.ReadRequest, resp *
 		return
 	}
 
-	parts := strings.SplitN(matchingKey.User, ":", 2)
-	if len(parts) == 2 {
-		state.User = types.StringValue(parts[0])
-		state.Subuser = types.StringValue(parts[1])
-	} else {
-		state.User = types.StringValue(matchingKey.User)
-	}
-
+	state.setOwner(matchingKey.User)
 	state.AccessKey = types.StringValue(matchingKey.AccessKey)
 	state.SecretKey = types.StringValue(matchingKey.SecretKey)
 
@@ -212,16 +364,38 @@ func (r *keyResource) Read(ctx context.Context, req resource.ReadRequest, resp *
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	diags = resp.Identity.Set(ctx, state.identity())
+	resp.Diagnostics.Append(diags...)
 }
 
+// ImportState implements resource.ResourceWithImportState.  Keys are imported
+// by their access key or by their identity.
 func (r *keyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	users, err := r.client.GetUsers(ctx)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error fetching users for key import",
-			"Could not fetch users for key import: "+err.Error(),
-		)
-		return
+	accessKey := req.ID
+	var users *[]string
+	if accessKey == "" {
+		var identity keyIdentityModel
+		diags := req.Identity.Get(ctx, &identity)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		accessKey = identity.AccessKey.ValueString()
+		if identity.UserID.ValueString() != "" {
+			users = &[]string{identity.owner().uid()}
+		}
+	}
+
+	if users == nil {
+		var err error
+		users, err = r.client.GetUsers(ctx)
+		if err != nil {
+			addRGWError(&resp.Diagnostics, rgwErrorTarget{}, "Error fetching users for key import",
+				"Could not fetch users for key import", err)
+			return
+		}
 	}
 
 	var found bool
@@ -229,15 +403,13 @@ func (r *keyResource) ImportState(ctx context.Context, req resource.ImportStateR
 	for _, userName := range *users {
 		user, err := r.client.GetUser(ctx, admin.User{ID: userName})
 		if err != nil {
-			resp.Diagnostics.AddError(
-				"Error fetching user",
-				"Could not fetch user: "+err.Error(),
-			)
+			addRGWError(&resp.Diagnostics, rgwErrorTarget{}, "Error fetching user",
+				fmt.Sprintf("Could not fetch user %q", userName), err)
 			return
 		}
 
 		for _, key := range user.Keys {
-			if key.AccessKey == req.ID {
+			if key.AccessKey == accessKey {
 				found = true
 				matchingKey = key
 				break
@@ -253,32 +425,46 @@ func (r *keyResource) ImportState(ctx context.Context, req resource.ImportStateR
 		return
 	}
 
-	var state keyResourceModel
-	parts := strings.SplitN(matchingKey.User, ":", 2)
-	if len(parts) == 2 {
-		state.User = types.StringValue(parts[0])
-		state.Subuser = types.StringValue(parts[1])
-	} else {
-		state.User = types.StringValue(matchingKey.User)
-	}
-
-	state.AccessKey = types.StringValue(matchingKey.AccessKey)
-	state.SecretKey = types.StringValue(matchingKey.SecretKey)
-
+	state := importedKeyState(matchingKey)
 	diags := resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	diags = resp.Identity.Set(ctx, state.identity())
+	resp.Diagnostics.Append(diags...)
+}
+
+// importedKeyState returns the state of an imported key.
+func importedKeyState(key admin.UserKeySpec) keyResourceModel {
+	var state keyResourceModel
+	state.setOwner(key.User)
+	state.AccessKey = types.StringValue(key.AccessKey)
+	state.SecretKey = types.StringValue(key.SecretKey)
+	return state
 }
 
 // Update updates the resource and sets the updated Terraform state on success.
 func (r *keyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	// TODO: consider supporting updating keys (access key equal => new secret; if not re-create to rotate both)
-	resp.Diagnostics.AddError(
-		"Updating keys not yet supported (and might not make sense)",
-		"We don't support updating keys yet, because usually both the access key and secret key are rotated, so the can just be recreated",
-	)
+	// All attributes but adopt_existing require replacement, so only the
+	// state is updated here.
+	var plan keyResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.Identity.Set(ctx, plan.identity())
+	resp.Diagnostics.Append(diags...)
 }
 
 // Delete deletes the resource and removes the Terraform state on success.
@@ -291,16 +477,14 @@ func (r *keyResource) Delete(ctx context.Context, req resource.DeleteRequest, re
 	}
 
 	err := r.client.RemoveKey(ctx, admin.UserKeySpec{
-		UID:       state.User.ValueString(),
+		UID:       state.uid(),
 		SubUser:   state.Subuser.ValueString(),
 		AccessKey: state.AccessKey.ValueString(),
 		KeyType:   "s3",
 	})
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error removing key",
-			fmt.Sprintf("Could not remove key %q: %s", state.AccessKey.ValueString(), err),
-		)
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error removing key",
+			fmt.Sprintf("Could not remove key %q", state.AccessKey.ValueString()), err)
 		return
 	}
 }
@@ -0,0 +1,375 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+
+	"github.com/spreadshirt/terraform-provider-radosgw/internal/rgwtest"
+)
+
+func TestAccKeyResource(t *testing.T) {
+	_, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + testAccKeyResourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_key.user", "user", "demo"),
+					resource.TestCheckNoResourceAttr("radosgw_key.user", "subuser"),
+					resource.TestCheckResourceAttrSet("radosgw_key.user", "access_key"),
+					resource.TestCheckResourceAttrSet("radosgw_key.user", "secret_key"),
+					resource.TestCheckResourceAttr("radosgw_key.subuser", "user", "demo"),
+					resource.TestCheckResourceAttr("radosgw_key.subuser", "subuser", "readonly"),
+					resource.TestCheckResourceAttr("radosgw_key.explicit", "access_key", "DEMOACCESSKEY"),
+					resource.TestCheckResourceAttr("radosgw_key.explicit", "secret_key", "demo-secret-key"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "radosgw_key.user",
+				ImportState:                          true,
+				ImportStateIdFunc:                    testAccKeyImportID("radosgw_key.user"),
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "access_key",
+			},
+			{
+				ResourceName:                         "radosgw_key.subuser",
+				ImportState:                          true,
+				ImportStateIdFunc:                    testAccKeyImportID("radosgw_key.subuser"),
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "access_key",
+			},
+		},
+	})
+}
+
+const testAccKeyResourceConfig = `
+resource "radosgw_user" "test" {
+  user_id      = "demo"
+  display_name = "Demo user"
+}
+
+resource "radosgw_subuser" "test" {
+  user_id = radosgw_user.test.user_id
+  subuser = "readonly"
+  access  = "read"
+}
+
+resource "radosgw_key" "user" {
+  user = radosgw_user.test.user_id
+}
+
+resource "radosgw_key" "subuser" {
+  user    = radosgw_user.test.user_id
+  subuser = radosgw_subuser.test.subuser
+}
+
+resource "radosgw_key" "explicit" {
+  user       = radosgw_user.test.user_id
+  access_key = "DEMOACCESSKEY"
+  secret_key = "demo-secret-key"
+}
+`
+
+func TestAccKeyResource_tenant(t *testing.T) {
+	_, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_12_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "radosgw_user" "test" {
+  tenant       = "acme"
+  user_id      = "demo"
+  display_name = "Demo user"
+}
+
+resource "radosgw_subuser" "test" {
+  tenant  = radosgw_user.test.tenant
+  user_id = radosgw_user.test.user_id
+  subuser = "readonly"
+  access  = "read"
+}
+
+resource "radosgw_key" "test" {
+  tenant     = radosgw_user.test.tenant
+  user       = radosgw_user.test.user_id
+  subuser    = radosgw_subuser.test.subuser
+  access_key = "ACMEACCESSKEY"
+  secret_key = "acme-secret-key"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_key.test", "tenant", "acme"),
+					resource.TestCheckResourceAttr("radosgw_key.test", "user", "demo"),
+					resource.TestCheckResourceAttr("radosgw_key.test", "subuser", "readonly"),
+				),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectIdentity("radosgw_key.test", map[string]knownvalue.Check{
+						"tenant":     knownvalue.StringExact("acme"),
+						"user_id":    knownvalue.StringExact("demo"),
+						"subuser":    knownvalue.StringExact("readonly"),
+						"access_key": knownvalue.StringExact("ACMEACCESSKEY"),
+					}),
+				},
+			},
+			{
+				ResourceName:                         "radosgw_key.test",
+				ImportState:                          true,
+				ImportStateId:                        "ACMEACCESSKEY",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "access_key",
+			},
+			{
+				ResourceName:    "radosgw_key.test",
+				ImportState:     true,
+				ImportStateKind: resource.ImportBlockWithResourceIdentity,
+			},
+		},
+	})
+}
+
+func TestAccKeyResource_validation(t *testing.T) {
+	_, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// the tenant is given separately, like for the other resources
+			{
+				Config: providerConfig + `
+resource "radosgw_key" "test" {
+  user       = "acme$demo"
+  access_key = "ACMEACCESSKEY"
+  secret_key = "acme-secret-key"
+}
+`,
+				ExpectError: regexp.MustCompile(`must\s+not\s+contain\s+"\$"\s+or\s+":"`),
+			},
+		},
+	})
+}
+
+// TestKeyResourceRead_legacyTenantedUser checks that state of a key whose
+// user is given as "<tenant>$<user>" is moved to tenant on refresh.
+func TestKeyResourceRead_legacyTenantedUser(t *testing.T) {
+	server := rgwtest.NewServer()
+	defer server.Close()
+	ctx := context.Background()
+	if _, err := server.API().CreateUser(ctx, admin.User{ID: "acme$demo", DisplayName: "Demo user"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.API().CreateKey(ctx, admin.UserKeySpec{UID: "acme$demo", KeyType: "s3", AccessKey: "ACMEACCESSKEY", SecretKey: "acme-secret-key"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := testReadResource(t, &keyResource{client: server.API()}, keyResourceModel{
+		Tenant:        types.StringNull(),
+		User:          types.StringValue("acme$demo"),
+		Subuser:       types.StringNull(),
+		AccessKey:     types.StringValue("ACMEACCESSKEY"),
+		SecretKey:     types.StringValue("acme-secret-key"),
+		AdoptExisting: types.BoolNull(),
+	})
+	if got.Tenant.ValueString() != "acme" || got.User.ValueString() != "demo" {
+		t.Errorf("got tenant %s and user %s, want acme and demo", got.Tenant, got.User)
+	}
+}
+
+// TestAccKeyResource_parallel creates several generated keys of the same
+// user at once, which Terraform does in parallel.  The new key of each is
+// found by comparing the keys of the user before and after its creation, so
+// without serializing key creation per user the keys get mixed up.
+func TestAccKeyResource_parallel(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	const keys = 10
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + fmt.Sprintf(`
+resource "radosgw_user" "test" {
+  user_id      = "demo"
+  display_name = "Demo user"
+}
+
+resource "radosgw_key" "test" {
+  count = %d
+  user  = radosgw_user.test.user_id
+}
+`, keys),
+				Check: testAccCheckDistinctKeys(server, "demo", keys),
+			},
+		},
+	})
+}
+
+// testAccCheckDistinctKeys checks that the count radosgw_key.test resources
+// hold distinct keys of the user, with their secret keys.
+func testAccCheckDistinctKeys(server *rgwtest.Server, uid string, count int) resource.TestCheckFunc {
+	return testAccCheckUserState(server, uid, func(user admin.User, state *terraform.State) error {
+		secrets := make(map[string]string, len(user.Keys))
+		for _, key := range user.Keys {
+			secrets[key.AccessKey] = key.SecretKey
+		}
+
+		seen := make(map[string]string, count)
+		for i := 0; i < count; i++ {
+			name := fmt.Sprintf("radosgw_key.test.%d", i)
+			rs, ok := state.RootModule().Resources[name]
+			if !ok {
+				return fmt.Errorf("resource %s not found", name)
+			}
+			accessKey := rs.Primary.Attributes["access_key"]
+			if other, ok := seen[accessKey]; ok {
+				return fmt.Errorf("%s and %s hold the same key %q", other, name, accessKey)
+			}
+			seen[accessKey] = name
+			if secret, ok := secrets[accessKey]; !ok || secret != rs.Primary.Attributes["secret_key"] {
+				return fmt.Errorf("%s does not hold the secret key of %q", name, accessKey)
+			}
+		}
+		return nil
+	})
+}
+
+// testAccCheckUserState is like testAccCheckUser, but also passes the
+// Terraform state to check.
+func testAccCheckUserState(server *rgwtest.Server, uid string, check func(admin.User, *terraform.State) error) resource.TestCheckFunc {
+	return func(state *terraform.State) error {
+		user, err := server.API().GetUser(context.Background(), admin.User{ID: uid})
+		if err != nil {
+			return err
+		}
+		return check(user, state)
+	}
+}
+
+// testAccKeyImportID returns the access key of the key resource as import ID.
+func testAccKeyImportID(resourceName string) resource.ImportStateIdFunc {
+	return func(state *terraform.State) (string, error) {
+		rs, ok := state.RootModule().Resources[resourceName]
+		if !ok {
+			return "", fmt.Errorf("resource %s not found", resourceName)
+		}
+		return rs.Primary.Attributes["access_key"], nil
+	}
+}
+
+func TestAccKeyResource_adoptExisting(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck: func() {
+			ctx := context.Background()
+			if _, err := server.API().CreateUser(ctx, admin.User{ID: "demo", DisplayName: "Demo user"}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := server.API().CreateKey(ctx, admin.UserKeySpec{UID: "demo", KeyType: "s3", AccessKey: "DEMOACCESSKEY", SecretKey: "old-secret-key"}); err != nil {
+				t.Fatal(err)
+			}
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "radosgw_key" "test" {
+  user           = "demo"
+  access_key     = "DEMOACCESSKEY"
+  secret_key     = "new-secret-key"
+  adopt_existing = true
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_key.test", "secret_key", "new-secret-key"),
+					testAccCheckUser(server, "demo", func(user admin.User) error {
+						for _, key := range user.Keys {
+							if key.AccessKey == "DEMOACCESSKEY" && key.SecretKey != "new-secret-key" {
+								return fmt.Errorf("secret key was not changed: %+v", key)
+							}
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccKeyResource_adoptExistingFailure(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	config := providerConfig + `
+resource "radosgw_key" "test" {
+  user           = "demo"
+  access_key     = "DEMOACCESSKEY"
+  secret_key     = "new-secret-key"
+  adopt_existing = true
+}
+`
+
+	var restore func()
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck: func() {
+			ctx := context.Background()
+			if _, err := server.API().CreateUser(ctx, admin.User{ID: "demo", DisplayName: "Demo user"}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := server.API().CreateKey(ctx, admin.UserKeySpec{UID: "demo", KeyType: "s3", AccessKey: "DEMOACCESSKEY", SecretKey: "old-secret-key"}); err != nil {
+				t.Fatal(err)
+			}
+		},
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					restore = server.Fail(func(r *http.Request) bool {
+						return r.Method == http.MethodPut && r.URL.Query().Has("key")
+					}, http.StatusInternalServerError, "UnknownError")
+				},
+				Config:      config,
+				ExpectError: regexp.MustCompile(`UnknownError`),
+			},
+			// the failed adoption left the original key alone
+			{
+				PreConfig: func() {
+					restore()
+					user, err := server.API().GetUser(context.Background(), admin.User{ID: "demo"})
+					if err != nil {
+						t.Fatal(err)
+					}
+					for _, key := range user.Keys {
+						if key.AccessKey == "DEMOACCESSKEY" && key.SecretKey == "old-secret-key" {
+							return
+						}
+					}
+					t.Fatalf("original key did not survive: %+v", user.Keys)
+				},
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_key.test", "secret_key", "new-secret-key"),
+				),
+			},
+		},
+	})
+}
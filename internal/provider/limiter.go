@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/time/rate"
+)
+
+// limitedHTTPClient limits the number of concurrent requests and the rate of
+// requests sent to radosgw, shared by all resources and data sources.
+type limitedHTTPClient struct {
+	client admin.HTTPClient
+
+	// slots holds a token for every request in flight, nil if the number
+	// of concurrent requests is not limited.
+	slots chan struct{}
+	// limiter is nil if the request rate is not limited.
+	limiter *rate.Limiter
+}
+
+var _ admin.HTTPClient = &limitedHTTPClient{}
+
+// newLimitedHTTPClient wraps client to send at most maxConcurrent requests
+// at once and at most requestsPerSecond requests per second.  Zero disables
+// the respective limit.
+func newLimitedHTTPClient(client admin.HTTPClient, maxConcurrent int, requestsPerSecond float64) *limitedHTTPClient {
+	c := &limitedHTTPClient{client: client}
+	if maxConcurrent > 0 {
+		c.slots = make(chan struct{}, maxConcurrent)
+	}
+	if requestsPerSecond > 0 {
+		burst := int(math.Max(1, math.Floor(requestsPerSecond)))
+		c.limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+	}
+	return c
+}
+
+// withClient returns a client that sends requests with client, sharing the
+// limits of c.
+func (c *limitedHTTPClient) withClient(client admin.HTTPClient) *limitedHTTPClient {
+	limited := *c
+	limited.client = client
+	return &limited
+}
+
+// Do implements admin.HTTPClient.
+//
+// The concurrency slot is held until the response body is closed.
+func (c *limitedHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.limiter != nil {
+		start := time.Now()
+		if err := c.limiter.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+		if waited := time.Since(start); waited > time.Millisecond {
+			tflog.Debug(ctx, "waited for radosgw request rate limit", map[string]any{
+				"wait": waited.String(),
+			})
+		}
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// acquire waits for a free concurrency slot and returns the function to
+// release it again.
+func (c *limitedHTTPClient) acquire(ctx context.Context) (func(), error) {
+	if c.slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+	default:
+		tflog.Debug(ctx, "waiting for a free radosgw request slot", map[string]any{
+			"max_concurrent_requests": cap(c.slots),
+		})
+		start := time.Now()
+		select {
+		case c.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		tflog.Debug(ctx, "waited for a free radosgw request slot", map[string]any{
+			"wait": time.Since(start).String(),
+		})
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-c.slots })
+	}, nil
+}
+
+// releasingBody calls release when the body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// concurrencyClient records the maximum number of concurrent requests.
+type concurrencyClient struct {
+	current, max atomic.Int32
+}
+
+func (c *concurrencyClient) Do(req *http.Request) (*http.Response, error) {
+	current := c.current.Add(1)
+	for {
+		max := c.max.Load()
+		if current <= max || c.max.CompareAndSwap(max, current) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body: &closeFunc{Reader: strings.NewReader("{}"), close: func() {
+			c.current.Add(-1)
+		}},
+	}, nil
+}
+
+type closeFunc struct {
+	io.Reader
+	close func()
+}
+
+func (c *closeFunc) Close() error {
+	c.close()
+	return nil
+}
+
+func doRequests(t *testing.T, client *limitedHTTPClient, n int) {
+	t.Helper()
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := http.NewRequest(http.MethodGet, "http://radosgw.invalid/admin/user", nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestLimitedHTTPClient_maxConcurrent(t *testing.T) {
+	backend := &concurrencyClient{}
+	client := newLimitedHTTPClient(backend, 3, 0)
+
+	doRequests(t, client, 20)
+
+	if max := backend.max.Load(); max != 3 {
+		t.Errorf("got %d concurrent requests, want 3", max)
+	}
+	if len(client.slots) != 0 {
+		t.Errorf("%d request slots were not released", len(client.slots))
+	}
+}
+
+func TestLimitedHTTPClient_requestsPerSecond(t *testing.T) {
+	backend := &concurrencyClient{}
+	client := newLimitedHTTPClient(backend, 0, 50)
+
+	// the first 50 requests are allowed at once, the next 10 take 200ms
+	start := time.Now()
+	doRequests(t, client, 60)
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("60 requests took %s, want about 200ms", elapsed)
+	}
+}
+
+func TestLimitedHTTPClient_withClient(t *testing.T) {
+	// the admin API and the AWS APIs share the limits
+	backend := &concurrencyClient{}
+	admin := newLimitedHTTPClient(backend, 3, 0)
+	aws := admin.withClient(backend)
+
+	var wg sync.WaitGroup
+	for _, client := range []*limitedHTTPClient{admin, aws} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			doRequests(t, client, 10)
+		}()
+	}
+	wg.Wait()
+
+	if max := backend.max.Load(); max != 3 {
+		t.Errorf("got %d concurrent requests, want 3", max)
+	}
+}
@@ -0,0 +1,44 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+)
+
+// listUsers calls yield for each user with an ID accepted by include, in the
+// order of their IDs, until yield returns false.
+func listUsers(ctx context.Context, client adminClient, include func(uid string) bool, yield func(admin.User) bool) error {
+	uids, err := client.GetUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("listing users: %w", err)
+	}
+	sort.Strings(*uids)
+
+	for _, uid := range *uids {
+		if !include(uid) {
+			continue
+		}
+
+		user, err := client.GetUser(ctx, admin.User{ID: uid})
+		if err != nil {
+			return fmt.Errorf("fetching user %q: %w", uid, err)
+		}
+		if !yield(user) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// listErrorResult returns the result reporting that listing failed with err.
+func listErrorResult(summary string, err error) list.ListResult {
+	var diags diag.Diagnostics
+	addRGWError(&diags, rgwErrorTarget{}, summary, "Could not list objects", err)
+	return list.ListResult{Diagnostics: diags}
+}
@@ -0,0 +1,102 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/querycheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccListResources(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+	testGenerateUsers(t, server)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_14_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig,
+			},
+			{
+				// the provider configuration is taken from the previous step
+				Query: true,
+				Config: `
+list "radosgw_user" "demo" {
+  provider = radosgw
+
+  config {
+    prefix = "de"
+  }
+}
+
+list "radosgw_subuser" "acme" {
+  provider = radosgw
+
+  config {
+    tenant = "acme"
+  }
+}
+
+list "radosgw_key" "demo" {
+  provider = radosgw
+
+  config {
+    user    = "demo"
+    subuser = "readonly"
+  }
+}
+
+list "radosgw_key" "all" {
+  provider = radosgw
+}
+`,
+				QueryResultChecks: []querycheck.QueryResultCheck{
+					// ExpectLength only checks the summary of the list that
+					// completed last, so filters are checked with ExpectNoIdentity
+					querycheck.ExpectIdentity("radosgw_user.demo", map[string]knownvalue.Check{
+						"tenant":  knownvalue.Null(),
+						"user_id": knownvalue.StringExact("demo"),
+					}),
+					querycheck.ExpectNoIdentity("radosgw_user.demo", map[string]knownvalue.Check{
+						"tenant":  knownvalue.Null(),
+						"user_id": knownvalue.StringExact("admin"),
+					}),
+					querycheck.ExpectIdentity("radosgw_subuser.acme", map[string]knownvalue.Check{
+						"tenant":  knownvalue.StringExact("acme"),
+						"user_id": knownvalue.StringExact("other"),
+						"subuser": knownvalue.StringExact("app"),
+					}),
+					querycheck.ExpectNoIdentity("radosgw_subuser.acme", map[string]knownvalue.Check{
+						"tenant":  knownvalue.Null(),
+						"user_id": knownvalue.StringExact("demo"),
+						"subuser": knownvalue.StringExact("readonly"),
+					}),
+					querycheck.ExpectIdentity("radosgw_key.demo", map[string]knownvalue.Check{
+						"tenant":     knownvalue.Null(),
+						"user_id":    knownvalue.StringExact("demo"),
+						"subuser":    knownvalue.StringExact("readonly"),
+						"access_key": knownvalue.StringExact("READONLYKEY"),
+					}),
+					querycheck.ExpectIdentity("radosgw_key.all", map[string]knownvalue.Check{
+						"tenant":     knownvalue.StringExact("acme"),
+						"user_id":    knownvalue.StringExact("other"),
+						"subuser":    knownvalue.StringExact("app"),
+						"access_key": knownvalue.StringExact("APPKEY"),
+					}),
+					// the key of the provider is not listed
+					querycheck.ExpectNoIdentity("radosgw_key.all", map[string]knownvalue.Check{
+						"tenant":     knownvalue.Null(),
+						"user_id":    knownvalue.StringExact("admin"),
+						"subuser":    knownvalue.Null(),
+						"access_key": knownvalue.StringExact(server.AccessKey),
+					}),
+				},
+			},
+		},
+	})
+}
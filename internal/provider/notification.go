@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// metadataHeaderPattern matches the headers of user metadata of objects.
+var metadataHeaderPattern = regexp.MustCompile(`^x-amz-meta-[A-Za-z0-9_.-]+$`)
+
+// The types below are the notification configuration of buckets as
+// radosgw has it, which the AWS SDK lacks the metadata and tag filters of.
+// Their tags follow those of the AWS SDK, which marshals them.
+
+type notificationConfiguration struct {
+	_ struct{} `type:"structure"`
+
+	TopicConfigurations []*topicConfiguration `locationName:"TopicConfiguration" type:"list" flattened:"true"`
+}
+
+type topicConfiguration struct {
+	_ struct{} `type:"structure"`
+
+	ID       *string             `locationName:"Id" type:"string"`
+	TopicArn *string             `locationName:"Topic" type:"string" required:"true"`
+	Events   []*string           `locationName:"Event" type:"list" flattened:"true" required:"true"`
+	Filter   *notificationFilter `type:"structure"`
+}
+
+type notificationFilter struct {
+	_ struct{} `type:"structure"`
+
+	Key      *notificationFilterRules `locationName:"S3Key" type:"structure"`
+	Metadata *notificationFilterRules `locationName:"S3Metadata" type:"structure"`
+	Tags     *notificationFilterRules `locationName:"S3Tags" type:"structure"`
+}
+
+type notificationFilterRules struct {
+	_ struct{} `type:"structure"`
+
+	FilterRules []*s3.FilterRule `locationName:"FilterRule" type:"list" flattened:"true"`
+}
+
+// add returns rules with a rule added, allocating rules if needed.
+func (rules *notificationFilterRules) add(name, value string) *notificationFilterRules {
+	if rules == nil {
+		rules = &notificationFilterRules{}
+	}
+	rules.FilterRules = append(rules.FilterRules, &s3.FilterRule{Name: aws.String(name), Value: aws.String(value)})
+	return rules
+}
+
+// values returns the rules by name, nil if there are none.
+func (rules *notificationFilterRules) values() map[string]string {
+	if rules == nil || len(rules.FilterRules) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(rules.FilterRules))
+	for _, rule := range rules.FilterRules {
+		values[aws.StringValue(rule.Name)] = aws.StringValue(rule.Value)
+	}
+	return values
+}
+
+type putBucketNotificationInput struct {
+	_ struct{} `locationName:"PutBucketNotificationConfigurationRequest" type:"structure" payload:"NotificationConfiguration"`
+
+	Bucket                    *string                    `location:"uri" locationName:"Bucket" type:"string" required:"true"`
+	NotificationConfiguration *notificationConfiguration `locationName:"NotificationConfiguration" type:"structure" required:"true" xmlURI:"http://s3.amazonaws.com/doc/2006-03-01/"`
+}
+
+// getBucketNotification returns the notification configuration of a
+// bucket.  The request of the AWS SDK is sent with the types of the
+// provider, so that the filters of radosgw are kept.
+func getBucketNotification(ctx context.Context, client s3iface.S3API, bucket string) (*notificationConfiguration, error) {
+	req, _ := client.GetBucketNotificationConfigurationRequest(&s3.GetBucketNotificationConfigurationRequest{
+		Bucket: aws.String(bucket),
+	})
+	config := &notificationConfiguration{}
+	req.Data = config
+	req.SetContext(ctx)
+	return config, req.Send()
+}
+
+// putBucketNotification sets the notification configuration of a bucket,
+// like getBucketNotification with the types of the provider.
+func putBucketNotification(ctx context.Context, client s3iface.S3API, bucket string, config *notificationConfiguration) error {
+	req, _ := client.PutBucketNotificationConfigurationRequest(&s3.PutBucketNotificationConfigurationInput{
+		Bucket: aws.String(bucket),
+	})
+	req.Params = &putBucketNotificationInput{
+		Bucket:                    aws.String(bucket),
+		NotificationConfiguration: config,
+	}
+	req.SetContext(ctx)
+	return req.Send()
+}
@@ -0,0 +1,152 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/ceph/go-ceph/rgw/admin"
+)
+
+// rawAdminClient calls the parts of the admin API that go-ceph has no
+// methods for, signing requests the same way.
+type rawAdminClient struct {
+	endpoint    string
+	credentials *credentials.Credentials
+	client      admin.HTTPClient
+}
+
+func newRawAdminClient(endpoint, accessKeyID, secretAccessKey string, client admin.HTTPClient) *rawAdminClient {
+	return &rawAdminClient{
+		endpoint:    strings.TrimSuffix(endpoint, "/"),
+		credentials: credentials.NewStaticCredentials(accessKeyID, secretAccessKey, ""),
+		client:      client,
+	}
+}
+
+// rawAdminError is an error response of the admin API.  Like the errors of
+// go-ceph, it matches the admin.Err* reasons with errors.Is and its message
+// starts with the error code.
+type rawAdminError struct {
+	Code       string `json:"Code"`
+	RequestID  string `json:"RequestId"`
+	StatusCode int    `json:"-"`
+}
+
+func (e rawAdminError) Error() string {
+	return fmt.Sprintf("%s %s (status %d)", e.Code, e.RequestID, e.StatusCode)
+}
+
+// Is reports whether target is the go-ceph error reason of e.
+func (e rawAdminError) Is(target error) bool {
+	return target.Error() == e.Code
+}
+
+// get calls the admin API at path, relative to "/admin", and decodes the
+// JSON response into v.
+func (c *rawAdminClient) get(ctx context.Context, path string, query url.Values, v any) error {
+	u := c.endpoint + "/admin" + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := v4.NewSigner(c.credentials).Sign(req, nil, "s3", "default", time.Now()); err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 300 {
+		apiErr := rawAdminError{StatusCode: resp.StatusCode}
+		if err := json.Unmarshal(body, &apiErr); err != nil || apiErr.Code == "" {
+			apiErr.Code = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("decoding response of %s: %w", path, err)
+	}
+	return nil
+}
+
+// rgwPeriod is the part of a radosgw period, the configuration of the zones
+// of a realm, that the provider needs.
+type rgwPeriod struct {
+	PeriodMap struct {
+		Zonegroups []rgwZonegroup `json:"zonegroups"`
+	} `json:"period_map"`
+}
+
+type rgwZonegroup struct {
+	ID               string               `json:"id"`
+	Name             string               `json:"name"`
+	DefaultPlacement string               `json:"default_placement"`
+	PlacementTargets []rgwPlacementTarget `json:"placement_targets"`
+}
+
+type rgwPlacementTarget struct {
+	Name           string   `json:"name"`
+	StorageClasses []string `json:"storage_classes"`
+}
+
+// getPeriod returns the current period.  It needs the "zone=read" cap.
+func (c *rawAdminClient) getPeriod(ctx context.Context) (rgwPeriod, error) {
+	var period rgwPeriod
+	err := c.get(ctx, "/realm/period", nil, &period)
+	return period, err
+}
+
+// storageClasses returns the storage classes of the placement target
+// placementRule, which is written "<placement>[/<storage class>]" as in
+// the bucket info.  The default placement of the zonegroup is used if
+// placementRule is empty.  As the zonegroup served by the endpoint is not
+// known, the storage classes of the placement target in all zonegroups are
+// returned.
+//
+// It reports false if the placement target is unknown.
+func (p rgwPeriod) storageClasses(placementRule string) ([]string, bool) {
+	placement, _, _ := strings.Cut(placementRule, "/")
+
+	var classes []string
+	var found bool
+	for _, zonegroup := range p.PeriodMap.Zonegroups {
+		name := placement
+		if name == "" {
+			name = zonegroup.DefaultPlacement
+		}
+		for _, target := range zonegroup.PlacementTargets {
+			if target.Name != name {
+				continue
+			}
+			found = true
+			if len(target.StorageClasses) == 0 {
+				// radosgw omits the default storage class in old periods
+				classes = append(classes, "STANDARD")
+			}
+			classes = append(classes, target.StorageClasses...)
+		}
+	}
+
+	return sortedSet(classes), found
+}
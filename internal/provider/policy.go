@@ -0,0 +1,208 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ basetypes.StringTypable                    = policyDocumentType{}
+	_ basetypes.StringValuableWithSemanticEquals = policyDocument{}
+)
+
+// policyDocumentType is the type of attributes holding a JSON policy
+// document.
+type policyDocumentType struct {
+	basetypes.StringType
+}
+
+// Equal implements attr.Type.
+func (t policyDocumentType) Equal(o attr.Type) bool {
+	other, ok := o.(policyDocumentType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+// String implements attr.Type.
+func (t policyDocumentType) String() string {
+	return "policyDocumentType"
+}
+
+// ValueFromString implements basetypes.StringTypable.
+func (t policyDocumentType) ValueFromString(_ context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return policyDocument{StringValue: in}, nil
+}
+
+// ValueFromTerraform implements attr.Type.
+func (t policyDocumentType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+
+	stringValuable, diags := t.ValueFromString(ctx, stringValue)
+	if diags.HasError() {
+		return nil, fmt.Errorf("unexpected error converting StringValue to StringValuable: %v", diags)
+	}
+
+	return stringValuable, nil
+}
+
+// ValueType implements attr.Type.
+func (t policyDocumentType) ValueType(_ context.Context) attr.Value {
+	return policyDocument{}
+}
+
+// policyDocument is a JSON policy document.
+//
+// Documents are semantically equal if they only differ in formatting, the
+// order of keys, statements and list entries, and in using a single value
+// instead of a list with it, so that radosgw returning a policy in a
+// different form is not reported as drift.
+type policyDocument struct {
+	basetypes.StringValue
+}
+
+// newPolicyDocument returns a known policy document.
+func newPolicyDocument(document string) policyDocument {
+	return policyDocument{StringValue: basetypes.NewStringValue(document)}
+}
+
+// Equal implements attr.Value.
+func (v policyDocument) Equal(o attr.Value) bool {
+	other, ok := o.(policyDocument)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+// Type implements attr.Value.
+func (v policyDocument) Type(_ context.Context) attr.Type {
+	return policyDocumentType{}
+}
+
+// StringSemanticEquals implements basetypes.StringValuableWithSemanticEquals.
+func (v policyDocument) StringSemanticEquals(_ context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(policyDocument)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T, got %T. Please report this issue to the provider developers.", v, newValuable),
+		)
+		return false, diags
+	}
+
+	return policiesEquivalent(v.ValueString(), newValue.ValueString()), diags
+}
+
+// policiesEquivalent reports whether the policy documents a and b are
+// semantically equal.  Invalid JSON is only equal to the same string.
+func policiesEquivalent(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	normalizedA, err := normalizePolicy(a)
+	if err != nil {
+		return false
+	}
+	normalizedB, err := normalizePolicy(b)
+	if err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(normalizedA, normalizedB)
+}
+
+// decodePolicy decodes a JSON policy document, keeping numbers as written.
+func decodePolicy(document string) (any, error) {
+	decoder := json.NewDecoder(strings.NewReader(document))
+	decoder.UseNumber()
+
+	var policy any
+	if err := decoder.Decode(&policy); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the policy")
+	}
+
+	return policy, nil
+}
+
+// normalizePolicy decodes a JSON policy document into a form in which
+// semantically equal documents are deeply equal.
+//
+// All lists of the policy grammar are sets, so lists are sorted and
+// deduplicated, and a list with a single value is replaced by that value.
+func normalizePolicy(document string) (any, error) {
+	policy, err := decodePolicy(document)
+	if err != nil {
+		return nil, err
+	}
+	return normalizePolicyValue(policy), nil
+}
+
+func normalizePolicyValue(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for k, v := range value {
+			value[k] = normalizePolicyValue(v)
+		}
+		return value
+	case []any:
+		entries := make(map[string]any, len(value))
+		keys := make([]string, 0, len(value))
+		for _, v := range value {
+			v = normalizePolicyValue(v)
+			key := canonicalJSON(v)
+			if _, ok := entries[key]; !ok {
+				keys = append(keys, key)
+			}
+			entries[key] = v
+		}
+		if len(keys) == 1 {
+			return entries[keys[0]]
+		}
+		sort.Strings(keys)
+		normalized := make([]any, 0, len(keys))
+		for _, key := range keys {
+			normalized = append(normalized, entries[key])
+		}
+		return normalized
+	default:
+		return value
+	}
+}
+
+// canonicalJSON encodes value with sorted keys and without HTML escaping.
+func canonicalJSON(value any) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Sprintf("%#v", value)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,360 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &policyDocumentDataSource{}
+
+func NewPolicyDocumentDataSource() datasource.DataSource {
+	return &policyDocumentDataSource{}
+}
+
+// policyDocumentDataSource renders policy documents for radosgw.  It does
+// not call radosgw, so it needs no client.
+type policyDocumentDataSource struct{}
+
+func (d *policyDocumentDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_policy_document"
+}
+
+func (d *policyDocumentDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	principalsBlock := schema.SetNestedBlock{
+		NestedObject: schema.NestedBlockObject{
+			Attributes: map[string]schema.Attribute{
+				"type": schema.StringAttribute{
+					MarkdownDescription: "Type of the principals, usually `AWS`.  `*` stands for everyone.",
+					Required:            true,
+				},
+				"identifiers": schema.SetAttribute{
+					MarkdownDescription: "ARNs of the principals, or `*`.",
+					ElementType:         types.StringType,
+					Optional:            true,
+				},
+				"users": schema.SetAttribute{
+					MarkdownDescription: "Users and subusers as `[<tenant>$]<user>[:<subuser>]`, like the owner of a key, which are turned into ARNs.  Only for principals of type `AWS`.",
+					ElementType:         types.StringType,
+					Optional:            true,
+				},
+			},
+			Blocks: map[string]schema.Block{
+				"user": schema.ListNestedBlock{
+					MarkdownDescription: "User or subuser which is turned into an ARN, for example from the attributes of a `radosgw_user` or `radosgw_subuser`.  Only for principals of type `AWS`.",
+					NestedObject: schema.NestedBlockObject{
+						Attributes: map[string]schema.Attribute{
+							"tenant": schema.StringAttribute{
+								MarkdownDescription: "Tenant of the user, if any.",
+								Optional:            true,
+							},
+							"user_id": schema.StringAttribute{
+								Required: true,
+							},
+							"subuser": schema.StringAttribute{
+								MarkdownDescription: "Subuser of the user, if the principal is a subuser.",
+								Optional:            true,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Renders a policy document for bucket and IAM policies of radosgw as canonical JSON, turning users and subusers into the ARNs radosgw expects.",
+
+		Attributes: map[string]schema.Attribute{
+			"version": schema.StringAttribute{
+				MarkdownDescription: fmt.Sprintf("Version of the policy language.  Defaults to `%s`.", policyVersions[0]),
+				Optional:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(policyVersions...),
+				},
+			},
+			"policy_id": schema.StringAttribute{
+				MarkdownDescription: "ID of the policy.",
+				Optional:            true,
+			},
+			"json": schema.StringAttribute{
+				MarkdownDescription: "The policy document as JSON, with lists sorted so that it only changes when the policy does.",
+				Computed:            true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"statement": schema.ListNestedBlock{
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"sid": schema.StringAttribute{
+							Optional: true,
+						},
+						"effect": schema.StringAttribute{
+							MarkdownDescription: "Either `Allow` or `Deny`.  Defaults to `Allow`.",
+							Optional:            true,
+							Validators: []validator.String{
+								stringvalidator.OneOf("Allow", "Deny"),
+							},
+						},
+						"actions": schema.SetAttribute{
+							ElementType: types.StringType,
+							Optional:    true,
+						},
+						"not_actions": schema.SetAttribute{
+							ElementType: types.StringType,
+							Optional:    true,
+						},
+						"resources": schema.SetAttribute{
+							ElementType: types.StringType,
+							Optional:    true,
+						},
+						"not_resources": schema.SetAttribute{
+							ElementType: types.StringType,
+							Optional:    true,
+						},
+					},
+					Blocks: map[string]schema.Block{
+						"principals":     principalsBlock,
+						"not_principals": principalsBlock,
+						"condition": schema.SetNestedBlock{
+							NestedObject: schema.NestedBlockObject{
+								Attributes: map[string]schema.Attribute{
+									"test": schema.StringAttribute{
+										MarkdownDescription: "Condition operator, such as `StringEquals`.",
+										Required:            true,
+									},
+									"variable": schema.StringAttribute{
+										MarkdownDescription: "Condition key, such as `s3:prefix`.",
+										Required:            true,
+									},
+									"values": schema.ListAttribute{
+										ElementType: types.StringType,
+										Required:    true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+type policyDocumentDataSourceModel struct {
+	Version    types.String           `tfsdk:"version"`
+	PolicyID   types.String           `tfsdk:"policy_id"`
+	Statements []policyStatementModel `tfsdk:"statement"`
+	JSON       types.String           `tfsdk:"json"`
+}
+
+type policyStatementModel struct {
+	Sid           types.String           `tfsdk:"sid"`
+	Effect        types.String           `tfsdk:"effect"`
+	Actions       []string               `tfsdk:"actions"`
+	NotActions    []string               `tfsdk:"not_actions"`
+	Resources     []string               `tfsdk:"resources"`
+	NotResources  []string               `tfsdk:"not_resources"`
+	Principals    []policyPrincipalModel `tfsdk:"principals"`
+	NotPrincipals []policyPrincipalModel `tfsdk:"not_principals"`
+	Conditions    []policyConditionModel `tfsdk:"condition"`
+}
+
+type policyPrincipalModel struct {
+	Type        types.String               `tfsdk:"type"`
+	Identifiers []string                   `tfsdk:"identifiers"`
+	Users       []string                   `tfsdk:"users"`
+	User        []policyPrincipalUserModel `tfsdk:"user"`
+}
+
+type policyPrincipalUserModel struct {
+	Tenant  types.String `tfsdk:"tenant"`
+	UserID  types.String `tfsdk:"user_id"`
+	Subuser types.String `tfsdk:"subuser"`
+}
+
+type policyConditionModel struct {
+	Test     types.String `tfsdk:"test"`
+	Variable types.String `tfsdk:"variable"`
+	Values   []string     `tfsdk:"values"`
+}
+
+// policyJSON is a policy document, with its elements in the order used by
+// AWS and radosgw.
+type policyJSON struct {
+	Version   string                `json:"Version,omitempty"`
+	ID        string                `json:"Id,omitempty"`
+	Statement []policyStatementJSON `json:"Statement"`
+}
+
+type policyStatementJSON struct {
+	Sid          string                         `json:"Sid,omitempty"`
+	Effect       string                         `json:"Effect"`
+	Principal    any                            `json:"Principal,omitempty"`
+	NotPrincipal any                            `json:"NotPrincipal,omitempty"`
+	Action       []string                       `json:"Action,omitempty"`
+	NotAction    []string                       `json:"NotAction,omitempty"`
+	Resource     []string                       `json:"Resource,omitempty"`
+	NotResource  []string                       `json:"NotResource,omitempty"`
+	Condition    map[string]map[string][]string `json:"Condition,omitempty"`
+}
+
+func (d *policyDocumentDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state policyDocumentDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	policy := policyJSON{
+		Version:   policyVersions[0],
+		ID:        state.PolicyID.ValueString(),
+		Statement: make([]policyStatementJSON, 0, len(state.Statements)),
+	}
+	if !state.Version.IsNull() {
+		policy.Version = state.Version.ValueString()
+	}
+
+	for i, statement := range state.Statements {
+		statementPath := path.Root("statement").AtListIndex(i)
+
+		principal, err := principalJSON(statement.Principals)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(statementPath.AtName("principals"), "Invalid principals", err.Error())
+			continue
+		}
+		notPrincipal, err := principalJSON(statement.NotPrincipals)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(statementPath.AtName("not_principals"), "Invalid principals", err.Error())
+			continue
+		}
+
+		effect := "Allow"
+		if !statement.Effect.IsNull() {
+			effect = statement.Effect.ValueString()
+		}
+
+		policy.Statement = append(policy.Statement, policyStatementJSON{
+			Sid:          statement.Sid.ValueString(),
+			Effect:       effect,
+			Principal:    principal,
+			NotPrincipal: notPrincipal,
+			Action:       sortedSet(statement.Actions),
+			NotAction:    sortedSet(statement.NotActions),
+			Resource:     sortedSet(statement.Resources),
+			NotResource:  sortedSet(statement.NotResources),
+			Condition:    conditionJSON(statement.Conditions),
+		})
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(policy); err != nil {
+		resp.Diagnostics.AddError("Unable to render policy", err.Error())
+		return
+	}
+	state.JSON = types.StringValue(buf.String())
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// principalJSON returns the Principal or NotPrincipal element of the
+// principals blocks of a statement, nil if there are none.
+func principalJSON(principals []policyPrincipalModel) (any, error) {
+	if len(principals) == 0 {
+		return nil, nil
+	}
+
+	byType := make(map[string][]string)
+	for _, principal := range principals {
+		principalType := principal.Type.ValueString()
+		if principalType == "*" {
+			if len(principals) > 1 || len(principal.Users) > 0 || len(principal.User) > 0 {
+				return nil, fmt.Errorf(`principals of type "*" stand for everyone and cannot be combined with other principals`)
+			}
+			return "*", nil
+		}
+
+		identifiers := append([]string(nil), principal.Identifiers...)
+		if (len(principal.Users) > 0 || len(principal.User) > 0) && principalType != "AWS" {
+			return nil, fmt.Errorf(`users and user can only be used with principals of type "AWS", not %q`, principalType)
+		}
+		for _, user := range principal.Users {
+			ref := parseUserRef(user)
+			if err := validateUserRef(ref); err != nil {
+				return nil, fmt.Errorf("invalid user %q: %w", user, err)
+			}
+			identifiers = append(identifiers, ref.arn())
+		}
+		for _, user := range principal.User {
+			ref := userRef{Tenant: user.Tenant.ValueString(), UserID: user.UserID.ValueString(), Subuser: user.Subuser.ValueString()}
+			if err := validateUserRef(ref); err != nil {
+				return nil, fmt.Errorf("invalid user %q: %w", ref, err)
+			}
+			identifiers = append(identifiers, ref.arn())
+		}
+
+		byType[principalType] = append(byType[principalType], identifiers...)
+	}
+
+	for principalType, identifiers := range byType {
+		byType[principalType] = sortedSet(identifiers)
+	}
+	return byType, nil
+}
+
+// conditionJSON returns the Condition element of the condition blocks of a
+// statement.  Values of blocks with the same test and variable are merged.
+func conditionJSON(conditions []policyConditionModel) map[string]map[string][]string {
+	if len(conditions) == 0 {
+		return nil
+	}
+
+	condition := make(map[string]map[string][]string)
+	for _, c := range conditions {
+		test, variable := c.Test.ValueString(), c.Variable.ValueString()
+		if condition[test] == nil {
+			condition[test] = make(map[string][]string)
+		}
+		condition[test][variable] = append(condition[test][variable], c.Values...)
+	}
+	for _, variables := range condition {
+		for variable, values := range variables {
+			variables[variable] = sortedSet(values)
+		}
+	}
+	return condition
+}
+
+// sortedSet returns the distinct values sorted, nil if there are none.
+func sortedSet(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(values))
+	set := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			set = append(set, v)
+		}
+	}
+	sort.Strings(set)
+	return set
+}
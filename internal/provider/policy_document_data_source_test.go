@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccPolicyDocumentDataSource(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck:                 func() { testAccBucket(t, server) },
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + testAccPolicyDocumentDataSourceConfig,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.radosgw_policy_document.test", "json", testAccPolicyDocumentJSON),
+					testAccCheckBucketSubresource(server, "assets", "policy", regexp.MustCompile(`"arn:aws:iam::acme:user/deploy"`)),
+				),
+			},
+		},
+	})
+}
+
+func TestAccPolicyDocumentDataSource_invalidUser(t *testing.T) {
+	_, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+data "radosgw_policy_document" "test" {
+  statement {
+    actions = ["s3:GetObject"]
+    principals {
+      type  = "Federated"
+      users = ["demo"]
+    }
+  }
+}
+`,
+				ExpectError: regexp.MustCompile(`users and user can only be used with principals of type "AWS"`),
+			},
+		},
+	})
+}
+
+const testAccPolicyDocumentDataSourceConfig = `
+resource "radosgw_user" "deploy" {
+  tenant       = "acme"
+  user_id      = "deploy"
+  display_name = "Deploy"
+}
+
+resource "radosgw_subuser" "readonly" {
+  user_id = "demo"
+  subuser = "readonly"
+  access  = "read"
+}
+
+data "radosgw_policy_document" "test" {
+  statement {
+    sid       = "read"
+    actions   = ["s3:ListBucket", "s3:GetObject"]
+    resources = ["arn:aws:s3:::assets/*", "arn:aws:s3:::assets"]
+
+    principals {
+      type  = "AWS"
+      users = ["demo"]
+
+      user {
+        tenant  = radosgw_subuser.readonly.tenant
+        user_id = radosgw_subuser.readonly.user_id
+        subuser = radosgw_subuser.readonly.subuser
+      }
+    }
+
+    condition {
+      test     = "StringLike"
+      variable = "s3:prefix"
+      values   = ["public/"]
+    }
+  }
+
+  statement {
+    effect    = "Deny"
+    actions   = ["s3:DeleteObject"]
+    resources = ["arn:aws:s3:::assets/*"]
+
+    not_principals {
+      type = "AWS"
+
+      user {
+        tenant  = radosgw_user.deploy.tenant
+        user_id = radosgw_user.deploy.user_id
+      }
+    }
+  }
+}
+
+resource "radosgw_bucket_policy" "test" {
+  bucket = "assets"
+  policy = data.radosgw_policy_document.test.json
+}
+`
+
+const testAccPolicyDocumentJSON = `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Sid": "read",
+      "Effect": "Allow",
+      "Principal": {
+        "AWS": [
+          "arn:aws:iam:::user/demo",
+          "arn:aws:iam:::user/demo:readonly"
+        ]
+      },
+      "Action": [
+        "s3:GetObject",
+        "s3:ListBucket"
+      ],
+      "Resource": [
+        "arn:aws:s3:::assets",
+        "arn:aws:s3:::assets/*"
+      ],
+      "Condition": {
+        "StringLike": {
+          "s3:prefix": [
+            "public/"
+          ]
+        }
+      }
+    },
+    {
+      "Effect": "Deny",
+      "NotPrincipal": {
+        "AWS": [
+          "arn:aws:iam::acme:user/deploy"
+        ]
+      },
+      "Action": [
+        "s3:DeleteObject"
+      ],
+      "Resource": [
+        "arn:aws:s3:::assets/*"
+      ]
+    }
+  ]
+}
+`
@@ -0,0 +1,598 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// s3PolicyActions are the S3 actions radosgw supports in policies.
+var s3PolicyActions = []string{
+	"s3:AbortMultipartUpload",
+	"s3:BypassGovernanceRetention",
+	"s3:CreateBucket",
+	"s3:DeleteBucket",
+	"s3:DeleteBucketEncryption",
+	"s3:DeleteBucketOwnershipControls",
+	"s3:DeleteBucketPolicy",
+	"s3:DeleteBucketPublicAccessBlock",
+	"s3:DeleteBucketWebsite",
+	"s3:DeleteObject",
+	"s3:DeleteObjectTagging",
+	"s3:DeleteObjectVersion",
+	"s3:DeleteObjectVersionTagging",
+	"s3:DeleteReplicationConfiguration",
+	"s3:GetAccelerateConfiguration",
+	"s3:GetBucketAcl",
+	"s3:GetBucketCORS",
+	"s3:GetBucketEncryption",
+	"s3:GetBucketLocation",
+	"s3:GetBucketLogging",
+	"s3:GetBucketNotification",
+	"s3:GetBucketObjectLockConfiguration",
+	"s3:GetBucketOwnershipControls",
+	"s3:GetBucketPolicy",
+	"s3:GetBucketPolicyStatus",
+	"s3:GetBucketPublicAccessBlock",
+	"s3:GetBucketRequestPayment",
+	"s3:GetBucketTagging",
+	"s3:GetBucketVersioning",
+	"s3:GetBucketWebsite",
+	"s3:GetLifecycleConfiguration",
+	"s3:GetObject",
+	"s3:GetObjectAcl",
+	"s3:GetObjectAttributes",
+	"s3:GetObjectLegalHold",
+	"s3:GetObjectRetention",
+	"s3:GetObjectTagging",
+	"s3:GetObjectTorrent",
+	"s3:GetObjectVersion",
+	"s3:GetObjectVersionAcl",
+	"s3:GetObjectVersionAttributes",
+	"s3:GetObjectVersionForReplication",
+	"s3:GetObjectVersionTagging",
+	"s3:GetObjectVersionTorrent",
+	"s3:GetReplicationConfiguration",
+	"s3:ListAllMyBuckets",
+	"s3:ListBucket",
+	"s3:ListBucketMultipartUploads",
+	"s3:ListBucketVersions",
+	"s3:ListMultipartUploadParts",
+	"s3:PutAccelerateConfiguration",
+	"s3:PutBucketAcl",
+	"s3:PutBucketCORS",
+	"s3:PutBucketEncryption",
+	"s3:PutBucketLogging",
+	"s3:PutBucketNotification",
+	"s3:PutBucketObjectLockConfiguration",
+	"s3:PutBucketOwnershipControls",
+	"s3:PutBucketPolicy",
+	"s3:PutBucketPublicAccessBlock",
+	"s3:PutBucketRequestPayment",
+	"s3:PutBucketTagging",
+	"s3:PutBucketVersioning",
+	"s3:PutBucketWebsite",
+	"s3:PutLifecycleConfiguration",
+	"s3:PutObject",
+	"s3:PutObjectAcl",
+	"s3:PutObjectLegalHold",
+	"s3:PutObjectRetention",
+	"s3:PutObjectTagging",
+	"s3:PutObjectVersionAcl",
+	"s3:PutObjectVersionTagging",
+	"s3:PutReplicationConfiguration",
+	"s3:RestoreObject",
+}
+
+// policyConditionKeys are the condition keys radosgw evaluates in policies.
+// Keys ending in "/" take a tag key or name as suffix.
+var policyConditionKeys = []string{
+	"aws:CurrentTime",
+	"aws:EpochTime",
+	"aws:PrincipalTag/",
+	"aws:PrincipalType",
+	"aws:Referer",
+	"aws:RequestTag/",
+	"aws:ResourceTag/",
+	"aws:SecureTransport",
+	"aws:SourceIp",
+	"aws:TagKeys",
+	"aws:UserAgent",
+	"aws:username",
+	"s3:ExistingObjectTag/",
+	"s3:LocationConstraint",
+	"s3:RequestObjectTag/",
+	"s3:RequestObjectTagKeys",
+	"s3:VersionId",
+	"s3:delimiter",
+	"s3:max-keys",
+	"s3:object-lock-legal-hold",
+	"s3:object-lock-mode",
+	"s3:object-lock-remaining-retention-days",
+	"s3:object-lock-retain-until-date",
+	"s3:prefix",
+	"s3:x-amz-acl",
+	"s3:x-amz-copy-source",
+	"s3:x-amz-grant-full-control",
+	"s3:x-amz-grant-read",
+	"s3:x-amz-grant-read-acp",
+	"s3:x-amz-grant-write",
+	"s3:x-amz-grant-write-acp",
+	"s3:x-amz-metadata-directive",
+	"s3:x-amz-server-side-encryption",
+	"s3:x-amz-server-side-encryption-aws-kms-key-id",
+	"s3:x-amz-storage-class",
+}
+
+// policyConditionOperators are the condition operators radosgw supports,
+// without the "ForAnyValue:" and "ForAllValues:" qualifiers and the
+// "IfExists" suffix.
+var policyConditionOperators = []string{
+	"ArnEquals", "ArnLike", "ArnNotEquals", "ArnNotLike",
+	"BinaryEquals",
+	"Bool",
+	"DateEquals", "DateGreaterThan", "DateGreaterThanEquals", "DateLessThan", "DateLessThanEquals", "DateNotEquals",
+	"IpAddress", "NotIpAddress",
+	"Null",
+	"NumericEquals", "NumericGreaterThan", "NumericGreaterThanEquals", "NumericLessThan", "NumericLessThanEquals", "NumericNotEquals",
+	"StringEquals", "StringEqualsIgnoreCase", "StringLike", "StringNotEquals", "StringNotEqualsIgnoreCase", "StringNotLike",
+}
+
+// stsPolicyActions are the STS actions radosgw supports in policies.
+var stsPolicyActions = []string{
+	"sts:AssumeRole",
+	"sts:AssumeRoleWithWebIdentity",
+	"sts:GetSessionToken",
+	"sts:TagSession",
+}
+
+// snsPolicyActions are the SNS actions radosgw supports in policies.
+var snsPolicyActions = []string{
+	"sns:CreateTopic",
+	"sns:DeleteTopic",
+	"sns:GetTopicAttributes",
+	"sns:ListTopics",
+	"sns:Publish",
+	"sns:SetTopicAttributes",
+}
+
+// iamPolicyActions are the IAM actions radosgw supports in policies.
+var iamPolicyActions = []string{
+	"iam:CreateRole",
+	"iam:DeleteRole",
+	"iam:DeleteRolePolicy",
+	"iam:DeleteUserPolicy",
+	"iam:GetRole",
+	"iam:GetRolePolicy",
+	"iam:GetUserPolicy",
+	"iam:ListRolePolicies",
+	"iam:ListRoles",
+	"iam:ListUserPolicies",
+	"iam:PutRolePolicy",
+	"iam:PutUserPolicy",
+	"iam:UpdateAssumeRolePolicy",
+	"iam:UpdateRole",
+}
+
+// webIdentityConditionKeyPattern matches the condition keys of trust
+// policies on the claims of web identity tokens, written as the URL of the
+// OpenID Connect provider without scheme followed by the claim, such as
+// "sso.example.com/realms/demo:sub".
+var webIdentityConditionKeyPattern = regexp.MustCompile(`^\S+:(sub|aud|app_id|azp|amr)$`)
+
+// policyVersions are the policy language versions radosgw understands.
+var policyVersions = []string{"2012-10-17", "2008-10-17"}
+
+// principalARNPattern matches the principals radosgw understands, users as
+// "arn:aws:iam::<tenant>:user/<user>", also with ":<subuser>", roles as
+// "arn:aws:iam::<tenant>:role/<role>" and all users of a tenant as
+// "arn:aws:iam::<tenant>:root".  The tenant is empty for users without one.
+var principalARNPattern = regexp.MustCompile(`^arn:aws:iam::[^:/]*:(root|user/[^$/]+|role/.+)$`)
+
+// federatedPrincipalARNPattern matches the OpenID Connect providers of
+// federated principals, "arn:aws:iam::<tenant>:oidc-provider/<url>" with the
+// URL of the provider without scheme.
+var federatedPrincipalARNPattern = regexp.MustCompile(`^arn:aws:iam::[^:/]*:oidc-provider/\S+$`)
+
+// policyRules describes what radosgw supports in a kind of policy.
+type policyRules struct {
+	// name is the kind of policy, such as "bucket policy".
+	name string
+
+	// actions are the supported actions.
+	actions []string
+
+	// principals is whether statements need a Principal, or must not have
+	// one, and principalTypes are the supported types of principals.
+	principals     bool
+	principalTypes []string
+
+	// resourcePrefixes are the prefixes of the resources the policy can
+	// apply to.  Statements need a Resource if it is set, and must not have
+	// one otherwise.
+	resourcePrefixes []string
+
+	// conditionKeyPattern, if set, matches the condition keys radosgw
+	// evaluates in the policy besides policyConditionKeys.
+	conditionKeyPattern *regexp.Regexp
+}
+
+// bucketPolicyRules are the rules of bucket policies.
+var bucketPolicyRules = policyRules{
+	name:             "bucket policy",
+	actions:          s3PolicyActions,
+	principals:       true,
+	principalTypes:   []string{"AWS"},
+	resourcePrefixes: []string{"arn:aws:s3:"},
+}
+
+// trustPolicyRules are the rules of the trust policies of roles, which
+// allow principals to assume the role.
+var trustPolicyRules = policyRules{
+	name:                "trust policy",
+	actions:             stsPolicyActions,
+	principals:          true,
+	principalTypes:      []string{"AWS", "Federated"},
+	conditionKeyPattern: webIdentityConditionKeyPattern,
+}
+
+// rolePolicyRules are the rules of the permission policies of roles.
+var rolePolicyRules = policyRules{
+	name:             "role policy",
+	actions:          concatStrings(s3PolicyActions, snsPolicyActions, stsPolicyActions),
+	resourcePrefixes: []string{"arn:aws:s3:", "arn:aws:sns:", "arn:aws:iam:"},
+}
+
+// userPolicyRules are the rules of the inline policies of users, which
+// radosgw evaluates for requests of the user besides bucket policies.
+var userPolicyRules = policyRules{
+	name:             "user policy",
+	actions:          concatStrings(s3PolicyActions, snsPolicyActions, stsPolicyActions, iamPolicyActions),
+	resourcePrefixes: []string{"arn:aws:s3:", "arn:aws:sns:", "arn:aws:iam:"},
+}
+
+// validate returns the problems of a policy document that radosgw would
+// reject or ignore.
+func (rules policyRules) validate(document string) []string {
+	policy, err := decodePolicy(document)
+	if err != nil {
+		return []string{fmt.Sprintf("The policy is not valid JSON: %s.", err)}
+	}
+
+	object, ok := policy.(map[string]any)
+	if !ok {
+		return []string{"The policy must be a JSON object."}
+	}
+
+	var problems []string
+	for key, value := range object {
+		switch key {
+		case "Version":
+			version, _ := value.(string)
+			if !containsFold(policyVersions, version) {
+				problems = append(problems, fmt.Sprintf("Version %s is not supported, use %q.", canonicalJSON(value), policyVersions[0]))
+			}
+		case "Id", "Statement":
+		default:
+			problems = append(problems, fmt.Sprintf("Unknown policy element %q.", key))
+		}
+	}
+
+	statements, ok := object["Statement"].([]any)
+	if !ok && object["Statement"] != nil {
+		statements = []any{object["Statement"]}
+	}
+	if len(statements) == 0 {
+		problems = append(problems, "The policy has no statements.")
+	}
+	sort.Strings(problems)
+
+	for i, statement := range statements {
+		for _, problem := range rules.validateStatement(statement) {
+			problems = append(problems, fmt.Sprintf("Statement %d: %s", i+1, problem))
+		}
+	}
+
+	return problems
+}
+
+// validateStatement returns the problems of a policy statement.
+func (rules policyRules) validateStatement(value any) []string {
+	statement, ok := value.(map[string]any)
+	if !ok {
+		return []string{"statements must be JSON objects."}
+	}
+
+	var problems []string
+	for key := range statement {
+		switch key {
+		case "Sid", "Effect", "Principal", "NotPrincipal", "Action", "NotAction", "Resource", "NotResource", "Condition":
+		default:
+			problems = append(problems, fmt.Sprintf("unknown statement element %q.", key))
+		}
+	}
+
+	if effect, _ := statement["Effect"].(string); effect != "Allow" && effect != "Deny" {
+		problems = append(problems, `Effect must be "Allow" or "Deny".`)
+	}
+
+	problems = append(problems, rules.validatePrincipals(statement)...)
+
+	actionKey, actions, problem := exclusiveElement(statement, "Action", "NotAction", true)
+	if problem != "" {
+		problems = append(problems, problem)
+	}
+	for _, action := range actions {
+		if !rules.supportsAction(action) {
+			problems = append(problems, fmt.Sprintf("%s %q is not supported by radosgw in a %s.", actionKey, action, rules.name))
+		}
+	}
+
+	resourceKey, resources, problem := exclusiveElement(statement, "Resource", "NotResource", len(rules.resourcePrefixes) > 0)
+	if problem != "" {
+		problems = append(problems, problem)
+	}
+	if len(resources) > 0 && len(rules.resourcePrefixes) == 0 {
+		problems = append(problems, fmt.Sprintf("%s is not allowed in a %s, it applies to the role it is attached to.", resourceKey, rules.name))
+		resources = nil
+	}
+	for _, resource := range resources {
+		if resource != "*" && !hasAnyPrefix(resource, rules.resourcePrefixes) {
+			problems = append(problems, fmt.Sprintf("%s %q must be \"*\" or an ARN starting with %s.", resourceKey, resource, strings.Join(quoteAll(rules.resourcePrefixes), " or ")))
+		}
+	}
+
+	if condition, ok := statement["Condition"]; ok {
+		problems = append(problems, rules.validateCondition(condition)...)
+	}
+
+	sort.Strings(problems)
+	return problems
+}
+
+// validatePrincipals returns the problems of the principals of a statement.
+func (rules policyRules) validatePrincipals(statement map[string]any) []string {
+	key, value, problem := exclusiveElementValue(statement, "Principal", "NotPrincipal", rules.principals)
+	if problem != "" {
+		return []string{problem}
+	}
+	if value == nil {
+		return nil
+	}
+	if !rules.principals {
+		return []string{fmt.Sprintf("%s is not allowed in a %s, it applies to the user or role it is attached to.", key, rules.name)}
+	}
+	if value == "*" {
+		return nil
+	}
+
+	principals, ok := value.(map[string]any)
+	if !ok {
+		return []string{fmt.Sprintf(`%s must be "*" or an object like {"AWS": [...]}.`, key)}
+	}
+
+	var problems []string
+	for principalType, ids := range principals {
+		if !containsFold(rules.principalTypes, principalType) {
+			problems = append(problems, fmt.Sprintf("%s type %q is not supported by radosgw in a %s, use %s.", key, principalType, rules.name, strings.Join(quoteAll(rules.principalTypes), " or ")))
+			continue
+		}
+		values, ok := stringList(ids)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s %q must be a string or a list of strings.", key, principalType))
+			continue
+		}
+		if principalType == "Federated" {
+			for _, id := range values {
+				if !federatedPrincipalARNPattern.MatchString(id) {
+					problems = append(problems, fmt.Sprintf(`%s %q must be an ARN like "arn:aws:iam::<tenant>:oidc-provider/<url>", with the URL of the OpenID Connect provider without scheme.`, key, id))
+				}
+			}
+		}
+		if principalType != "AWS" {
+			continue
+		}
+		for _, id := range values {
+			if id != "*" && !principalARNPattern.MatchString(id) {
+				problems = append(problems, fmt.Sprintf(`%s %q must be "*" or an ARN like "arn:aws:iam::<tenant>:user/<user>", with an empty tenant for users without one.`, key, id))
+			}
+		}
+	}
+	return problems
+}
+
+// supportsAction reports whether the action, which may contain wildcards,
+// matches an action radosgw supports.  Actions are case-insensitive.
+func (rules policyRules) supportsAction(action string) bool {
+	if action == "*" {
+		return true
+	}
+
+	pattern := strings.ToLower(action)
+	for _, supported := range rules.actions {
+		if matched, _ := path.Match(pattern, strings.ToLower(supported)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCondition returns the problems of the Condition of a statement.
+func (rules policyRules) validateCondition(value any) []string {
+	condition, ok := value.(map[string]any)
+	if !ok {
+		return []string{"Condition must be a JSON object."}
+	}
+
+	var problems []string
+	for operator, value := range condition {
+		if !supportsConditionOperator(operator) {
+			problems = append(problems, fmt.Sprintf("condition operator %q is not supported by radosgw.", operator))
+		}
+
+		keys, ok := value.(map[string]any)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("condition %q must map condition keys to values.", operator))
+			continue
+		}
+		for key := range keys {
+			if !supportsConditionKey(key) && (rules.conditionKeyPattern == nil || !rules.conditionKeyPattern.MatchString(key)) {
+				problems = append(problems, fmt.Sprintf("condition key %q is not supported by radosgw.", key))
+			}
+		}
+	}
+	return problems
+}
+
+// supportsConditionOperator reports whether radosgw supports the condition
+// operator.
+func supportsConditionOperator(operator string) bool {
+	operator = strings.TrimPrefix(operator, "ForAnyValue:")
+	operator = strings.TrimPrefix(operator, "ForAllValues:")
+	operator = strings.TrimSuffix(operator, "IfExists")
+	return containsFold(policyConditionOperators, operator)
+}
+
+// supportsConditionKey reports whether radosgw evaluates the condition key.
+// Condition keys are case-insensitive.
+func supportsConditionKey(key string) bool {
+	for _, supported := range policyConditionKeys {
+		if strings.HasSuffix(supported, "/") {
+			if len(key) > len(supported) && strings.EqualFold(key[:len(supported)], supported) {
+				return true
+			}
+		} else if strings.EqualFold(key, supported) {
+			return true
+		}
+	}
+	return false
+}
+
+// exclusiveElement returns which of the statement elements key and notKey
+// is set and its values, which must be strings.  It returns a problem if
+// both are set, if neither is but one is required, or if the values are not
+// strings.
+func exclusiveElement(statement map[string]any, key, notKey string, required bool) (string, []string, string) {
+	setKey, value, problem := exclusiveElementValue(statement, key, notKey, required)
+	if problem != "" || value == nil {
+		return setKey, nil, problem
+	}
+
+	values, ok := stringList(value)
+	if !ok {
+		return setKey, nil, fmt.Sprintf("%s must be a string or a list of strings.", setKey)
+	}
+	return setKey, values, ""
+}
+
+// exclusiveElementValue is like exclusiveElement, but returns the value
+// without checking it.
+func exclusiveElementValue(statement map[string]any, key, notKey string, required bool) (string, any, string) {
+	value, hasKey := statement[key]
+	notValue, hasNotKey := statement[notKey]
+	switch {
+	case hasKey && hasNotKey:
+		return key, nil, fmt.Sprintf("only one of %s and %s can be set.", key, notKey)
+	case hasNotKey:
+		return notKey, notValue, ""
+	case !hasKey && required:
+		return key, nil, fmt.Sprintf("%s or %s is required.", key, notKey)
+	}
+	return key, value, ""
+}
+
+// stringList returns the strings of a policy element that is a string or a
+// list of strings.
+func stringList(value any) ([]string, bool) {
+	switch value := value.(type) {
+	case string:
+		return []string{value}, true
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			s, ok := v.(string)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, s)
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// concatStrings returns the values of all lists in one list.
+func concatStrings(lists ...[]string) []string {
+	var values []string
+	for _, list := range lists {
+		values = append(values, list...)
+	}
+	return values
+}
+
+func quoteAll(values []string) []string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, fmt.Sprintf("%q", v))
+	}
+	return quoted
+}
+
+// policyValidator validates policy documents against the rules of radosgw
+// at plan time.
+type policyValidator struct {
+	rules policyRules
+}
+
+var _ validator.String = policyValidator{}
+
+// Description implements validator.Describer.
+func (v policyValidator) Description(_ context.Context) string {
+	return fmt.Sprintf("must be a %s supported by radosgw", v.rules.name)
+}
+
+// MarkdownDescription implements validator.Describer.
+func (v policyValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString implements validator.String.
+func (v policyValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	problems := v.rules.validate(req.ConfigValue.ValueString())
+	if len(problems) == 0 {
+		return
+	}
+
+	resp.Diagnostics.AddAttributeError(
+		req.Path,
+		fmt.Sprintf("Invalid %s", v.rules.name),
+		fmt.Sprintf("The %s is not supported by radosgw:\n\n  - %s", v.rules.name, strings.Join(problems, "\n  - ")),
+	)
+}
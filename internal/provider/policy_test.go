@@ -0,0 +1,173 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPoliciesEquivalent(t *testing.T) {
+	const policy = `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Principal": {"AWS": ["arn:aws:iam:::user/demo", "arn:aws:iam::acme:user/other"]},
+      "Action": ["s3:GetObject", "s3:ListBucket"],
+      "Resource": ["arn:aws:s3:::assets", "arn:aws:s3:::assets/*"]
+    },
+    {
+      "Effect": "Deny",
+      "Principal": "*",
+      "Action": "s3:DeleteObject",
+      "Resource": "arn:aws:s3:::assets/*"
+    }
+  ]
+}`
+
+	tests := []struct {
+		name  string
+		other string
+		want  bool
+	}{
+		{"reformatted", strings.Join(strings.Fields(policy), ""), true},
+		{"reordered", `{"Statement":[{"Resource":"arn:aws:s3:::assets/*","Action":["s3:DeleteObject"],"Principal":"*","Effect":"Deny"},{"Action":["s3:ListBucket","s3:GetObject","s3:GetObject"],"Effect":"Allow","Principal":{"AWS":["arn:aws:iam::acme:user/other","arn:aws:iam:::user/demo"]},"Resource":["arn:aws:s3:::assets/*","arn:aws:s3:::assets"]}],"Version":"2012-10-17"}`, true},
+		{"different action", strings.Replace(policy, "s3:DeleteObject", "s3:PutObject", 1), false},
+		{"different effect", strings.Replace(policy, "Deny", "Allow", 1), false},
+		{"invalid", `{"Version":`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policiesEquivalent(policy, tt.other); got != tt.want {
+				t.Errorf("got %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyRulesValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		want   []string
+	}{
+		{
+			name:   "valid",
+			policy: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["arn:aws:iam::acme:user/demo","arn:aws:iam:::user/demo:readonly"]},"Action":["s3:Get*","s3:listbucket"],"Resource":"arn:aws:s3:::assets/*","Condition":{"StringLike":{"s3:prefix":"public/"},"ForAnyValue:StringEqualsIfExists":{"s3:ExistingObjectTag/team":"ops"}}}]}`,
+		},
+		{
+			name:   "invalid JSON",
+			policy: `{"Statement":`,
+			want:   []string{"The policy is not valid JSON"},
+		},
+		{
+			name:   "unsupported action",
+			policy: `{"Statement":{"Effect":"Allow","Principal":"*","Action":["s3:GetObject","s3:PutBucketInventoryConfiguration","s3:Frobnicate*"],"Resource":"*"}}`,
+			want: []string{
+				`Statement 1: Action "s3:Frobnicate*" is not supported by radosgw in a bucket policy.`,
+				`Statement 1: Action "s3:PutBucketInventoryConfiguration" is not supported by radosgw in a bucket policy.`,
+			},
+		},
+		{
+			name:   "plain user principal",
+			policy: `{"Statement":[{"Effect":"Allow","Principal":{"AWS":"demo"},"Action":"s3:GetObject","Resource":"arn:aws:s3:::assets/*"}]}`,
+			want:   []string{`Statement 1: Principal "demo" must be "*" or an ARN like "arn:aws:iam::<tenant>:user/<user>"`},
+		},
+		{
+			name:   "unsupported condition",
+			policy: `{"Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::assets/*","Condition":{"StringEquals":{"aws:SourceVpce":"vpce-1"},"IpAddressMatches":{"aws:SourceIp":"10.0.0.0/8"}}}]}`,
+			want: []string{
+				`Statement 1: condition key "aws:SourceVpce" is not supported by radosgw.`,
+				`Statement 1: condition operator "IpAddressMatches" is not supported by radosgw.`,
+			},
+		},
+		{
+			name:   "missing elements",
+			policy: `{"Version":"2000-01-01","Statement":[{"Effect":"Permit"}]}`,
+			want: []string{
+				`Version "2000-01-01" is not supported`,
+				`Statement 1: Action or NotAction is required.`,
+				`Statement 1: Effect must be "Allow" or "Deny".`,
+				`Statement 1: Principal or NotPrincipal is required.`,
+				`Statement 1: Resource or NotResource is required.`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bucketPolicyRules.validate(tt.policy)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got problems %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if !strings.HasPrefix(got[i], tt.want[i]) {
+					t.Errorf("got problem %q, want %q", got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRolePolicyRulesValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		rules  policyRules
+		policy string
+		want   []string
+	}{
+		{
+			name:   "web identity trust policy",
+			rules:  trustPolicyRules,
+			policy: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Federated":["arn:aws:iam:::oidc-provider/sso.example.com/realms/demo"]},"Action":["sts:AssumeRoleWithWebIdentity"],"Condition":{"StringEquals":{"sso.example.com/realms/demo:sub":"system:serviceaccount:apps:web"}}}]}`,
+		},
+		{
+			name:   "trust policy with resource",
+			rules:  trustPolicyRules,
+			policy: `{"Statement":[{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam:::user/demo","Federated":"sso.example.com"},"Action":"s3:GetObject","Resource":"*"}]}`,
+			want: []string{
+				`Statement 1: Action "s3:GetObject" is not supported by radosgw in a trust policy.`,
+				`Statement 1: Principal "sso.example.com" must be an ARN like "arn:aws:iam::<tenant>:oidc-provider/<url>"`,
+				`Statement 1: Resource is not allowed in a trust policy`,
+			},
+		},
+		{
+			name:   "role policy",
+			rules:  rolePolicyRules,
+			policy: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject","sns:Publish"],"Resource":["arn:aws:s3:::assets/*","arn:aws:sns:default::events"]}]}`,
+		},
+		{
+			name:   "role policy with principal",
+			rules:  rolePolicyRules,
+			policy: `{"Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::assets/*","Condition":{"StringEquals":{"sso.example.com:sub":"web"}}}]}`,
+			want: []string{
+				`Statement 1: Principal is not allowed in a role policy`,
+				`Statement 1: condition key "sso.example.com:sub" is not supported by radosgw.`,
+			},
+		},
+		{
+			name:   "user policy",
+			rules:  userPolicyRules,
+			policy: `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Action":["s3:Delete*","iam:*UserPolicy"],"Resource":"*"}]}`,
+		},
+		{
+			name:   "user policy with unsupported action",
+			rules:  userPolicyRules,
+			policy: `{"Statement":[{"Effect":"Allow","Action":"iam:CreateUser","Resource":"arn:aws:iam:::user/demo"}]}`,
+			want: []string{
+				`Statement 1: Action "iam:CreateUser" is not supported by radosgw in a user policy.`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.rules.validate(tt.policy)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got problems %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if !strings.HasPrefix(got[i], tt.want[i]) {
+					t.Errorf("got problem %q, want %q", got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -8,11 +8,15 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/list"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 
@@ -20,7 +24,10 @@ import (
 )
 
 // Ensure RadosgwProvider satisfies various provider interfaces.
-var _ provider.Provider = &radosgwProvider{}
+var (
+	_ provider.Provider                  = &radosgwProvider{}
+	_ provider.ProviderWithListResources = &radosgwProvider{}
+)
 
 // radosgwProvider defines the provider implementation.
 type radosgwProvider struct {
@@ -35,6 +42,11 @@ type radosgwProviderModel struct {
 	Endpoint        types.String `tfsdk:"endpoint"`
 	AccessKeyID     types.String `tfsdk:"access_key_id"`
 	SecretAccessKey types.String `tfsdk:"secret_access_key"`
+	DisableCache    types.Bool   `tfsdk:"disable_cache"`
+	AdoptExisting   types.Bool   `tfsdk:"adopt_existing"`
+
+	MaxConcurrentRequests types.Int64   `tfsdk:"max_concurrent_requests"`
+	RequestsPerSecond     types.Float64 `tfsdk:"requests_per_second"`
 }
 
 func (p *radosgwProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -56,6 +68,28 @@ func (p *radosgwProvider) Schema(ctx context.Context, req provider.SchemaRequest
 				Optional:  true,
 				Sensitive: true,
 			},
+			"disable_cache": schema.BoolAttribute{
+				MarkdownDescription: "Disable caching of users fetched from radosgw.  By default users are cached for a short time, so that resources of the same user don't fetch it repeatedly during a plan or apply.",
+				Optional:            true,
+			},
+			"adopt_existing": schema.BoolAttribute{
+				MarkdownDescription: "Take over users, subusers and keys that already exist when creating them, instead of failing.  They are changed to match the configuration.  Can be overridden by `adopt_existing` of the resources.  Defaults to `false`.",
+				Optional:            true,
+			},
+			"max_concurrent_requests": schema.Int64Attribute{
+				MarkdownDescription: "Maximum number of requests sent to radosgw at the same time, shared by all resources and data sources.  Unlimited by default.",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"requests_per_second": schema.Float64Attribute{
+				MarkdownDescription: "Maximum number of requests per second sent to radosgw, shared by all resources and data sources.  Unlimited by default.",
+				Optional:            true,
+				Validators: []validator.Float64{
+					float64validator.AtLeast(0.01),
+				},
+			},
 		},
 	}
 }
@@ -129,7 +163,22 @@ func (p *radosgwProvider) Configure(ctx context.Context, req provider.ConfigureR
 	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, "radosgw_secret_access_key")
 	tflog.Debug(ctx, "creating radosgw admin client")
 
-	client, err := admin.New(endpoint, accessKeyID, secretAccessKey, http.DefaultClient)
+	var limited *limitedHTTPClient
+	if !config.MaxConcurrentRequests.IsNull() || !config.RequestsPerSecond.IsNull() {
+		limited = newLimitedHTTPClient(nil, int(config.MaxConcurrentRequests.ValueInt64()), config.RequestsPerSecond.ValueFloat64())
+	}
+	// wrapHTTPClient logs and limits the requests sent with client, with the
+	// limits shared by the admin API and the AWS APIs
+	wrapHTTPClient := func(client admin.HTTPClient) admin.HTTPClient {
+		var wrapped admin.HTTPClient = &loggingHTTPClient{client: client}
+		if limited != nil {
+			wrapped = limited.withClient(wrapped)
+		}
+		return wrapped
+	}
+	httpClient := wrapHTTPClient(http.DefaultClient)
+
+	client, err := admin.New(endpoint, accessKeyID, secretAccessKey, httpClient)
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Unable to create radosgw admin client",
@@ -138,8 +187,32 @@ func (p *radosgwProvider) Configure(ctx context.Context, req provider.ConfigureR
 		return
 	}
 
-	resp.DataSourceData = client
-	resp.ResourceData = client
+	var dataClient adminClient = client
+	if !config.DisableCache.ValueBool() {
+		dataClient = newCachingClient(client, userCacheTTL)
+	}
+
+	awsSession, err := newAWSSession(endpoint, accessKeyID, secretAccessKey, wrapHTTPClient)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to create radosgw S3 client",
+			"An unexpected error happened creating the client for the S3, SNS and IAM APIs of radosgw.\n\nClient error: "+err.Error(),
+		)
+		return
+	}
+
+	data := &radosgwProviderData{
+		client:          dataClient,
+		rawAdmin:        newRawAdminClient(endpoint, accessKeyID, secretAccessKey, httpClient),
+		endpoint:        endpoint,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		awsSession:      awsSession,
+		adoptExisting:   config.AdoptExisting.ValueBool(),
+	}
+	resp.DataSourceData = data
+	resp.ResourceData = data
+	resp.ListResourceData = data
 
 	tflog.Info(ctx, "configured radosgw admin client", map[string]any{"success": true})
 }
@@ -149,12 +222,35 @@ func (p *radosgwProvider) Resources(ctx context.Context) []func() resource.Resou
 		NewUserResource,
 		NewSubuserResource,
 		NewKeyResource,
+		NewBucketPolicyResource,
+		NewBucketLifecycleConfigurationResource,
+		NewBucketVersioningResource,
+		NewBucketObjectLockConfigurationResource,
+		NewTopicResource,
+		NewBucketNotificationResource,
+		NewBucketCORSConfigurationResource,
+		NewBucketWebsiteConfigurationResource,
+		NewBucketTaggingResource,
+		NewBucketServerSideEncryptionConfigurationResource,
+		NewBucketACLResource,
+		NewIAMRoleResource,
+		NewIAMRolePolicyResource,
+		NewUserPolicyResource,
+	}
+}
+
+func (p *radosgwProvider) ListResources(ctx context.Context) []func() list.ListResource {
+	return []func() list.ListResource{
+		NewUserListResource,
+		NewSubuserListResource,
+		NewKeyListResource,
 	}
 }
 
 func (p *radosgwProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewBucketsDataSource,
+		NewPolicyDocumentDataSource,
 	}
 }
 
@@ -0,0 +1,44 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+
+	"github.com/spreadshirt/terraform-provider-radosgw/internal/rgwtest"
+)
+
+// testAccProtoV6ProviderFactories are used to instantiate a provider during
+// acceptance testing. The factory function will be invoked for every Terraform
+// CLI command executed to create a provider server to which the CLI can
+// reattach.
+var testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"radosgw": providerserver.NewProtocol6WithError(New("test")()),
+}
+
+// testAccServer starts a fake radosgw for the duration of the test and
+// returns it together with a provider configuration using it.
+//
+// Acceptance tests run against the fake, so they neither need a Ceph cluster
+// nor network access.
+func testAccServer(t *testing.T) (*rgwtest.Server, string) {
+	t.Helper()
+
+	server := rgwtest.NewServer()
+	t.Cleanup(server.Close)
+
+	config := fmt.Sprintf(`
+provider "radosgw" {
+  endpoint          = %q
+  access_key_id     = %q
+  secret_access_key = %q
+}
+`, server.URL, server.AccessKey, server.SecretKey)
+
+	return server, config
+}
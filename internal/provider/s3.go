@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/ceph/go-ceph/rgw/admin"
+)
+
+// awsRegion is the region requests to the S3 API are signed for.  radosgw
+// checks signatures against the region of the request, so any name works
+// unless the zonegroup enforces its own.
+const awsRegion = "us-east-1"
+
+// newAWSSession returns a session for the AWS APIs of radosgw at endpoint,
+// such as S3.
+//
+// The HTTP client configured by the SDK is wrapped by wrapHTTPClient, so that
+// requests are logged and limited like those to the admin API.  Shared
+// config files are not read and the endpoint, region and credentials given
+// take precedence over the environment, as settings meant for AWS must not
+// apply to radosgw.
+func newAWSSession(endpoint, accessKeyID, secretAccessKey string, wrapHTTPClient func(admin.HTTPClient) admin.HTTPClient) (*session.Session, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config: aws.Config{
+			Endpoint:         aws.String(endpoint),
+			Region:           aws.String(awsRegion),
+			Credentials:      credentials.NewStaticCredentials(accessKeyID, secretAccessKey, ""),
+			S3ForcePathStyle: aws.Bool(true),
+			// the SDK applies AWS_CA_BUNDLE and client certificates to
+			// this client, which only works with an http.Transport
+			HTTPClient: &http.Client{},
+		},
+		SharedConfigState: session.SharedConfigDisable,
+		SharedConfigFiles: []string{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// keep the transport the SDK configured, so that AWS_CA_BUNDLE applies
+	transport := sess.Config.HTTPClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	sess.Config.HTTPClient.Transport = httpClientTransport{
+		client: wrapHTTPClient(&http.Client{Transport: transport}),
+	}
+	return sess, nil
+}
+
+// httpClientTransport sends the requests of an http.Client with an
+// admin.HTTPClient.
+type httpClientTransport struct {
+	client admin.HTTPClient
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t httpClientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.client.Do(req)
+}
+
+// awsErrorCode returns the error code of an error response of the AWS APIs
+// of radosgw, or "" if err is none.
+func awsErrorCode(err error) string {
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) {
+		return ""
+	}
+	return awsErr.Code()
+}
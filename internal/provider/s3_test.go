@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/ceph/go-ceph/rgw/admin"
+)
+
+// countingHTTPClient counts the requests sent with it.
+type countingHTTPClient struct {
+	client   admin.HTTPClient
+	requests atomic.Int32
+}
+
+// Do implements admin.HTTPClient.
+func (c *countingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	c.requests.Add(1)
+	return c.client.Do(req)
+}
+
+func TestNewAWSSessionIgnoresEnvironment(t *testing.T) {
+	// radosgw with a certificate of a private CA, given by a CA bundle
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte(`<ListAllMyBucketsResult><Owner><ID>demo</ID></Owner><Buckets><Bucket><Name>assets</Name></Bucket></Buckets></ListAllMyBucketsResult>`))
+	}))
+	defer server.Close()
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("AWS_CA_BUNDLE", bundle)
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "OTHERKEY")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "other-secret")
+	t.Setenv("AWS_PROFILE", "missing")
+	t.Setenv("AWS_SDK_LOAD_CONFIG", "1")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "missing"))
+
+	var counting *countingHTTPClient
+	session, err := newAWSSession(server.URL, "AK", "secret", func(client admin.HTTPClient) admin.HTTPClient {
+		counting = &countingHTTPClient{client: client}
+		return counting
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := aws.StringValue(session.Config.Region); got != awsRegion {
+		t.Errorf("got region %q, want %q", got, awsRegion)
+	}
+	if got := aws.StringValue(session.Config.Endpoint); got != server.URL {
+		t.Errorf("got endpoint %q", got)
+	}
+	creds, err := session.Config.Credentials.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.AccessKeyID != "AK" || creds.SecretAccessKey != "secret" {
+		t.Errorf("got credentials %q/%q, want AK/secret", creds.AccessKeyID, creds.SecretAccessKey)
+	}
+
+	// the CA bundle is used and requests go through the wrapped client
+	out, err := s3.New(session).ListBuckets(&s3.ListBucketsInput{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Buckets) != 1 || aws.StringValue(out.Buckets[0].Name) != "assets" {
+		t.Errorf("got buckets %v, want assets", out.Buckets)
+	}
+	if got := counting.requests.Load(); got != 1 {
+		t.Errorf("got %d requests through the wrapped client, want 1", got)
+	}
+}
@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ list.ListResource              = &subuserListResource{}
+	_ list.ListResourceWithConfigure = &subuserListResource{}
+)
+
+// NewSubuserListResource is a helper function to simplify the provider implementation.
+func NewSubuserListResource() list.ListResource {
+	return &subuserListResource{}
+}
+
+// subuserListResource lists the subusers of radosgw.
+type subuserListResource struct {
+	client adminClient
+}
+
+// Configure implements list.ListResourceWithConfigure.
+func (r *subuserListResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
+		return
+	}
+
+	r.client = data.client
+}
+
+// Metadata returns the resource type name.
+func (r *subuserListResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_subuser"
+}
+
+// ListResourceConfigSchema defines the schema for list blocks.
+func (r *subuserListResource) ListResourceConfigSchema(_ context.Context, _ list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists the subusers of radosgw, including those of tenanted users.",
+		Attributes: map[string]schema.Attribute{
+			"tenant": schema.StringAttribute{
+				MarkdownDescription: "Only list subusers of users of this tenant.",
+				Optional:            true,
+			},
+			"user_id": schema.StringAttribute{
+				MarkdownDescription: "Only list subusers of this user.",
+				Optional:            true,
+			},
+			"prefix": schema.StringAttribute{
+				MarkdownDescription: "Only list subusers whose name starts with this prefix.",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+type subuserListConfigModel struct {
+	Tenant types.String `tfsdk:"tenant"`
+	UserID types.String `tfsdk:"user_id"`
+	Prefix types.String `tfsdk:"prefix"`
+}
+
+// List implements list.ListResource.
+func (r *subuserListResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	var config subuserListConfigModel
+	diags := req.Config.Get(ctx, &config)
+	if diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	include := func(uid string) bool {
+		tenant, userID := splitUserID(uid)
+		return (config.Tenant.IsNull() || tenant == config.Tenant.ValueString()) &&
+			(config.UserID.IsNull() || userID == config.UserID.ValueString())
+	}
+
+	stream.Results = func(push func(list.ListResult) bool) {
+		err := listUsers(ctx, r.client, include, func(user admin.User) bool {
+			for _, subuser := range user.Subusers {
+				subuser = mapSubuser(user.ID, subuser)
+				if !strings.HasPrefix(subuser.Name, config.Prefix.ValueString()) {
+					continue
+				}
+
+				state := importedSubuserState(user.ID, subuser)
+
+				result := req.NewListResult(ctx)
+				result.DisplayName = state.ref().String()
+				result.Diagnostics.Append(result.Identity.Set(ctx, state.identity())...)
+				if req.IncludeResource {
+					result.Diagnostics.Append(result.Resource.Set(ctx, state)...)
+				}
+
+				if !push(result) {
+					return false
+				}
+			}
+			return true
+		})
+		if err != nil {
+			push(listErrorResult("Error listing subusers", err))
+		}
+	}
+}
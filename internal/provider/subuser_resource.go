@@ -2,15 +2,25 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/ceph/go-ceph/rgw/admin"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -18,6 +28,7 @@ var (
 	_ resource.Resource                = &subuserResource{}
 	_ resource.ResourceWithConfigure   = &subuserResource{}
 	_ resource.ResourceWithImportState = &subuserResource{}
+	_ resource.ResourceWithIdentity    = &subuserResource{}
 )
 
 // NewSubuserResource is a helper function to simplify the provider implementation.
@@ -27,27 +38,21 @@ func NewSubuserResource() resource.Resource {
 
 // subuserResource is the resource implementation.
 type subuserResource struct {
-	client *admin.API
+	client adminClient
+
+	// adoptExisting is the provider default for adopting existing subusers.
+	adoptExisting bool
 }
 
 // Configure implements resource.ResourceWithConfigure.
 func (r *subuserResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Prevent panic if the provider has not been configured.
-	if req.ProviderData == nil {
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
 		return
 	}
 
-	client, ok := req.ProviderData.(*admin.API)
-	if !ok {
-		resp.Diagnostics.AddError(
-			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *admin.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
-		)
-
-		return
-	}
-
-	r.client = client
+	r.client = data.client
+	r.adoptExisting = data.adoptExisting
 }
 
 // Metadata returns the resource type name.
@@ -59,11 +64,27 @@ func (r *subuserResource) Metadata(_ context.Context, req resource.MetadataReque
 func (r *subuserResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
+			"tenant": schema.StringAttribute{
+				MarkdownDescription: "Tenant of the user, if any.",
+				Optional:            true,
+				Validators:          tenantValidators(),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
 			"user_id": schema.StringAttribute{
-				Required: true,
+				Required:   true,
+				Validators: rgwIDValidators(),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"subuser": schema.StringAttribute{
-				Required: true,
+				Required:   true,
+				Validators: rgwIDValidators(),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"access": schema.StringAttribute{
 				Required: true,
@@ -77,14 +98,146 @@ func (r *subuserResource) Schema(_ context.Context, _ resource.SchemaRequest, re
 					),
 				},
 			},
+			"generate_key": schema.BoolAttribute{
+				MarkdownDescription: "Generate a key for the subuser on creation, see `access_key` and `secret_key`.  Keys can also be managed separately using the `radosgw_key` resource.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+			},
+			"key_type": schema.StringAttribute{
+				MarkdownDescription: "Type of the key created with `generate_key` or `secret_key`, either `s3` or `swift`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString(defaultSubuserKeyType),
+				Validators: []validator.String{
+					stringvalidator.OneOf("s3", "swift"),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"access_key": schema.StringAttribute{
+				MarkdownDescription: "Access key of the key created for the subuser, only set for `s3` keys.",
+				Computed:            true,
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"secret_key": schema.StringAttribute{
+				MarkdownDescription: "Secret key to create for the subuser.  Generated if `generate_key` is set.",
+				Optional:            true,
+				Computed:            true,
+				Sensitive:           true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"purge_keys": schema.BoolAttribute{
+				MarkdownDescription: "Remove the keys of the subuser when it is deleted.  Defaults to `true`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"adopt_existing": adoptExistingAttribute("subuser"),
+		},
+	}
+}
+
+// IdentitySchema defines the identity of the resource.
+func (r *subuserResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"tenant": identityschema.StringAttribute{
+				Description:       "Tenant of the user, if any.",
+				OptionalForImport: true,
+			},
+			"user_id": identityschema.StringAttribute{
+				RequiredForImport: true,
+			},
+			"subuser": identityschema.StringAttribute{
+				RequiredForImport: true,
+			},
 		},
 	}
 }
 
+// defaultSubuserKeyType is the key type used if key_type is not set.
+const defaultSubuserKeyType = "s3"
+
 type subuserResourceModel struct {
-	UserID  types.String `tfsdk:"user_id"`
-	Subuser types.String `tfsdk:"subuser"`
-	Access  types.String `tfsdk:"access"`
+	Tenant      types.String `tfsdk:"tenant"`
+	UserID      types.String `tfsdk:"user_id"`
+	Subuser     types.String `tfsdk:"subuser"`
+	Access      types.String `tfsdk:"access"`
+	GenerateKey types.Bool   `tfsdk:"generate_key"`
+	KeyType     types.String `tfsdk:"key_type"`
+	AccessKey   types.String `tfsdk:"access_key"`
+	SecretKey   types.String `tfsdk:"secret_key"`
+	PurgeKeys   types.Bool   `tfsdk:"purge_keys"`
+
+	AdoptExisting types.Bool `tfsdk:"adopt_existing"`
+}
+
+// wantsKey reports whether the subuser gets a key.
+func (m subuserResourceModel) wantsKey() bool {
+	return m.GenerateKey.ValueBool() || !m.SecretKey.IsNull()
+}
+
+// ref returns the subuser of m.
+func (m subuserResourceModel) ref() userRef {
+	return userRef{Tenant: m.Tenant.ValueString(), UserID: m.UserID.ValueString(), Subuser: m.Subuser.ValueString()}
+}
+
+// uid returns the ID of the parent user as used by the admin API.
+func (m subuserResourceModel) uid() string {
+	return m.ref().uid()
+}
+
+// errorTarget describes the subuser for explaining admin API errors.
+func (m subuserResourceModel) errorTarget() rgwErrorTarget {
+	return rgwErrorTarget{
+		resourceType: "radosgw_subuser",
+		importID:     m.ref().String(),
+		userPath:     path.Root("user_id"),
+		subuserPath:  path.Root("subuser"),
+		keyPath:      path.Root("secret_key"),
+	}
+}
+
+// identity returns the resource identity of the subuser.
+func (m subuserResourceModel) identity() subuserIdentityModel {
+	return subuserIdentityModel{
+		Tenant:  m.Tenant,
+		UserID:  m.UserID,
+		Subuser: m.Subuser,
+	}
+}
+
+// setUserID sets the tenant and user from a user ID returned by the admin API.
+func (m *subuserResourceModel) setUserID(uid string) {
+	ref := parseUserRef(uid)
+	m.Tenant = optionalString(ref.Tenant)
+	m.UserID = types.StringValue(ref.UserID)
+}
+
+// setDefaults fills in the defaults of attributes that are missing from
+// imported state or state written by older versions of the provider, so
+// that they do not cause the subuser to be replaced.
+func (m *subuserResourceModel) setDefaults() {
+	if m.GenerateKey.IsNull() {
+		m.GenerateKey = types.BoolValue(false)
+	}
+	if m.KeyType.IsNull() {
+		m.KeyType = types.StringValue(defaultSubuserKeyType)
+	}
+	if m.PurgeKeys.IsNull() {
+		m.PurgeKeys = types.BoolValue(true)
+	}
 }
 
 // Read implements resource.Resource.
@@ -96,19 +249,18 @@ func (r *subuserResource) Read(ctx context.Context, req resource.ReadRequest, re
 		return
 	}
 
-	user, err := r.client.GetUser(ctx, admin.User{ID: state.UserID.ValueString()})
+	user, err := r.client.GetUser(ctx, admin.User{ID: state.uid()})
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error fetching subuser",
-			"Could not fetch user to get subuser: "+err.Error(),
-		)
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error fetching subuser",
+			fmt.Sprintf("Could not fetch user %q to get subuser", state.uid()), err)
 		return
 	}
 
 	var found bool
 	var matchingSubuser admin.SubuserSpec
 	for _, subuser := range user.Subusers {
-		if strings.TrimPrefix(subuser.Name, user.ID+":") == state.Subuser.ValueString() {
+		subuser = mapSubuser(user.ID, subuser)
+		if subuser.Name == state.Subuser.ValueString() {
 			found = true
 			matchingSubuser = subuser
 			break
@@ -123,17 +275,19 @@ func (r *subuserResource) Read(ctx context.Context, req resource.ReadRequest, re
 		return
 	}
 
-	matchingSubuser = mapSubuser(user.ID, matchingSubuser)
-
-	state.UserID = types.StringValue(user.ID)
+	state.setUserID(user.ID)
 	state.Subuser = types.StringValue(matchingSubuser.Name)
 	state.Access = types.StringValue(string(matchingSubuser.Access))
+	state.setDefaults()
 
 	diags = resp.State.Set(ctx, state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	diags = resp.Identity.Set(ctx, state.identity())
+	resp.Diagnostics.Append(diags...)
 }
 
 // mapSubuser maps some fields of subuser so that they work with Terraform.
@@ -160,56 +314,86 @@ func mapSubuser(parentUser string, subuser admin.SubuserSpec) admin.SubuserSpec
 	return subuser
 }
 
-// ImportState implements resource.ResourceWithImportState.
+// ImportState implements resource.ResourceWithImportState.  Subusers are
+// imported by an import ID parsed by parseSubuserImportID or by their
+// identity.
 func (r *subuserResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	parts := strings.SplitN(req.ID, ":", 2)
-	if len(parts) != 2 {
+	var id userRef
+	if req.ID == "" {
+		var identity subuserIdentityModel
+		diags := req.Identity.Get(ctx, &identity)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		id = identity.ref()
+	} else {
+		var err error
+		id, err = parseSubuserImportID(req.ID)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Invalid subuser import ID",
+				fmt.Sprintf("Could not parse import ID %q: %s.\n\nThe import ID must be of the form %s.", req.ID, err, subuserImportFormats),
+			)
+			return
+		}
+	}
+
+	user, err := r.client.GetUser(ctx, admin.User{ID: id.uid()})
+	if errors.Is(err, admin.ErrNoSuchUser) {
 		resp.Diagnostics.AddError(
-			"Invalid subuser reference",
-			"Subuser must be of format <user>:<subuser>",
+			"User not found",
+			fmt.Sprintf("Could not import subuser %q, because its user %q does not exist.", id.Subuser, id.uid()),
 		)
 		return
 	}
-
-	user, err := r.client.GetUser(ctx, admin.User{ID: parts[0]})
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error fetching user",
-			"Could not fetch user: "+err.Error(),
-		)
+		addRGWError(&resp.Diagnostics, rgwErrorTarget{}, "Error fetching user",
+			fmt.Sprintf("Could not fetch user %q", id.uid()), err)
+		return
 	}
 
 	var found bool
 	var matchingSubuser admin.SubuserSpec
 	for _, subuser := range user.Subusers {
-		if subuser.Name == req.ID {
+		subuser = mapSubuser(user.ID, subuser)
+		if subuser.Name == id.Subuser {
 			found = true
 			matchingSubuser = subuser
-			matchingSubuser.Name = strings.TrimPrefix(subuser.Name, user.ID+":")
 			break
 		}
 	}
 
 	if !found {
 		resp.Diagnostics.AddError(
-			"Missing subuser",
-			"Could not find subuser",
+			"Subuser not found",
+			fmt.Sprintf("Could not import subuser %q, because user %q has no such subuser.", id.Subuser, id.uid()),
 		)
 		return
 	}
 
-	matchingSubuser = mapSubuser(user.ID, matchingSubuser)
-
-	var state subuserResourceModel
-	state.UserID = types.StringValue(user.ID)
-	state.Subuser = types.StringValue(matchingSubuser.Name)
-	state.Access = types.StringValue(string(matchingSubuser.Access))
-
+	state := importedSubuserState(user.ID, matchingSubuser)
 	diags := resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	diags = resp.Identity.Set(ctx, state.identity())
+	resp.Diagnostics.Append(diags...)
+}
+
+// importedSubuserState returns the state of an imported subuser of the user
+// with ID uid.  Its keys are not imported, they are managed by radosgw_key.
+func importedSubuserState(uid string, subuser admin.SubuserSpec) subuserResourceModel {
+	var state subuserResourceModel
+	state.setUserID(uid)
+	state.Subuser = types.StringValue(subuser.Name)
+	state.Access = types.StringValue(string(subuser.Access))
+	state.AccessKey = types.StringNull()
+	state.SecretKey = types.StringNull()
+	state.setDefaults()
+	return state
 }
 
 // Create implements resource.Resource.
@@ -221,20 +405,48 @@ func (r *subuserResource) Create(ctx context.Context, req resource.CreateRequest
 		return
 	}
 
-	generateKey := false // do not auto-generate keys, they are managed using the radosgw_key resource
+	// keys are not created with the subuser, but added by createKey below
+	// if generate_key or secret_key ask for one
+	generateKey := false
 	newSubuser := admin.SubuserSpec{
 		Name:        plan.Subuser.ValueString(),
 		Access:      admin.SubuserAccess(plan.Access.ValueString()),
 		GenerateKey: &generateKey,
 	}
 
-	err := r.client.CreateSubuser(ctx, admin.User{ID: plan.UserID.ValueString()}, newSubuser)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating subuser",
-			"Could not create subuser, unexpected error: "+err.Error(),
-		)
+	plan.AccessKey = types.StringNull()
+	if plan.SecretKey.IsUnknown() {
+		plan.SecretKey = types.StringNull()
+	}
+
+	err := r.client.CreateSubuser(ctx, admin.User{ID: plan.uid()}, newSubuser)
+	if errors.Is(err, admin.ErrSubuserExists) && shouldAdopt(plan.AdoptExisting, r.adoptExisting) {
+		if err := r.adopt(ctx, &plan, &resp.Diagnostics); err != nil {
+			addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error adopting subuser",
+				fmt.Sprintf("Could not adopt existing subuser %q", plan.Subuser.ValueString()), err)
+			return
+		}
+	} else if err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error creating subuser",
+			fmt.Sprintf("Could not create subuser %q", plan.Subuser.ValueString()), err)
 		return
+	} else if plan.wantsKey() {
+		if err := r.createKey(ctx, &plan); err != nil {
+			addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error creating subuser key",
+				fmt.Sprintf("Could not create key for subuser %q", plan.Subuser.ValueString()), err)
+			// no state is saved, so remove the subuser again instead of
+			// failing the next apply with SubuserExists
+			purgeKeys := true
+			err = r.client.RemoveSubuser(ctx, admin.User{ID: plan.uid()}, admin.SubuserSpec{
+				Name:      plan.Subuser.ValueString(),
+				PurgeKeys: &purgeKeys,
+			})
+			if err != nil {
+				addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error removing subuser",
+					fmt.Sprintf("Could not remove subuser %q after failing to create its key, remove it manually or import it", plan.Subuser.ValueString()), err)
+			}
+			return
+		}
 	}
 
 	diags = resp.State.Set(ctx, plan)
@@ -242,36 +454,154 @@ func (r *subuserResource) Create(ctx context.Context, req resource.CreateRequest
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	diags = resp.Identity.Set(ctx, plan.identity())
+	resp.Diagnostics.Append(diags...)
+}
+
+// createKey creates the key of the subuser and sets it in plan.
+func (r *subuserResource) createKey(ctx context.Context, plan *subuserResourceModel) error {
+	newKey := admin.UserKeySpec{
+		UID:       plan.uid(),
+		SubUser:   plan.Subuser.ValueString(),
+		KeyType:   plan.KeyType.ValueString(),
+		SecretKey: plan.SecretKey.ValueString(),
+	}
+	if plan.GenerateKey.ValueBool() {
+		generateKey := true
+		newKey.GenerateKey = &generateKey
+	}
+
+	keys, err := r.client.CreateKey(ctx, newKey)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range *keys {
+		if key.User != plan.ref().String() {
+			continue
+		}
+
+		if key.AccessKey != "" {
+			plan.AccessKey = types.StringValue(key.AccessKey)
+		}
+		plan.SecretKey = types.StringValue(key.SecretKey)
+		break
+	}
+
+	return nil
+}
+
+// adopt takes over the existing subuser of plan, changing its access and
+// adding a key if they differ from the plan.  An existing key of the subuser
+// is kept if it matches the key type and secret key of the plan.
+func (r *subuserResource) adopt(ctx context.Context, plan *subuserResourceModel, diags *diag.Diagnostics) error {
+	user, err := r.client.GetUser(ctx, admin.User{ID: plan.uid()})
+	if err != nil {
+		return err
+	}
+
+	var existing *admin.SubuserSpec
+	for _, subuser := range user.Subusers {
+		subuser = mapSubuser(user.ID, subuser)
+		if subuser.Name == plan.Subuser.ValueString() {
+			existing = &subuser
+			break
+		}
+	}
+	if existing == nil {
+		return fmt.Errorf("subuser %q of user %q disappeared while adopting it", plan.Subuser.ValueString(), plan.uid())
+	}
+
+	var changed []string
+	if string(existing.Access) != plan.Access.ValueString() {
+		modifiedSubuser := admin.SubuserSpec{
+			Name:   plan.Subuser.ValueString(),
+			Access: admin.SubuserAccess(plan.Access.ValueString()),
+		}
+		if err := r.client.ModifySubuser(ctx, admin.User{ID: plan.uid()}, modifiedSubuser); err != nil {
+			return err
+		}
+		changed = append(changed, "access")
+	}
+
+	if plan.wantsKey() && !plan.adoptKey(user) {
+		if err := r.createKey(ctx, plan); err != nil {
+			return err
+		}
+		changed = append(changed, "key")
+	}
+
+	tflog.Warn(ctx, "adopted existing subuser", map[string]any{"user_id": plan.uid(), "subuser": plan.Subuser.ValueString(), "fields": changed})
+	diags.AddWarning(
+		"Adopted existing subuser",
+		fmt.Sprintf("Subuser %q of user %q already existed and is now managed by Terraform.%s", plan.Subuser.ValueString(), plan.uid(), adoptedChanges(changed)),
+	)
+
+	return nil
+}
+
+// adoptKey sets the key of the subuser in m to an existing key of user that
+// matches its key type and secret key, and reports whether there is one.
+func (m *subuserResourceModel) adoptKey(user admin.User) bool {
+	owner := m.ref().String()
+	matches := func(keyOwner, secretKey string) bool {
+		return keyOwner == owner && (m.SecretKey.IsNull() || m.SecretKey.ValueString() == secretKey)
+	}
+
+	if m.KeyType.ValueString() == "swift" {
+		for _, key := range user.SwiftKeys {
+			if matches(key.User, key.SecretKey) {
+				m.SecretKey = types.StringValue(key.SecretKey)
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, key := range user.Keys {
+		if matches(key.User, key.SecretKey) {
+			m.AccessKey = types.StringValue(key.AccessKey)
+			m.SecretKey = types.StringValue(key.SecretKey)
+			return true
+		}
+	}
+	return false
 }
 
 // Update implements resource.Resource.
 func (r *subuserResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan subuserResourceModel
-	diags := req.Plan.Get(ctx, &plan)
-	resp.Diagnostics.Append(diags...)
+	var plan, state subuserResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	modifiedSubuser := admin.SubuserSpec{
-		Name:   plan.Subuser.ValueString(),
-		Access: admin.SubuserAccess(plan.Access.ValueString()),
-	}
+	// access is the only attribute of the subuser itself that can change in
+	// place, purge_keys and adopt_existing only matter on delete and create
+	if !plan.Access.Equal(state.Access) {
+		modifiedSubuser := admin.SubuserSpec{
+			Name:   plan.Subuser.ValueString(),
+			Access: admin.SubuserAccess(plan.Access.ValueString()),
+		}
 
-	err := r.client.ModifySubuser(ctx, admin.User{ID: plan.UserID.ValueString()}, modifiedSubuser)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating subuser",
-			"Could not create subuser, unexpected error: "+err.Error(),
-		)
-		return
+		err := r.client.ModifySubuser(ctx, admin.User{ID: plan.uid()}, modifiedSubuser)
+		if err != nil {
+			addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error updating subuser",
+				fmt.Sprintf("Could not update subuser %q", plan.Subuser.ValueString()), err)
+			return
+		}
 	}
 
-	diags = resp.State.Set(ctx, plan)
+	diags := resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	diags = resp.Identity.Set(ctx, plan.identity())
+	resp.Diagnostics.Append(diags...)
 }
 
 // Delete implements resource.Resource.
@@ -283,12 +613,14 @@ func (r *subuserResource) Delete(ctx context.Context, req resource.DeleteRequest
 		return
 	}
 
-	err := r.client.RemoveSubuser(ctx, admin.User{ID: state.UserID.ValueString()}, admin.SubuserSpec{Name: state.Subuser.ValueString()})
+	purgeKeys := state.PurgeKeys.IsNull() || state.PurgeKeys.ValueBool()
+	err := r.client.RemoveSubuser(ctx, admin.User{ID: state.uid()}, admin.SubuserSpec{
+		Name:      state.Subuser.ValueString(),
+		PurgeKeys: &purgeKeys,
+	})
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error removing subuser",
-			fmt.Sprintf("Could not remove subuser %q: %s", state.Subuser.ValueString(), err),
-		)
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error removing subuser",
+			fmt.Sprintf("Could not remove subuser %q", state.Subuser.ValueString()), err)
 		return
 	}
 }
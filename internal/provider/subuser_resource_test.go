@@ -0,0 +1,380 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccSubuserResource(t *testing.T) {
+	_, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + testAccSubuserResourceConfig("read"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_subuser.test", "user_id", "demo"),
+					resource.TestCheckResourceAttr("radosgw_subuser.test", "subuser", "readonly"),
+					resource.TestCheckResourceAttr("radosgw_subuser.test", "access", "read"),
+					resource.TestCheckResourceAttr("radosgw_subuser.test", "purge_keys", "true"),
+					resource.TestCheckNoResourceAttr("radosgw_subuser.test", "access_key"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "radosgw_subuser.test",
+				ImportState:                          true,
+				ImportStateId:                        "demo:readonly",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "subuser",
+			},
+			{
+				ResourceName:                         "radosgw_subuser.test",
+				ImportState:                          true,
+				ImportStateId:                        `{"user_id": "demo", "subuser": "readonly"}`,
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "subuser",
+			},
+			{
+				ResourceName:  "radosgw_subuser.test",
+				ImportState:   true,
+				ImportStateId: "demo",
+				ExpectError:   regexp.MustCompile(`Invalid subuser import ID`),
+			},
+			{
+				ResourceName:  "radosgw_subuser.test",
+				ImportState:   true,
+				ImportStateId: "missing:readonly",
+				ExpectError:   regexp.MustCompile(`User not found`),
+			},
+			{
+				ResourceName:  "radosgw_subuser.test",
+				ImportState:   true,
+				ImportStateId: "demo:missing",
+				ExpectError:   regexp.MustCompile(`Subuser not found`),
+			},
+			// Update and Read testing
+			{
+				Config: providerConfig + testAccSubuserResourceConfig("full"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_subuser.test", "access", "full"),
+				),
+			},
+		},
+	})
+}
+
+func testAccSubuserResourceConfig(access string) string {
+	return fmt.Sprintf(`
+resource "radosgw_user" "test" {
+  user_id      = "demo"
+  display_name = "Demo user"
+}
+
+resource "radosgw_subuser" "test" {
+  user_id = radosgw_user.test.user_id
+  subuser = "readonly"
+  access  = %q
+}
+`, access)
+}
+
+func TestAccSubuserResource_purgeKeys(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	config := func(purgeKeys bool) string {
+		return fmt.Sprintf(`
+resource "radosgw_user" "test" {
+  user_id      = "demo"
+  display_name = "Demo user"
+}
+
+resource "radosgw_subuser" "test" {
+  user_id    = radosgw_user.test.user_id
+  subuser    = "readonly"
+  access     = "read"
+  purge_keys = %t
+}
+`, purgeKeys)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + config(true),
+			},
+			// purge_keys only matters on delete, so changing it does not
+			// modify the subuser
+			{
+				PreConfig: func() {
+					t.Cleanup(server.Fail(func(r *http.Request) bool {
+						return r.Method == http.MethodPost && r.URL.Query().Has("subuser")
+					}, http.StatusInternalServerError, "UnknownError"))
+				},
+				Config: providerConfig + config(false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_subuser.test", "purge_keys", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSubuserResource_tenant(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck: func() {
+			if _, err := server.API().CreateUser(context.Background(), admin.User{ID: "demo", Tenant: "acme", DisplayName: "Demo user"}); err != nil {
+				t.Fatal(err)
+			}
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "radosgw_subuser" "test" {
+  tenant  = "acme"
+  user_id = "demo"
+  subuser = "readonly"
+  access  = "read"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_subuser.test", "tenant", "acme"),
+					resource.TestCheckResourceAttr("radosgw_subuser.test", "user_id", "demo"),
+				),
+			},
+			{
+				ResourceName:                         "radosgw_subuser.test",
+				ImportState:                          true,
+				ImportStateId:                        "acme$demo:readonly",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "subuser",
+			},
+			{
+				ResourceName:                         "radosgw_subuser.test",
+				ImportState:                          true,
+				ImportStateId:                        `{"tenant": "acme", "user_id": "demo", "subuser": "readonly"}`,
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "subuser",
+			},
+		},
+	})
+}
+
+func TestAccSubuserResource_identity(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_12_0),
+		},
+		PreCheck: func() {
+			if _, err := server.API().CreateUser(context.Background(), admin.User{ID: "demo", Tenant: "acme", DisplayName: "Demo user"}); err != nil {
+				t.Fatal(err)
+			}
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "radosgw_subuser" "test" {
+  tenant  = "acme"
+  user_id = "demo"
+  subuser = "readonly"
+  access  = "read"
+}
+`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectIdentity("radosgw_subuser.test", map[string]knownvalue.Check{
+						"tenant":  knownvalue.StringExact("acme"),
+						"user_id": knownvalue.StringExact("demo"),
+						"subuser": knownvalue.StringExact("readonly"),
+					}),
+				},
+			},
+			{
+				ResourceName:    "radosgw_subuser.test",
+				ImportState:     true,
+				ImportStateKind: resource.ImportBlockWithResourceIdentity,
+			},
+		},
+	})
+}
+
+func TestAccSubuserResource_generateKey(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "radosgw_user" "test" {
+  user_id      = "demo"
+  display_name = "Demo user"
+}
+
+resource "radosgw_subuser" "test" {
+  user_id      = radosgw_user.test.user_id
+  subuser      = "app"
+  access       = "readwrite"
+  generate_key = true
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("radosgw_subuser.test", "access_key"),
+					resource.TestCheckResourceAttrSet("radosgw_subuser.test", "secret_key"),
+				),
+			},
+		},
+		CheckDestroy: testAccCheckUser(server, "demo", func(user admin.User) error {
+			for _, key := range user.Keys {
+				if key.User == "demo:app" {
+					return fmt.Errorf("key %s of removed subuser was not purged", key.AccessKey)
+				}
+			}
+			return nil
+		}),
+	})
+}
+
+func TestAccSubuserResource_generateKeyFailure(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+	config := providerConfig + `
+resource "radosgw_user" "test" {
+  user_id      = "demo"
+  display_name = "Demo user"
+}
+
+resource "radosgw_subuser" "test" {
+  user_id      = radosgw_user.test.user_id
+  subuser      = "app"
+  access       = "readwrite"
+  generate_key = true
+}
+`
+
+	var restore func()
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				PreConfig: func() {
+					restore = server.Fail(func(r *http.Request) bool {
+						return r.Method == http.MethodPut && r.URL.Query().Has("key")
+					}, http.StatusInternalServerError, "UnknownError")
+				},
+				Config:      config,
+				ExpectError: regexp.MustCompile(`UnknownError`),
+			},
+			// the subuser without key was removed, so it is created again
+			{
+				PreConfig: func() {
+					restore()
+					user, err := server.API().GetUser(context.Background(), admin.User{ID: "demo"})
+					if err != nil {
+						t.Fatal(err)
+					}
+					if len(user.Subusers) != 0 {
+						t.Fatalf("subuser of the failed create was left behind: %+v", user.Subusers)
+					}
+				},
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("radosgw_subuser.test", "access_key"),
+					resource.TestCheckResourceAttrSet("radosgw_subuser.test", "secret_key"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSubuserResource_adoptExisting(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck: func() {
+			ctx := context.Background()
+			if _, err := server.API().CreateUser(ctx, admin.User{ID: "demo", DisplayName: "Demo user"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := server.API().CreateSubuser(ctx, admin.User{ID: "demo"}, admin.SubuserSpec{Name: "app", Access: admin.SubuserAccessRead}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := server.API().CreateKey(ctx, admin.UserKeySpec{UID: "demo", SubUser: "app", KeyType: "s3", AccessKey: "APPACCESSKEY", SecretKey: "app-secret-key"}); err != nil {
+				t.Fatal(err)
+			}
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "radosgw_subuser" "test" {
+  user_id        = "demo"
+  subuser        = "app"
+  access         = "readwrite"
+  generate_key   = true
+  adopt_existing = true
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_subuser.test", "access", "readwrite"),
+					resource.TestCheckResourceAttr("radosgw_subuser.test", "access_key", "APPACCESSKEY"),
+					resource.TestCheckResourceAttr("radosgw_subuser.test", "secret_key", "app-secret-key"),
+					testAccCheckUser(server, "demo", func(user admin.User) error {
+						if len(user.Subusers) != 1 || user.Subusers[0].Access != admin.SubuserAccessReplyReadWrite {
+							return fmt.Errorf("subuser access was not changed: %+v", user.Subusers)
+						}
+						if len(user.Keys) != 2 {
+							return fmt.Errorf("got %d keys, want the user key and the adopted subuser key", len(user.Keys))
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccSubuserResource_exists(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck: func() {
+			ctx := context.Background()
+			if _, err := server.API().CreateUser(ctx, admin.User{ID: "demo", DisplayName: "Demo user"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := server.API().CreateSubuser(ctx, admin.User{ID: "demo"}, admin.SubuserSpec{Name: "app", Access: admin.SubuserAccessRead}); err != nil {
+				t.Fatal(err)
+			}
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "radosgw_subuser" "test" {
+  user_id = "demo"
+  subuser = "app"
+  access  = "read"
+}
+`,
+				ExpectError: regexp.MustCompile(`The subuser already exists`),
+			},
+		},
+	})
+}
@@ -0,0 +1,437 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &topicResource{}
+	_ resource.ResourceWithConfigure      = &topicResource{}
+	_ resource.ResourceWithImportState    = &topicResource{}
+	_ resource.ResourceWithValidateConfig = &topicResource{}
+)
+
+// topicNamePattern matches the names radosgw accepts for topics.
+var topicNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// topicEndpointSchemes are the push endpoint schemes radosgw supports.
+var topicEndpointSchemes = []string{"http", "https", "amqp", "amqps", "kafka"}
+
+// topicEndpointArgs are the endpoint arguments of topics that can be set
+// with endpoint_args.  The others have attributes of their own.
+var topicEndpointArgs = []string{
+	"amqp-exchange",
+	"amqp-ack-level",
+	"kafka-ack-level",
+	"use-ssl",
+	"ca-location",
+	"cloudevents",
+	"mechanism",
+	"time_to_live",
+	"max_retries",
+	"retry_sleep_duration",
+}
+
+// NewTopicResource is a helper function to simplify the provider implementation.
+func NewTopicResource() resource.Resource {
+	return &topicResource{}
+}
+
+// topicResource is the resource implementation.
+type topicResource struct {
+	sns snsiface.SNSAPI
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *topicResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
+		return
+	}
+
+	r.sns = sns.New(data.awsSession)
+}
+
+// Metadata returns the resource type name.
+func (r *topicResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_topic"
+}
+
+// Schema defines the schema for the resource.
+func (r *topicResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a topic for bucket notifications through the SNS API of radosgw.  Topics belong to the tenant of the provider credentials.",
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the topic.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(topicNamePattern, "must be 1 to 256 letters, digits, hyphens and underscores"),
+				},
+			},
+			"arn": schema.StringAttribute{
+				MarkdownDescription: "ARN of the topic, used by bucket notifications.",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"push_endpoint": schema.StringAttribute{
+				MarkdownDescription: "URI of the endpoint notifications are sent to, with one of the schemes `http`, `https`, `amqp`, `amqps` and `kafka`.  " +
+					"radosgw only accepts credentials in the URI over TLS, unless configured otherwise.  Without endpoint, notifications are only stored in persistent topics.",
+				Optional:  true,
+				Sensitive: true,
+				Validators: []validator.String{
+					topicEndpointValidator{},
+				},
+			},
+			"persistent": schema.BoolAttribute{
+				MarkdownDescription: "Whether notifications are stored and sent asynchronously, so that they are not lost when the endpoint is unavailable.  Defaults to `false`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"opaque_data": schema.StringAttribute{
+				MarkdownDescription: "Data added to all notifications of the topic.",
+				Optional:            true,
+			},
+			"verify_ssl": schema.BoolAttribute{
+				MarkdownDescription: "Whether the certificate of TLS endpoints is verified.  Defaults to `true`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"endpoint_args": schema.MapAttribute{
+				MarkdownDescription: "Further arguments of the endpoint, such as `amqp-exchange`, which AMQP endpoints need.  Supported are: `" + strings.Join(topicEndpointArgs, "`, `") + "`.",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Validators: []validator.Map{
+					mapvalidator.KeysAre(stringvalidator.OneOf(topicEndpointArgs...)),
+				},
+			},
+		},
+	}
+}
+
+type topicResourceModel struct {
+	Name         types.String `tfsdk:"name"`
+	ARN          types.String `tfsdk:"arn"`
+	PushEndpoint types.String `tfsdk:"push_endpoint"`
+	Persistent   types.Bool   `tfsdk:"persistent"`
+	OpaqueData   types.String `tfsdk:"opaque_data"`
+	VerifySSL    types.Bool   `tfsdk:"verify_ssl"`
+	EndpointArgs types.Map    `tfsdk:"endpoint_args"`
+}
+
+// errorTarget describes the topic for explaining SNS API errors.
+func (m topicResourceModel) errorTarget() rgwErrorTarget {
+	return rgwErrorTarget{
+		resourceType: "radosgw_topic",
+		importID:     m.Name.ValueString(),
+		endpointPath: path.Root("push_endpoint"),
+	}
+}
+
+// attributes returns the attributes of the topic for CreateTopic.  All
+// attributes are sent, as radosgw replaces the topic.
+func (m topicResourceModel) attributes() map[string]*string {
+	attributes := map[string]*string{
+		"persistent": aws.String(fmt.Sprint(m.Persistent.ValueBool())),
+		"verify-ssl": aws.String(fmt.Sprint(m.VerifySSL.ValueBool())),
+	}
+	if !m.PushEndpoint.IsNull() {
+		attributes["push-endpoint"] = aws.String(m.PushEndpoint.ValueString())
+	}
+	if !m.OpaqueData.IsNull() {
+		attributes["OpaqueData"] = aws.String(m.OpaqueData.ValueString())
+	}
+	for name, value := range m.EndpointArgs.Elements() {
+		attributes[name] = aws.String(value.(types.String).ValueString())
+	}
+	return attributes
+}
+
+// topicEndpointValidator checks push endpoints of topics.
+type topicEndpointValidator struct{}
+
+var _ validator.String = topicEndpointValidator{}
+
+// Description implements validator.String.
+func (v topicEndpointValidator) Description(_ context.Context) string {
+	return "value must be a URI with one of the schemes " + strings.Join(topicEndpointSchemes, ", ")
+}
+
+// MarkdownDescription implements validator.String.
+func (v topicEndpointValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString implements validator.String.
+func (v topicEndpointValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	u, err := url.Parse(req.ConfigValue.ValueString())
+	if err != nil || u.Host == "" || !containsString(topicEndpointSchemes, u.Scheme) {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid push endpoint",
+			fmt.Sprintf("The push endpoint %q is not a URI with one of the schemes %s.", redactURL(req.ConfigValue.ValueString()), strings.Join(topicEndpointSchemes, ", ")))
+	}
+}
+
+// ValidateConfig implements resource.ResourceWithValidateConfig.
+func (r *topicResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	// endpoint arguments can be unknown one by one
+	var pushEndpoint types.String
+	var endpointArgs map[string]types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("push_endpoint"), &pushEndpoint)...)
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("endpoint_args"), &endpointArgs)...)
+	if resp.Diagnostics.HasError() || pushEndpoint.IsNull() || pushEndpoint.IsUnknown() {
+		return
+	}
+	args := make(map[string]string, len(endpointArgs))
+	for name, value := range endpointArgs {
+		if value.IsUnknown() {
+			return
+		}
+		args[name] = value.ValueString()
+	}
+
+	u, err := url.Parse(pushEndpoint.ValueString())
+	if err != nil {
+		return
+	}
+
+	if (u.Scheme == "amqp" || u.Scheme == "amqps") && args["amqp-exchange"] == "" {
+		resp.Diagnostics.AddAttributeError(path.Root("endpoint_args"), "Missing AMQP exchange",
+			"AMQP endpoints need the exchange to publish notifications to, set it as \"amqp-exchange\" in endpoint_args.")
+	}
+
+	_, hasPassword := u.User.Password()
+	tls := u.Scheme == "https" || u.Scheme == "amqps" || (u.Scheme == "kafka" && args["use-ssl"] == "true")
+	if hasPassword && !tls {
+		resp.Diagnostics.AddAttributeWarning(path.Root("push_endpoint"), "Credentials sent in cleartext",
+			"The push endpoint has credentials but does not use TLS.  radosgw rejects such topics unless rgw_allow_notification_secrets_in_cleartext is set.")
+	}
+}
+
+// Read implements resource.Resource.
+func (r *topicResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state topicResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	out, err := r.sns.GetTopicAttributesWithContext(ctx, &sns.GetTopicAttributesInput{
+		TopicArn: aws.String(state.ARN.ValueString()),
+	})
+	if isTopicNotFound(err) {
+		tflog.Warn(ctx, "topic removed outside of Terraform", map[string]any{"arn": state.ARN.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error fetching topic",
+			fmt.Sprintf("Could not fetch topic %q", state.ARN.ValueString()), err)
+		return
+	}
+
+	if err := state.setAttributes(out.Attributes); err != nil {
+		resp.Diagnostics.AddError("Error fetching topic",
+			fmt.Sprintf("Could not decode attributes of topic %q: %s", state.ARN.ValueString(), err))
+		return
+	}
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// setAttributes sets the model from the topic attributes returned by
+// radosgw, which has the endpoint and its arguments as JSON.
+func (m *topicResourceModel) setAttributes(attributes map[string]*string) error {
+	var endpoint struct {
+		EndpointAddress string          `json:"EndpointAddress"`
+		EndpointArgs    string          `json:"EndpointArgs"`
+		Persistent      json.RawMessage `json:"Persistent"`
+	}
+	if err := json.Unmarshal([]byte(aws.StringValue(attributes["EndPoint"])), &endpoint); err != nil {
+		return fmt.Errorf("decoding endpoint: %w", err)
+	}
+	args, err := url.ParseQuery(endpoint.EndpointArgs)
+	if err != nil {
+		return fmt.Errorf("decoding endpoint arguments: %w", err)
+	}
+
+	if name := aws.StringValue(attributes["Name"]); name != "" {
+		m.Name = types.StringValue(name)
+	}
+	if arn := aws.StringValue(attributes["TopicArn"]); arn != "" {
+		m.ARN = types.StringValue(arn)
+	}
+	m.PushEndpoint = optionalString(endpoint.EndpointAddress)
+	// radosgw returns persistent as boolean or string, depending on the version
+	persistent := strings.Trim(string(endpoint.Persistent), `"`)
+	m.Persistent = types.BoolValue(persistent == "true" || (persistent == "" && args.Get("persistent") == "true"))
+	m.OpaqueData = optionalString(aws.StringValue(attributes["OpaqueData"]))
+	m.VerifySSL = types.BoolValue(args.Get("verify-ssl") != "false")
+
+	endpointArgs := make(map[string]attr.Value)
+	for _, name := range topicEndpointArgs {
+		if args.Has(name) {
+			endpointArgs[name] = types.StringValue(args.Get(name))
+		}
+	}
+	// radosgw returns no arguments both for an empty map and for none, so
+	// keep an empty map as configured
+	if len(endpointArgs) == 0 && m.EndpointArgs.IsNull() {
+		m.EndpointArgs = types.MapNull(types.StringType)
+	} else {
+		m.EndpointArgs = types.MapValueMust(types.StringType, endpointArgs)
+	}
+	return nil
+}
+
+// isTopicNotFound reports whether err is the error of radosgw for missing
+// topics, which differs between versions.
+func isTopicNotFound(err error) bool {
+	switch awsErrorCode(err) {
+	case "NotFound", "NoSuchKey", "NoSuchEntity":
+		return true
+	}
+	var reqErr awserr.RequestFailure
+	return err != nil && errors.As(err, &reqErr) && reqErr.StatusCode() == 404
+}
+
+// ImportState implements resource.ResourceWithImportState.  Topics are
+// imported by ARN or by name.
+func (r *topicResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	arn := req.ID
+	if !strings.HasPrefix(arn, "arn:") {
+		var err error
+		arn, err = r.findTopic(ctx, req.ID)
+		if err != nil {
+			resp.Diagnostics.AddError("Error importing topic", fmt.Sprintf("Could not list topics: %s", err))
+			return
+		}
+		if arn == "" {
+			resp.Diagnostics.AddError("Error importing topic",
+				fmt.Sprintf("There is no topic %q in the tenant of the provider credentials.  Import topics of other tenants by ARN.", req.ID))
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("arn"), arn)...)
+}
+
+// findTopic returns the ARN of the topic with the name, or "" if there is
+// none.
+func (r *topicResource) findTopic(ctx context.Context, name string) (string, error) {
+	var found string
+	err := r.sns.ListTopicsPagesWithContext(ctx, &sns.ListTopicsInput{}, func(page *sns.ListTopicsOutput, _ bool) bool {
+		for _, t := range page.Topics {
+			if arn := aws.StringValue(t.TopicArn); strings.HasSuffix(arn, ":"+name) {
+				found = arn
+				return false
+			}
+		}
+		return true
+	})
+	return found, err
+}
+
+// Create implements resource.Resource.
+func (r *topicResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan topicResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	out, err := r.sns.CreateTopicWithContext(ctx, &sns.CreateTopicInput{
+		Name:       aws.String(plan.Name.ValueString()),
+		Attributes: plan.attributes(),
+	})
+	if err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error creating topic",
+			fmt.Sprintf("Could not create topic %q", plan.Name.ValueString()), err)
+		return
+	}
+	plan.ARN = types.StringValue(aws.StringValue(out.TopicArn))
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.  radosgw replaces topics created
+// again with the same name, keeping their ARN.
+func (r *topicResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan topicResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	out, err := r.sns.CreateTopicWithContext(ctx, &sns.CreateTopicInput{
+		Name:       aws.String(plan.Name.ValueString()),
+		Attributes: plan.attributes(),
+	})
+	if err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error updating topic",
+			fmt.Sprintf("Could not update topic %q", plan.Name.ValueString()), err)
+		return
+	}
+	plan.ARN = types.StringValue(aws.StringValue(out.TopicArn))
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete implements resource.Resource.
+func (r *topicResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state topicResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.sns.DeleteTopicWithContext(ctx, &sns.DeleteTopicInput{
+		TopicArn: aws.String(state.ARN.ValueString()),
+	})
+	if err != nil && !isTopicNotFound(err) {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error deleting topic",
+			fmt.Sprintf("Could not delete topic %q", state.ARN.ValueString()), err)
+		return
+	}
+}
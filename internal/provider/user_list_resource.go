@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ list.ListResource              = &userListResource{}
+	_ list.ListResourceWithConfigure = &userListResource{}
+)
+
+// NewUserListResource is a helper function to simplify the provider implementation.
+func NewUserListResource() list.ListResource {
+	return &userListResource{}
+}
+
+// userListResource lists the users of radosgw.
+type userListResource struct {
+	client adminClient
+}
+
+// Configure implements list.ListResourceWithConfigure.
+func (r *userListResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
+		return
+	}
+
+	r.client = data.client
+}
+
+// Metadata returns the resource type name.
+func (r *userListResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user"
+}
+
+// ListResourceConfigSchema defines the schema for list blocks.
+func (r *userListResource) ListResourceConfigSchema(_ context.Context, _ list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Lists the users of radosgw, including tenanted users.",
+		Attributes: map[string]schema.Attribute{
+			"tenant": schema.StringAttribute{
+				MarkdownDescription: "Only list users of this tenant.",
+				Optional:            true,
+			},
+			"prefix": schema.StringAttribute{
+				MarkdownDescription: "Only list users whose ID starts with this prefix.",
+				Optional:            true,
+			},
+		},
+	}
+}
+
+type userListConfigModel struct {
+	Tenant types.String `tfsdk:"tenant"`
+	Prefix types.String `tfsdk:"prefix"`
+}
+
+// List implements list.ListResource.
+func (r *userListResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	var config userListConfigModel
+	diags := req.Config.Get(ctx, &config)
+	if diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+
+	include := func(uid string) bool {
+		tenant, userID := splitUserID(uid)
+		return (config.Tenant.IsNull() || tenant == config.Tenant.ValueString()) &&
+			strings.HasPrefix(userID, config.Prefix.ValueString())
+	}
+
+	stream.Results = func(push func(list.ListResult) bool) {
+		err := listUsers(ctx, r.client, include, func(user admin.User) bool {
+			var state userResourceModel
+			state.setUser(user)
+
+			result := req.NewListResult(ctx)
+			result.DisplayName = user.ID
+			result.Diagnostics.Append(result.Identity.Set(ctx, state.identity())...)
+			if req.IncludeResource {
+				result.Diagnostics.Append(result.Resource.Set(ctx, state)...)
+			}
+
+			return push(result)
+		})
+		if err != nil {
+			push(listErrorResult("Error listing users", err))
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                = &userPolicyResource{}
+	_ resource.ResourceWithConfigure   = &userPolicyResource{}
+	_ resource.ResourceWithImportState = &userPolicyResource{}
+)
+
+// NewUserPolicyResource is a helper function to simplify the provider implementation.
+func NewUserPolicyResource() resource.Resource {
+	return &userPolicyResource{}
+}
+
+// userPolicyResource is the resource implementation.
+type userPolicyResource struct {
+	iam iamiface.IAMAPI
+}
+
+// Configure implements resource.ResourceWithConfigure.
+func (r *userPolicyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
+		return
+	}
+
+	r.iam = iam.New(data.awsSession)
+}
+
+// Metadata returns the resource type name.
+func (r *userPolicyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_user_policy"
+}
+
+// Schema defines the schema for the resource.
+func (r *userPolicyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages an inline IAM policy of a user through the IAM API of radosgw, which radosgw evaluates for all requests of the user together with bucket policies.  " +
+			"The user of the provider credentials needs the `user-policy=*` cap.",
+		Attributes: map[string]schema.Attribute{
+			"tenant": schema.StringAttribute{
+				MarkdownDescription: "Tenant of the user, if any.",
+				Optional:            true,
+				Validators:          tenantValidators(),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"user": schema.StringAttribute{
+				MarkdownDescription: "ID of the user, for example from a radosgw_user resource.",
+				Required:            true,
+				Validators:          rgwIDValidators(),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "Name of the policy, unique among the policies of the user.",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, 128),
+					stringvalidator.RegexMatches(roleNamePattern, `must only contain letters, digits and the characters "_+=,.@-"`),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"policy": schema.StringAttribute{
+				MarkdownDescription: "JSON policy, whose statements have no principals as they apply to the user.  Differences in formatting and ordering are not reported as changes.",
+				Required:            true,
+				CustomType:          policyDocumentType{},
+				Validators: []validator.String{
+					policyValidator{rules: userPolicyRules},
+				},
+			},
+		},
+	}
+}
+
+type userPolicyResourceModel struct {
+	Tenant types.String   `tfsdk:"tenant"`
+	User   types.String   `tfsdk:"user"`
+	Name   types.String   `tfsdk:"name"`
+	Policy policyDocument `tfsdk:"policy"`
+}
+
+// uid returns the ID of the user as used by radosgw, which also takes
+// "<tenant>$<user>" as the user name of IAM API calls.
+func (m userPolicyResourceModel) uid() string {
+	return joinUserID(m.Tenant.ValueString(), m.User.ValueString())
+}
+
+// errorTarget describes the policy for explaining IAM API errors.
+func (m userPolicyResourceModel) errorTarget() rgwErrorTarget {
+	return rgwErrorTarget{
+		resourceType: "radosgw_user_policy",
+		importID:     m.uid() + ":" + m.Name.ValueString(),
+		userPath:     path.Root("user"),
+		caps:         "user-policy=*",
+		policyPath:   path.Root("policy"),
+	}
+}
+
+// Read implements resource.Resource.
+func (r *userPolicyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state userPolicyResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	out, err := r.iam.GetUserPolicyWithContext(ctx, &iam.GetUserPolicyInput{
+		UserName:   aws.String(state.uid()),
+		PolicyName: aws.String(state.Name.ValueString()),
+	})
+	if awsErrorCode(err) == iam.ErrCodeNoSuchEntityException {
+		tflog.Warn(ctx, "user policy removed outside of Terraform", map[string]any{
+			"uid":    state.uid(),
+			"policy": state.Name.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error fetching user policy",
+			fmt.Sprintf("Could not fetch policy %q of user %q", state.Name.ValueString(), state.uid()), err)
+		return
+	}
+
+	state.Policy = newPolicyDocument(decodeIAMPolicyDocument(aws.StringValue(out.PolicyDocument)))
+
+	diags = resp.State.Set(ctx, state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// ImportState implements resource.ResourceWithImportState.  Policies are
+// imported as "[<tenant>$]<user>:<name>".
+func (r *userPolicyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	uid, name, found := strings.Cut(req.ID, ":")
+	ref, err := parseUserImportID(uid)
+	if err == nil && (!found || !roleNamePattern.MatchString(name)) {
+		err = fmt.Errorf("missing policy name")
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid import ID",
+			fmt.Sprintf("Could not parse import ID %q: %s.\n\nThe import ID must be of the form \"<user>:<name>\" or \"<tenant>$<user>:<name>\".", req.ID, err),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("tenant"), optionalString(ref.Tenant))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("user"), ref.UserID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), name)...)
+}
+
+// Create implements resource.Resource.
+func (r *userPolicyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan userPolicyResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.put(ctx, plan); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error setting user policy",
+			fmt.Sprintf("Could not set policy %q of user %q", plan.Name.ValueString(), plan.uid()), err)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update implements resource.Resource.
+func (r *userPolicyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan userPolicyResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := r.put(ctx, plan); err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error updating user policy",
+			fmt.Sprintf("Could not update policy %q of user %q", plan.Name.ValueString(), plan.uid()), err)
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// put sets the policy of the user to that of plan, replacing a policy with
+// the same name.
+func (r *userPolicyResource) put(ctx context.Context, plan userPolicyResourceModel) error {
+	_, err := r.iam.PutUserPolicyWithContext(ctx, &iam.PutUserPolicyInput{
+		UserName:       aws.String(plan.uid()),
+		PolicyName:     aws.String(plan.Name.ValueString()),
+		PolicyDocument: aws.String(plan.Policy.ValueString()),
+	})
+	return err
+}
+
+// Delete implements resource.Resource.
+func (r *userPolicyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state userPolicyResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.iam.DeleteUserPolicyWithContext(ctx, &iam.DeleteUserPolicyInput{
+		UserName:   aws.String(state.uid()),
+		PolicyName: aws.String(state.Name.ValueString()),
+	})
+	if awsErrorCode(err) == iam.ErrCodeNoSuchEntityException {
+		return
+	}
+	if err != nil {
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error removing user policy",
+			fmt.Sprintf("Could not remove policy %q of user %q", state.Name.ValueString(), state.uid()), err)
+		return
+	}
+}
@@ -0,0 +1,216 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+
+	"github.com/spreadshirt/terraform-provider-radosgw/internal/rgwtest"
+)
+
+func TestAccUserPolicyResource(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + testAccUserPolicyResourceConfig("", "s3:DeleteObject"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_user_policy.test", "user", "demo"),
+					resource.TestCheckNoResourceAttr("radosgw_user_policy.test", "tenant"),
+					testAccCheckUserPolicy(server, "demo", "deny-delete", regexp.MustCompile(`"s3:DeleteObject"`)),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "radosgw_user_policy.test",
+				ImportState:                          true,
+				ImportStateId:                        "demo:deny-delete",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "name",
+			},
+			// A policy returned in a different form is no drift
+			{
+				PreConfig: func() {
+					testAccPutUserPolicy(t, server, &iam.PutUserPolicyInput{
+						UserName:       aws.String("demo"),
+						PolicyName:     aws.String("deny-delete"),
+						PolicyDocument: aws.String(`{"Statement": [{"Resource": "arn:aws:s3:::*", "Action": ["s3:DeleteObject"], "Effect": "Deny"}], "Version": "2012-10-17"}`),
+					})
+				},
+				Config:   providerConfig + testAccUserPolicyResourceConfig("", "s3:DeleteObject"),
+				PlanOnly: true,
+			},
+			// Update and Read testing
+			{
+				Config: providerConfig + testAccUserPolicyResourceConfig("", "s3:Delete*"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					testAccCheckUserPolicy(server, "demo", "deny-delete", regexp.MustCompile(`"s3:Delete\*"`)),
+				),
+			},
+			// Removed outside of Terraform
+			{
+				PreConfig: func() {
+					server.DeleteUserPolicy("demo", "deny-delete")
+				},
+				Config:             providerConfig + testAccUserPolicyResourceConfig("", "s3:Delete*"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccUserPolicyResource_tenant(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + testAccUserPolicyResourceConfig("acme", "s3:DeleteObject"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_user_policy.test", "tenant", "acme"),
+					testAccCheckUserPolicy(server, "acme$demo", "deny-delete", regexp.MustCompile(`"s3:DeleteObject"`)),
+				),
+			},
+			{
+				ResourceName:                         "radosgw_user_policy.test",
+				ImportState:                          true,
+				ImportStateId:                        "acme$demo:deny-delete",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "name",
+			},
+			{
+				ResourceName:  "radosgw_user_policy.test",
+				ImportState:   true,
+				ImportStateId: "acme$demo",
+				ExpectError:   regexp.MustCompile(`missing policy name`),
+			},
+		},
+	})
+}
+
+func TestAccUserPolicyResource_validation(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	// a user without the user-policy cap may not manage user policies
+	user, err := server.API().CreateUser(context.Background(), admin.User{ID: "ops", DisplayName: "Operator", UserCaps: "users=*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	restrictedConfig := fmt.Sprintf(`
+provider "radosgw" {
+  endpoint          = %q
+  access_key_id     = %q
+  secret_access_key = %q
+}
+`, server.URL, user.Keys[0].AccessKey, user.Keys[0].SecretKey)
+
+	const policyConfig = `
+resource "radosgw_user_policy" "test" {
+  user = "missing"
+  name = "deny-delete"
+
+  policy = jsonencode({
+    Version   = "2012-10-17"
+    Statement = [{ Effect = "Deny", Action = "s3:DeleteObject", Resource = "*" }]
+  })
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "radosgw_user_policy" "test" {
+  user = "demo"
+  name = "deny-delete"
+
+  policy = jsonencode({
+    Version   = "2012-10-17"
+    Statement = [{ Effect = "Deny", Action = "s3:DeleteObject", Resource = "*", Principal = { AWS = "arn:aws:iam:::user/demo" } }]
+  })
+}
+`,
+				ExpectError: regexp.MustCompile(`Principal is not allowed in a user policy`),
+			},
+			{
+				Config:      providerConfig + policyConfig,
+				ExpectError: regexp.MustCompile(`The user does not exist`),
+			},
+			{
+				Config:      restrictedConfig + policyConfig,
+				ExpectError: regexp.MustCompile(`user-policy=\*`),
+			},
+		},
+	})
+}
+
+func testAccUserPolicyResourceConfig(tenant, action string) string {
+	return fmt.Sprintf(`
+resource "radosgw_user" "demo" {
+  tenant       = %[1]q == "" ? null : %[1]q
+  user_id      = "demo"
+  display_name = "Demo user"
+}
+
+resource "radosgw_user_policy" "test" {
+  tenant = radosgw_user.demo.tenant
+  user   = radosgw_user.demo.user_id
+  name   = "deny-delete"
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [{
+      Effect   = "Deny"
+      Action   = [%[2]q]
+      Resource = "arn:aws:s3:::*"
+    }]
+  })
+}
+`, tenant, action)
+}
+
+// testAccPutUserPolicy sets an inline policy of a user on the fake radosgw
+// like a client outside of Terraform.
+func testAccPutUserPolicy(t *testing.T, server *rgwtest.Server, input *iam.PutUserPolicyInput) {
+	t.Helper()
+
+	session, err := newAWSSession(server.URL, server.AccessKey, server.SecretKey, func(client admin.HTTPClient) admin.HTTPClient { return client })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := iam.New(session).PutUserPolicy(input); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testAccCheckUserPolicy checks an inline policy of a user on the fake
+// radosgw.
+func testAccCheckUserPolicy(server *rgwtest.Server, uid, name string, pattern *regexp.Regexp) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		policies, ok := server.UserPolicies(uid)
+		if !ok {
+			return fmt.Errorf("user %q does not exist", uid)
+		}
+		policy, ok := policies[name]
+		if !ok {
+			return fmt.Errorf("user %q has no policy %q", uid, name)
+		}
+		if !pattern.MatchString(policy) {
+			return fmt.Errorf("policy %q of user %q does not match %s: %s", name, uid, pattern, policy)
+		}
+		return nil
+	}
+}
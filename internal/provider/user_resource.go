@@ -2,12 +2,18 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/ceph/go-ceph/rgw/admin"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -17,6 +23,7 @@ var (
 	_ resource.Resource                = &userResource{}
 	_ resource.ResourceWithConfigure   = &userResource{}
 	_ resource.ResourceWithImportState = &userResource{}
+	_ resource.ResourceWithIdentity    = &userResource{}
 )
 
 // NewUserResource is a helper function to simplify the provider implementation.
@@ -26,27 +33,21 @@ func NewUserResource() resource.Resource {
 
 // userResource is the resource implementation.
 type userResource struct {
-	client *admin.API
+	client adminClient
+
+	// adoptExisting is the provider default for adopting existing users.
+	adoptExisting bool
 }
 
 // Configure implements resource.ResourceWithConfigure.
 func (r *userResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
-	// Prevent panic if the provider has not been configured.
-	if req.ProviderData == nil {
-		return
-	}
-
-	client, ok := req.ProviderData.(*admin.API)
-	if !ok {
-		resp.Diagnostics.AddError(
-			"Unexpected Data Source Configure Type",
-			fmt.Sprintf("Expected *admin.API, got: %T. Please report this issue to the provider developers.", req.ProviderData),
-		)
-
+	data := configureProviderData(req.ProviderData, &resp.Diagnostics)
+	if data == nil {
 		return
 	}
 
-	r.client = client
+	r.client = data.client
+	r.adoptExisting = data.adoptExisting
 }
 
 // Metadata returns the resource type name.
@@ -57,20 +58,109 @@ func (r *userResource) Metadata(_ context.Context, req resource.MetadataRequest,
 // Schema defines the schema for the resource.
 func (r *userResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
+		MarkdownDescription: "Manages a radosgw user.  Only the display name and the suspension state are managed, other settings such as email, quotas, caps and keys are left untouched on update.",
 		Attributes: map[string]schema.Attribute{
+			"tenant": schema.StringAttribute{
+				MarkdownDescription: "Tenant of the user, if any.",
+				Optional:            true,
+				Validators:          tenantValidators(),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
 			"user_id": schema.StringAttribute{
-				Required: true,
+				Required:   true,
+				Validators: rgwIDValidators(),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"display_name": schema.StringAttribute{
 				Required: true,
 			},
+			"suspended": schema.BoolAttribute{
+				MarkdownDescription: "Whether the user is suspended.  A suspended user keeps all its data, but neither the user nor any of its subusers and keys can access radosgw until it is enabled again.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(false),
+			},
+			"adopt_existing": adoptExistingAttribute("user"),
+		},
+	}
+}
+
+// IdentitySchema defines the identity of the resource.
+func (r *userResource) IdentitySchema(_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = identityschema.Schema{
+		Attributes: map[string]identityschema.Attribute{
+			"tenant": identityschema.StringAttribute{
+				Description:       "Tenant of the user, if any.",
+				OptionalForImport: true,
+			},
+			"user_id": identityschema.StringAttribute{
+				RequiredForImport: true,
+			},
 		},
 	}
 }
 
 type userResourceModel struct {
+	Tenant      types.String `tfsdk:"tenant"`
 	UserID      types.String `tfsdk:"user_id"`
 	DisplayName types.String `tfsdk:"display_name"`
+	Suspended   types.Bool   `tfsdk:"suspended"`
+
+	AdoptExisting types.Bool `tfsdk:"adopt_existing"`
+}
+
+// ref returns the user of m.
+func (m userResourceModel) ref() userRef {
+	return userRef{Tenant: m.Tenant.ValueString(), UserID: m.UserID.ValueString()}
+}
+
+// uid returns the ID of the user as used by the admin API.
+func (m userResourceModel) uid() string {
+	return m.ref().uid()
+}
+
+// errorTarget describes the user for explaining admin API errors.
+func (m userResourceModel) errorTarget() rgwErrorTarget {
+	return rgwErrorTarget{
+		resourceType: "radosgw_user",
+		importID:     m.uid(),
+		userPath:     path.Root("user_id"),
+	}
+}
+
+// identity returns the resource identity of the user.
+func (m userResourceModel) identity() userIdentityModel {
+	return userIdentityModel{
+		Tenant: m.Tenant,
+		UserID: m.UserID,
+	}
+}
+
+// setUser sets the attributes managed by radosgw_user from user.
+func (m *userResourceModel) setUser(user admin.User) {
+	ref := parseUserRef(user.ID)
+	m.Tenant = optionalString(ref.Tenant)
+	m.UserID = types.StringValue(ref.UserID)
+	m.DisplayName = types.StringValue(user.DisplayName)
+	m.Suspended = types.BoolValue(isSuspended(user))
+}
+
+// suspendedValue converts the suspended flag to the integer representation used by the admin API.
+func suspendedValue(suspended bool) *int {
+	value := 0
+	if suspended {
+		value = 1
+	}
+	return &value
+}
+
+// isSuspended reports whether the admin API marks the user as suspended.
+func isSuspended(user admin.User) bool {
+	return user.Suspended != nil && *user.Suspended != 0
 }
 
 // Create creates the resource and sets the initial Terraform state.
@@ -83,27 +173,60 @@ func (r *userResource) Create(ctx context.Context, req resource.CreateRequest, r
 	}
 
 	user := admin.User{
-		ID:          plan.UserID.ValueString(),
+		ID:          plan.uid(),
 		DisplayName: plan.DisplayName.ValueString(),
+		Suspended:   suspendedValue(plan.Suspended.ValueBool()),
 	}
 
 	user, err := r.client.CreateUser(ctx, user)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error creating user",
-			"Could not create user, unexpected error: "+err.Error(),
-		)
+	if errors.Is(err, admin.ErrUserExists) && shouldAdopt(plan.AdoptExisting, r.adoptExisting) {
+		user, err = r.adopt(ctx, plan, &resp.Diagnostics)
+		if err != nil {
+			addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error adopting user",
+				fmt.Sprintf("Could not adopt existing user %q", plan.uid()), err)
+			return
+		}
+	} else if err != nil {
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error creating user",
+			fmt.Sprintf("Could not create user %q", plan.uid()), err)
 		return
 	}
 
-	plan.UserID = types.StringValue(user.ID)
-	plan.DisplayName = types.StringValue(user.DisplayName)
+	plan.setUser(user)
 
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	diags = resp.Identity.Set(ctx, plan.identity())
+	resp.Diagnostics.Append(diags...)
+}
+
+// adopt takes over the existing user of plan, modifying the fields that
+// differ from the plan.
+func (r *userResource) adopt(ctx context.Context, plan userResourceModel, diags *diag.Diagnostics) (admin.User, error) {
+	user, err := r.client.GetUser(ctx, admin.User{ID: plan.uid()})
+	if err != nil {
+		return admin.User{}, err
+	}
+
+	modifiedUser, changed := userModifications(user, plan)
+	if len(changed) > 0 {
+		user, err = r.client.ModifyUser(ctx, modifiedUser)
+		if err != nil {
+			return admin.User{}, err
+		}
+	}
+
+	tflog.Warn(ctx, "adopted existing user", map[string]any{"user_id": user.ID, "fields": changed})
+	diags.AddWarning(
+		"Adopted existing user",
+		fmt.Sprintf("User %q already existed and is now managed by Terraform.%s", user.ID, adoptedChanges(changed)),
+	)
+
+	return user, nil
 }
 
 // Read refreshes the Terraform state with the latest data.
@@ -115,30 +238,92 @@ func (r *userResource) Read(ctx context.Context, req resource.ReadRequest, resp
 		return
 	}
 
-	user, err := r.client.GetUser(ctx, admin.User{
-		ID: state.UserID.ValueString(),
-	})
+	user, err := r.client.GetUser(ctx, admin.User{ID: state.uid()})
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error reading user",
-			"Could not read user "+state.UserID.ValueString()+": "+err.Error(),
-		)
+		addRGWError(&resp.Diagnostics, state.errorTarget(), "Error reading user",
+			fmt.Sprintf("Could not read user %q", state.uid()), err)
 		return
 	}
 
-	state.UserID = types.StringValue(user.ID)
-	state.DisplayName = types.StringValue(user.DisplayName)
+	state.setUser(user)
 
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	diags = resp.Identity.Set(ctx, state.identity())
+	resp.Diagnostics.Append(diags...)
 }
 
+// ImportState implements resource.ResourceWithImportState.  Users are
+// imported by their ID "[<tenant>$]<user>" or by their identity.
 func (r *userResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	// Retrieve import ID and save to id attribute
-	resource.ImportStatePassthroughID(ctx, path.Root("user_id"), req, resp)
+	var id userRef
+	if req.ID == "" {
+		var identity userIdentityModel
+		diags := req.Identity.Get(ctx, &identity)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		id = identity.ref()
+	} else {
+		var err error
+		id, err = parseUserImportID(req.ID)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Invalid user import ID",
+				fmt.Sprintf("Could not parse import ID %q: %s.\n\nThe import ID must be of the form \"<user>\" or \"<tenant>$<user>\".", req.ID, err),
+			)
+			return
+		}
+	}
+
+	state := userResourceModel{
+		Tenant: optionalString(id.Tenant),
+		UserID: types.StringValue(id.UserID),
+	}
+
+	diags := resp.State.SetAttribute(ctx, path.Root("tenant"), state.Tenant)
+	resp.Diagnostics.Append(diags...)
+	diags = resp.State.SetAttribute(ctx, path.Root("user_id"), state.UserID)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.Identity.Set(ctx, state.identity())
+	resp.Diagnostics.Append(diags...)
+}
+
+// userModifications returns a ModifyUser request for current that only
+// contains the fields owned by radosgw_user that differ from plan, and the
+// names of those fields.
+//
+// radosgw_user owns the display name and the suspended flag.  All other
+// fields (email, max buckets, op mask, placement, caps, quotas, keys and
+// subusers) are ignored, so that they can be managed manually or by other
+// resources without being reset on update.
+//
+// The request is built from scratch rather than from current, as ModifyUser
+// would otherwise also send parameters derived from unrelated fields, such
+// as the access and secret keys of the user.
+func userModifications(current admin.User, plan userResourceModel) (admin.User, []string) {
+	modified := admin.User{ID: current.ID}
+
+	var changed []string
+	if current.DisplayName != plan.DisplayName.ValueString() {
+		modified.DisplayName = plan.DisplayName.ValueString()
+		changed = append(changed, "display-name")
+	}
+	if isSuspended(current) != plan.Suspended.ValueBool() {
+		modified.Suspended = suspendedValue(plan.Suspended.ValueBool())
+		changed = append(changed, "suspended")
+	}
+
+	return modified, changed
 }
 
 // Update updates the resource and sets the updated Terraform state on success.
@@ -150,40 +335,35 @@ func (r *userResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		return
 	}
 
-	user, err := r.client.GetUser(ctx, admin.User{ID: plan.UserID.ValueString()})
+	user, err := r.client.GetUser(ctx, admin.User{ID: plan.uid()})
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error retrieving user",
-			"Could not retrieve user, unexpected error: "+err.Error(),
-		)
+		addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error retrieving user",
+			fmt.Sprintf("Could not retrieve user %q", plan.uid()), err)
 		return
 	}
 
-	user.ID = plan.UserID.ValueString()
-	user.DisplayName = plan.DisplayName.ValueString()
-
-	user = admin.User{
-		ID:          plan.UserID.ValueString(),
-		DisplayName: plan.DisplayName.ValueString(),
-	}
+	modifiedUser, changed := userModifications(user, plan)
+	if len(changed) > 0 {
+		tflog.Debug(ctx, "modifying user", map[string]any{"user_id": user.ID, "fields": changed})
 
-	user, err = r.client.ModifyUser(ctx, user)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error update user",
-			"Could not update user, unexpected error: "+err.Error(),
-		)
-		return
+		user, err = r.client.ModifyUser(ctx, modifiedUser)
+		if err != nil {
+			addRGWError(&resp.Diagnostics, plan.errorTarget(), "Error updating user",
+				fmt.Sprintf("Could not update user %q", plan.uid()), err)
+			return
+		}
 	}
 
-	plan.UserID = types.StringValue(user.ID)
-	plan.DisplayName = types.StringValue(user.DisplayName)
+	plan.setUser(user)
 
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	diags = resp.Identity.Set(ctx, plan.identity())
+	resp.Diagnostics.Append(diags...)
 }
 
 // Delete deletes the resource and removes the Terraform state on success.
@@ -0,0 +1,287 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+
+	"github.com/spreadshirt/terraform-provider-radosgw/internal/rgwtest"
+)
+
+func TestAccUserResource(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			// Create and Read testing
+			{
+				Config: providerConfig + testAccUserResourceConfig("Demo user", false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_user.test", "user_id", "demo"),
+					resource.TestCheckResourceAttr("radosgw_user.test", "display_name", "Demo user"),
+					resource.TestCheckResourceAttr("radosgw_user.test", "suspended", "false"),
+				),
+			},
+			// ImportState testing
+			{
+				ResourceName:                         "radosgw_user.test",
+				ImportState:                          true,
+				ImportStateId:                        "demo",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "user_id",
+			},
+			// Update and Read testing
+			{
+				PreConfig: func() {
+					// changed outside of Terraform, must be preserved on update
+					maxBuckets := 5
+					if _, err := server.API().ModifyUser(context.Background(), admin.User{ID: "demo", Email: "demo@example.com", MaxBuckets: &maxBuckets}); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: providerConfig + testAccUserResourceConfig("Suspended demo user", true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_user.test", "display_name", "Suspended demo user"),
+					resource.TestCheckResourceAttr("radosgw_user.test", "suspended", "true"),
+					testAccCheckUser(server, "demo", func(user admin.User) error {
+						if user.Email != "demo@example.com" || user.MaxBuckets == nil || *user.MaxBuckets != 5 {
+							return fmt.Errorf("unmanaged fields were not preserved: %+v", user)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccUserResource_tenant(t *testing.T) {
+	_, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + `
+resource "radosgw_user" "test" {
+  tenant       = "acme"
+  user_id      = "demo"
+  display_name = "Demo user"
+}
+`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_user.test", "tenant", "acme"),
+					resource.TestCheckResourceAttr("radosgw_user.test", "user_id", "demo"),
+				),
+			},
+			{
+				ResourceName:                         "radosgw_user.test",
+				ImportState:                          true,
+				ImportStateId:                        "acme$demo",
+				ImportStateVerify:                    true,
+				ImportStateVerifyIdentifierAttribute: "user_id",
+			},
+		},
+	})
+}
+
+func TestAccUserResource_identity(t *testing.T) {
+	_, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_12_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: providerConfig + testAccUserResourceConfig("Demo user", false),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectIdentity("radosgw_user.test", map[string]knownvalue.Check{
+						"tenant":  knownvalue.Null(),
+						"user_id": knownvalue.StringExact("demo"),
+					}),
+				},
+			},
+			{
+				ResourceName:    "radosgw_user.test",
+				ImportState:     true,
+				ImportStateKind: resource.ImportBlockWithResourceIdentity,
+			},
+		},
+	})
+}
+
+func TestAccUserResource_validation(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+	config := func(tenant, userID string) string {
+		return providerConfig + fmt.Sprintf(`
+resource "radosgw_user" "test" {
+  tenant       = %q
+  user_id      = %q
+  display_name = "Demo user"
+}
+`, tenant, userID)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      config("acme", "acme$demo"),
+				ExpectError: regexp.MustCompile(`must\s+not\s+contain\s+"\$"\s+or\s+":"`),
+			},
+			{
+				Config:      config("ac-me", "demo"),
+				ExpectError: regexp.MustCompile(`must\s+only\s+contain\s+letters,\s+digits\s+and\s+"_"`),
+			},
+			// radosgw accepts user IDs with any other characters
+			{
+				Config: config("acme_1", "j.doe/ops#1"),
+				Check:  testAccCheckUser(server, "acme_1$j.doe/ops#1", func(admin.User) error { return nil }),
+			},
+		},
+	})
+}
+
+// TestUserResourceRead_legacyTenantedID checks that state of a user given as
+// "<tenant>$<user>" in user_id, as before the tenant attribute existed, is
+// moved to tenant on refresh, so that the configuration can be changed to
+// set tenant without replacing the user.
+func TestUserResourceRead_legacyTenantedID(t *testing.T) {
+	server := rgwtest.NewServer()
+	defer server.Close()
+	if _, err := server.API().CreateUser(context.Background(), admin.User{ID: "acme$demo", DisplayName: "Demo user"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := testReadResource(t, &userResource{client: server.API()}, userResourceModel{
+		Tenant:        types.StringNull(),
+		UserID:        types.StringValue("acme$demo"),
+		DisplayName:   types.StringValue("Demo user"),
+		Suspended:     types.BoolValue(false),
+		AdoptExisting: types.BoolNull(),
+	})
+	if got.Tenant.ValueString() != "acme" || got.UserID.ValueString() != "demo" {
+		t.Errorf("got tenant %s and user_id %s, want acme and demo", got.Tenant, got.UserID)
+	}
+}
+
+// testReadResource runs Read of r on state and returns the state read.
+func testReadResource[T any](t *testing.T, r fwresource.ResourceWithIdentity, state T) T {
+	t.Helper()
+	ctx := context.Background()
+
+	var schemaResp fwresource.SchemaResponse
+	r.Schema(ctx, fwresource.SchemaRequest{}, &schemaResp)
+	var identityResp fwresource.IdentitySchemaResponse
+	r.IdentitySchema(ctx, fwresource.IdentitySchemaRequest{}, &identityResp)
+
+	req := fwresource.ReadRequest{
+		State: tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)},
+	}
+	if diags := req.State.Set(ctx, state); diags.HasError() {
+		t.Fatal(diags)
+	}
+	resp := fwresource.ReadResponse{
+		State:    req.State,
+		Identity: &tfsdk.ResourceIdentity{Schema: identityResp.IdentitySchema, Raw: tftypes.NewValue(identityResp.IdentitySchema.Type().TerraformType(ctx), nil)},
+	}
+	r.Read(ctx, req, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+
+	var got T
+	if diags := resp.State.Get(ctx, &got); diags.HasError() {
+		t.Fatal(diags)
+	}
+	return got
+}
+
+func testAccUserResourceConfig(displayName string, suspended bool) string {
+	return fmt.Sprintf(`
+resource "radosgw_user" "test" {
+  user_id      = "demo"
+  display_name = %q
+  suspended    = %t
+}
+`, displayName, suspended)
+}
+
+// testAccCheckUser runs check against the user as stored by the fake radosgw.
+func testAccCheckUser(server *rgwtest.Server, uid string, check func(admin.User) error) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		user, err := server.API().GetUser(context.Background(), admin.User{ID: uid})
+		if err != nil {
+			return err
+		}
+		return check(user)
+	}
+}
+
+func TestAccUserResource_exists(t *testing.T) {
+	server, providerConfig := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck: func() {
+			if _, err := server.API().CreateUser(context.Background(), admin.User{ID: "demo", DisplayName: "Demo user"}); err != nil {
+				t.Fatal(err)
+			}
+		},
+		Steps: []resource.TestStep{
+			{
+				Config:      providerConfig + testAccUserResourceConfig("Demo user", false),
+				ExpectError: regexp.MustCompile(`(?s)The user already exists.*terraform import radosgw_user.<name> 'demo'`),
+			},
+		},
+	})
+}
+
+func TestAccUserResource_adoptExisting(t *testing.T) {
+	server, _ := testAccServer(t)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: testAccProtoV6ProviderFactories,
+		PreCheck: func() {
+			if _, err := server.API().CreateUser(context.Background(), admin.User{ID: "demo", DisplayName: "Hand-made user", Email: "demo@example.com"}); err != nil {
+				t.Fatal(err)
+			}
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "radosgw" {
+  endpoint          = %q
+  access_key_id     = %q
+  secret_access_key = %q
+  adopt_existing    = true
+}
+`, server.URL, server.AccessKey, server.SecretKey) + testAccUserResourceConfig("Demo user", false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("radosgw_user.test", "display_name", "Demo user"),
+					testAccCheckUser(server, "demo", func(user admin.User) error {
+						if user.DisplayName != "Demo user" || user.Email != "demo@example.com" {
+							return fmt.Errorf("user was not adopted: %+v", user)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
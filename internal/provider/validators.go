@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// rgwIDPattern matches the user and subuser IDs accepted by radosgw.
+//
+// radosgw accepts any other character, but uses "$" to separate the tenant
+// from the user ("tenant$user") and ":" to separate the user from the
+// subuser ("user:subuser").
+var rgwIDPattern = regexp.MustCompile(`^[^$:]+$`)
+
+// rgwIDValidators returns the validators for user and subuser IDs.  radosgw
+// does not limit their length.
+func rgwIDValidators() []validator.String {
+	return []validator.String{
+		stringvalidator.LengthAtLeast(1),
+		stringvalidator.RegexMatches(
+			rgwIDPattern,
+			`must not contain "$" or ":", which radosgw reserves to separate tenants, users and subusers (set tenant for users of a tenant)`,
+		),
+	}
+}
+
+// tenantPattern matches the tenant names accepted by radosgw.
+var tenantPattern = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// tenantValidators returns the validators for tenant names.
+func tenantValidators() []validator.String {
+	return []validator.String{
+		stringvalidator.LengthAtLeast(1),
+		stringvalidator.RegexMatches(
+			tenantPattern,
+			`must only contain letters, digits and "_"`,
+		),
+	}
+}
+
+// timestampValidator checks that a string is an RFC 3339 timestamp, such as
+// "2030-01-01T00:00:00Z".
+type timestampValidator struct{}
+
+var _ validator.String = timestampValidator{}
+
+// Description implements validator.Describer.
+func (v timestampValidator) Description(_ context.Context) string {
+	return `must be an RFC 3339 timestamp, such as "2030-01-01T00:00:00Z"`
+}
+
+// MarkdownDescription implements validator.Describer.
+func (v timestampValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString implements validator.String.
+func (v timestampValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if _, err := time.Parse(time.RFC3339, req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid timestamp", fmt.Sprintf("Value %q %s.", req.ConfigValue.ValueString(), v.Description(ctx)))
+	}
+}
@@ -0,0 +1,147 @@
+package rgwtest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+)
+
+// Group URIs of ACL grants to all users and to authenticated users.
+const (
+	allUsersGroup           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	authenticatedUsersGroup = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+)
+
+var aclPermissions = []string{"FULL_CONTROL", "READ", "WRITE", "READ_ACP", "WRITE_ACP"}
+
+type aclGrant struct {
+	Grantee struct {
+		Type string `xml:"type,attr"`
+		ID   string `xml:"ID"`
+		URI  string `xml:"URI"`
+	} `xml:"Grantee"`
+	Permission string `xml:"Permission"`
+}
+
+// cannedACLGrants returns the grants of a canned ACL besides the full
+// control of the owner, and whether radosgw knows the canned ACL.
+func cannedACLGrants(canned string) ([]aclGrant, bool) {
+	group := func(uri, permission string) aclGrant {
+		var g aclGrant
+		g.Grantee.Type, g.Grantee.URI, g.Permission = "Group", uri, permission
+		return g
+	}
+
+	switch canned {
+	case "private":
+		return nil, true
+	case "public-read":
+		return []aclGrant{group(allUsersGroup, "READ")}, true
+	case "public-read-write":
+		return []aclGrant{group(allUsersGroup, "READ"), group(allUsersGroup, "WRITE")}, true
+	case "authenticated-read":
+		return []aclGrant{group(authenticatedUsersGroup, "READ")}, true
+	}
+	return nil, false
+}
+
+// parseACLGrants returns the grants of an access control policy.
+func parseACLGrants(body []byte) ([]aclGrant, error) {
+	var policy struct {
+		Grants []aclGrant `xml:"AccessControlList>Grant"`
+	}
+	err := xml.Unmarshal(body, &policy)
+	return policy.Grants, err
+}
+
+// validateACL checks an ACL like radosgw, which accepts either a canned
+// ACL or grants to existing users and the known groups.
+func validateACL(s *Server, _ *bucket, header http.Header, body []byte) string {
+	if canned := header.Get("X-Amz-Acl"); canned != "" {
+		if len(bytes.TrimSpace(body)) > 0 {
+			return "InvalidRequest"
+		}
+		if _, ok := cannedACLGrants(canned); !ok {
+			return "InvalidArgument"
+		}
+		return ""
+	}
+
+	grants, err := parseACLGrants(body)
+	if err != nil {
+		return "MalformedACLError"
+	}
+	for _, g := range grants {
+		if !containsString(aclPermissions, g.Permission) {
+			return "MalformedACLError"
+		}
+		switch g.Grantee.Type {
+		case "CanonicalUser":
+			if _, ok := s.users[g.Grantee.ID]; !ok {
+				return "InvalidArgument"
+			}
+		case "Group":
+			if g.Grantee.URI != allUsersGroup && g.Grantee.URI != authenticatedUsersGroup {
+				return "InvalidArgument"
+			}
+		default:
+			return "MalformedACLError"
+		}
+	}
+	return ""
+}
+
+// normalizeACL returns the ACL as radosgw returns it, with the canned ACL
+// expanded into grants and the display names of the users.
+func normalizeACL(s *Server, b *bucket, header http.Header, body []byte) []byte {
+	if canned := header.Get("X-Amz-Acl"); canned != "" {
+		grants, _ := cannedACLGrants(canned)
+		return s.aclDocument(b, append(ownerGrant(b), grants...))
+	}
+
+	grants, _ := parseACLGrants(body)
+	return s.aclDocument(b, grants)
+}
+
+// initialACL returns the ACL of a bucket without one, which grants full
+// control to its owner.
+func initialACL(s *Server, b *bucket) []byte {
+	return s.aclDocument(b, ownerGrant(b))
+}
+
+func ownerGrant(b *bucket) []aclGrant {
+	var g aclGrant
+	g.Grantee.Type, g.Grantee.ID, g.Permission = "CanonicalUser", b.Owner, "FULL_CONTROL"
+	return []aclGrant{g}
+}
+
+// aclDocument renders the access control policy of the bucket with grants.
+func (s *Server) aclDocument(b *bucket, grants []aclGrant) []byte {
+	displayName := func(id string) string {
+		if u, ok := s.users[id]; ok {
+			return u.DisplayName
+		}
+		return ""
+	}
+	escape := func(v string) string {
+		var buf bytes.Buffer
+		_ = xml.EscapeText(&buf, []byte(v))
+		return buf.String()
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<AccessControlPolicy xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Owner><ID>%s</ID><DisplayName>%s</DisplayName></Owner><AccessControlList>`,
+		escape(b.Owner), escape(displayName(b.Owner)))
+	for _, g := range grants {
+		buf.WriteString(`<Grant><Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="` + g.Grantee.Type + `">`)
+		if g.Grantee.Type == "Group" {
+			fmt.Fprintf(&buf, "<URI>%s</URI>", escape(g.Grantee.URI))
+		} else {
+			fmt.Fprintf(&buf, "<ID>%s</ID><DisplayName>%s</DisplayName>", escape(g.Grantee.ID), escape(displayName(g.Grantee.ID)))
+		}
+		fmt.Fprintf(&buf, "</Grantee><Permission>%s</Permission></Grant>", g.Permission)
+	}
+	buf.WriteString(`</AccessControlList></AccessControlPolicy>`)
+	return buf.Bytes()
+}
@@ -0,0 +1,381 @@
+package rgwtest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+)
+
+type bucket struct {
+	Name       string
+	Owner      string
+	ID         string
+	Created    time.Time
+	NumObjects uint64
+	Size       uint64
+	Quota      quota
+
+	// Subresources are the documents of the S3 bucket subresources, such
+	// as "policy", by name.
+	Subresources map[string][]byte
+}
+
+// bucketInfo is the representation of a bucket returned by the admin API.
+type bucketInfo struct {
+	Bucket        string      `json:"bucket"`
+	NumShards     uint64      `json:"num_shards"`
+	Tenant        string      `json:"tenant"`
+	Zonegroup     string      `json:"zonegroup"`
+	PlacementRule string      `json:"placement_rule"`
+	ID            string      `json:"id"`
+	Marker        string      `json:"marker"`
+	IndexType     string      `json:"index_type"`
+	Owner         string      `json:"owner"`
+	Ver           string      `json:"ver"`
+	MasterVer     string      `json:"master_ver"`
+	Mtime         string      `json:"mtime"`
+	MaxMarker     string      `json:"max_marker"`
+	Usage         bucketUsage `json:"usage"`
+	BucketQuota   quota       `json:"bucket_quota"`
+}
+
+type bucketUsage struct {
+	RgwMain *bucketUsageCategory `json:"rgw.main,omitempty"`
+}
+
+type bucketUsageCategory struct {
+	Size           uint64 `json:"size"`
+	SizeActual     uint64 `json:"size_actual"`
+	SizeUtilized   uint64 `json:"size_utilized"`
+	SizeKb         uint64 `json:"size_kb"`
+	SizeKbActual   uint64 `json:"size_kb_actual"`
+	SizeKbUtilized uint64 `json:"size_kb_utilized"`
+	NumObjects     uint64 `json:"num_objects"`
+}
+
+func (b *bucket) info() bucketInfo {
+	tenant, _ := splitTenant(b.Owner)
+
+	info := bucketInfo{
+		Bucket:        b.Name,
+		NumShards:     11,
+		Tenant:        tenant,
+		PlacementRule: defaultPlacement,
+		ID:            b.ID,
+		Marker:        b.ID,
+		IndexType:     "Normal",
+		Owner:         b.Owner,
+		Ver:           "0#1",
+		MasterVer:     "0#0",
+		Mtime:         b.Created.UTC().Format("2006-01-02T15:04:05.000000Z"),
+		MaxMarker:     "0#",
+		BucketQuota:   b.Quota,
+	}
+	if b.NumObjects > 0 {
+		info.Usage.RgwMain = &bucketUsageCategory{
+			Size:           b.Size,
+			SizeActual:     b.Size,
+			SizeUtilized:   b.Size,
+			SizeKb:         b.Size / 1024,
+			SizeKbActual:   b.Size / 1024,
+			SizeKbUtilized: b.Size / 1024,
+			NumObjects:     b.NumObjects,
+		}
+	}
+
+	return info
+}
+
+func splitTenant(uid string) (tenant, user string) {
+	tenant, user, found := strings.Cut(uid, "$")
+	if !found {
+		return "", uid
+	}
+	return tenant, user
+}
+
+// CreateBucket creates a bucket owned by the given user, as buckets can not
+// be created using the admin API.
+func (s *Server) CreateBucket(name, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[owner]; !ok {
+		return fmt.Errorf("rgwtest: owner %q of bucket %q does not exist", owner, name)
+	}
+	if _, ok := s.buckets[name]; ok {
+		return fmt.Errorf("rgwtest: bucket %q already exists", name)
+	}
+
+	s.buckets[name] = &bucket{
+		Name:    name,
+		Owner:   owner,
+		ID:      fmt.Sprintf("%s.%d", randomString(accessKeyAlphabet, 8), len(s.buckets)+1),
+		Created: time.Now(),
+		Quota:   disabledQuota(),
+	}
+
+	return nil
+}
+
+// PutObjects records objects of the given total size in a bucket, which
+// shows up in the bucket stats and prevents removing the bucket without
+// purging its objects.
+func (s *Server) PutObjects(name string, count, size uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[name]
+	if !ok {
+		return fmt.Errorf("rgwtest: bucket %q does not exist", name)
+	}
+
+	b.NumObjects += count
+	b.Size += size
+
+	return nil
+}
+
+func (s *Server) sortedBuckets() []*bucket {
+	buckets := make([]*bucket, 0, len(s.buckets))
+	for _, b := range s.buckets {
+		buckets = append(buckets, b)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Name < buckets[j].Name })
+	return buckets
+}
+
+func (s *Server) lookupBucket(r *http.Request) (*bucket, *apiError) {
+	name := r.URL.Query().Get("bucket")
+	if name == "" {
+		return nil, errorf(http.StatusBadRequest, admin.ErrInvalidArgument)
+	}
+
+	b, ok := s.buckets[name]
+	if !ok {
+		return nil, errorf(http.StatusNotFound, admin.ErrNoSuchBucket)
+	}
+
+	return b, nil
+}
+
+func (s *Server) handleBucket(r *http.Request) (any, *apiError) {
+	query := r.URL.Query()
+
+	switch r.Method {
+	case http.MethodGet:
+		if query.Has("policy") {
+			return s.getBucketPolicy(r)
+		}
+		if query.Get("bucket") != "" {
+			b, apiErr := s.lookupBucket(r)
+			if apiErr != nil {
+				return nil, apiErr
+			}
+			return b.info(), nil
+		}
+		return s.listBuckets(r)
+	case http.MethodPut:
+		if query.Has("quota") {
+			b, apiErr := s.lookupBucket(r)
+			if apiErr != nil {
+				return nil, apiErr
+			}
+			return nil, updateQuota(r, &b.Quota)
+		}
+		return s.linkBucket(r)
+	case http.MethodPost:
+		return s.unlinkBucket(r)
+	case http.MethodDelete:
+		b, apiErr := s.lookupBucket(r)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		if b.NumObjects > 0 && !boolParam(r, "purge-objects", false) {
+			return nil, errorf(http.StatusConflict, admin.ErrBucketNotEmpty)
+		}
+		delete(s.buckets, b.Name)
+		return nil, nil
+	}
+
+	return nil, &apiError{status: http.StatusMethodNotAllowed, code: "MethodNotAllowed"}
+}
+
+func (s *Server) listBuckets(r *http.Request) (any, *apiError) {
+	uid := r.URL.Query().Get("uid")
+	if uid != "" {
+		if _, ok := s.users[uid]; !ok {
+			return nil, errorf(http.StatusNotFound, admin.ErrNoSuchUser)
+		}
+	}
+
+	var buckets []*bucket
+	for _, b := range s.sortedBuckets() {
+		if uid == "" || b.Owner == uid {
+			buckets = append(buckets, b)
+		}
+	}
+
+	if boolParam(r, "stats", false) {
+		infos := make([]bucketInfo, 0, len(buckets))
+		for _, b := range buckets {
+			infos = append(infos, b.info())
+		}
+		return infos, nil
+	}
+
+	names := make([]string, 0, len(buckets))
+	for _, b := range buckets {
+		names = append(names, b.Name)
+	}
+	return names, nil
+}
+
+func (s *Server) getBucketPolicy(r *http.Request) (any, *apiError) {
+	b, apiErr := s.lookupBucket(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	owner := s.users[b.Owner]
+	displayName := ""
+	if owner != nil {
+		displayName = owner.DisplayName
+	}
+
+	const fullControl = 15
+	type grant struct {
+		ID    string `json:"id"`
+		Grant struct {
+			Type struct {
+				Type int `json:"type"`
+			} `json:"type"`
+			ID         string `json:"id"`
+			Email      string `json:"email"`
+			Permission struct {
+				Flags int `json:"flags"`
+			} `json:"permission"`
+			Name    string `json:"name"`
+			Group   int    `json:"group"`
+			URLSpec string `json:"url_spec"`
+		} `json:"grant"`
+	}
+	var ownerGrant grant
+	ownerGrant.ID = b.Owner
+	ownerGrant.Grant.ID = b.Owner
+	ownerGrant.Grant.Name = displayName
+	ownerGrant.Grant.Permission.Flags = fullControl
+
+	type aclUser struct {
+		User string `json:"user"`
+		ACL  int    `json:"acl"`
+	}
+
+	return map[string]any{
+		"acl": map[string]any{
+			"acl_user_map":  []aclUser{{User: b.Owner, ACL: fullControl}},
+			"acl_group_map": []any{},
+			"grant_map":     []grant{ownerGrant},
+		},
+		"owner": map[string]string{
+			"id":           b.Owner,
+			"display_name": displayName,
+		},
+	}, nil
+}
+
+func (s *Server) linkBucket(r *http.Request) (any, *apiError) {
+	b, apiErr := s.lookupBucket(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	u, apiErr := s.lookupUser(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	b.Owner = u.ID
+
+	return nil, nil
+}
+
+func (s *Server) unlinkBucket(r *http.Request) (any, *apiError) {
+	b, apiErr := s.lookupBucket(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	u, apiErr := s.lookupUser(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if b.Owner != u.ID {
+		return nil, errorf(http.StatusNotFound, admin.ErrNoSuchBucket)
+	}
+
+	b.Owner = ""
+
+	return nil, nil
+}
+
+func (s *Server) handleUsage(r *http.Request) (any, *apiError) {
+	switch r.Method {
+	case http.MethodGet:
+		type usageSummary struct {
+			User       string `json:"user"`
+			Categories []any  `json:"categories"`
+			Total      struct {
+				BytesSent     uint64 `json:"bytes_sent"`
+				BytesReceived uint64 `json:"bytes_received"`
+				Ops           uint64 `json:"ops"`
+				SuccessfulOps uint64 `json:"successful_ops"`
+			} `json:"total"`
+		}
+
+		uid := r.URL.Query().Get("uid")
+		summary := []usageSummary{}
+		if boolParam(r, "show-summary", true) {
+			for _, u := range s.sortedUsers() {
+				if uid == "" || u.ID == uid {
+					summary = append(summary, usageSummary{User: u.ID, Categories: []any{}})
+				}
+			}
+		}
+
+		return map[string]any{
+			"entries": []any{},
+			"summary": summary,
+		}, nil
+	case http.MethodDelete:
+		if r.URL.Query().Get("uid") == "" && !boolParam(r, "remove-all", false) {
+			return nil, errorf(http.StatusBadRequest, admin.ErrInvalidArgument)
+		}
+		return nil, nil
+	}
+
+	return nil, &apiError{status: http.StatusMethodNotAllowed, code: "MethodNotAllowed"}
+}
+
+func (s *Server) sortedUsers() []*user {
+	users := make([]*user, 0, len(s.users))
+	for _, u := range s.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users
+}
+
+func (s *Server) handleInfo(r *http.Request) (any, *apiError) {
+	if r.Method != http.MethodGet {
+		return nil, &apiError{status: http.StatusMethodNotAllowed, code: "MethodNotAllowed"}
+	}
+
+	return map[string]any{
+		"info": map[string]any{
+			"storage_backends": []map[string]string{{"name": "rados", "cluster_id": "rgwtest"}},
+		},
+	}, nil
+}
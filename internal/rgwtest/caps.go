@@ -0,0 +1,132 @@
+package rgwtest
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+)
+
+// capPerm is a set of permissions of a user capability.
+type capPerm int
+
+const (
+	capRead capPerm = 1 << iota
+	capWrite
+
+	capReadWrite = capRead | capWrite
+)
+
+// capTypes are the capability types known to radosgw.
+var capTypes = map[string]bool{
+	"amz-cache":     true,
+	"bilog":         true,
+	"buckets":       true,
+	"datalog":       true,
+	"info":          true,
+	"mdlog":         true,
+	"metadata":      true,
+	"oidc-provider": true,
+	"ratelimit":     true,
+	"roles":         true,
+	"usage":         true,
+	"user-policy":   true,
+	"users":         true,
+	"zone":          true,
+}
+
+type capInfo struct {
+	Type string `json:"type"`
+	Perm string `json:"perm"`
+}
+
+func (p capPerm) String() string {
+	switch p {
+	case capReadWrite:
+		return "*"
+	case capRead:
+		return "read"
+	case capWrite:
+		return "write"
+	}
+	return ""
+}
+
+func capsInfo(caps map[string]capPerm) []capInfo {
+	infos := []capInfo{}
+	for capType, perm := range caps {
+		if perm != 0 {
+			infos = append(infos, capInfo{Type: capType, Perm: perm.String()})
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Type < infos[j].Type })
+	return infos
+}
+
+// parseCaps parses caps of the form "<type>=<perm>[;<type>=<perm>...]",
+// where perm is "read", "write", "read, write" or "*".
+func parseCaps(userCaps string) (map[string]capPerm, *apiError) {
+	caps := make(map[string]capPerm)
+	for _, c := range strings.Split(userCaps, ";") {
+		capType, perms, ok := strings.Cut(c, "=")
+		capType = strings.TrimSpace(capType)
+		if !ok || !capTypes[capType] {
+			return nil, errorf(http.StatusBadRequest, admin.ErrInvalidCapability)
+		}
+
+		for _, perm := range strings.Split(perms, ",") {
+			switch strings.TrimSpace(perm) {
+			case "*":
+				caps[capType] |= capReadWrite
+			case "read":
+				caps[capType] |= capRead
+			case "write":
+				caps[capType] |= capWrite
+			default:
+				return nil, errorf(http.StatusBadRequest, admin.ErrInvalidCapability)
+			}
+		}
+	}
+	return caps, nil
+}
+
+func (s *Server) addCaps(r *http.Request) (any, *apiError) {
+	u, apiErr := s.lookupUser(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	caps, apiErr := parseCaps(r.URL.Query().Get("user-caps"))
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	for capType, perm := range caps {
+		u.Caps[capType] |= perm
+	}
+
+	return capsInfo(u.Caps), nil
+}
+
+func (s *Server) removeCaps(r *http.Request) (any, *apiError) {
+	u, apiErr := s.lookupUser(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	caps, apiErr := parseCaps(r.URL.Query().Get("user-caps"))
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	for capType, perm := range caps {
+		if u.Caps[capType] == 0 {
+			return nil, errorf(http.StatusNotFound, admin.ErrNoSuchCap)
+		}
+		u.Caps[capType] &^= perm
+		if u.Caps[capType] == 0 {
+			delete(u.Caps, capType)
+		}
+	}
+
+	return capsInfo(u.Caps), nil
+}
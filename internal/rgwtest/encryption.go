@@ -0,0 +1,37 @@
+package rgwtest
+
+import (
+	"encoding/xml"
+	"net/http"
+)
+
+// validateEncryption checks a default encryption configuration like
+// radosgw, which supports a single rule of SSE-S3 or SSE-KMS, the latter
+// with the ID of a key in its KMS.
+func validateEncryption(_ *Server, _ *bucket, _ http.Header, body []byte) string {
+	var config struct {
+		Rules []struct {
+			Default *struct {
+				SSEAlgorithm   string `xml:"SSEAlgorithm"`
+				KMSMasterKeyID string `xml:"KMSMasterKeyID"`
+			} `xml:"ApplyServerSideEncryptionByDefault"`
+		} `xml:"Rule"`
+	}
+	if err := xml.Unmarshal(body, &config); err != nil || len(config.Rules) != 1 || config.Rules[0].Default == nil {
+		return "MalformedXML"
+	}
+
+	switch def := config.Rules[0].Default; def.SSEAlgorithm {
+	case "AES256":
+		if def.KMSMasterKeyID != "" {
+			return "InvalidArgument"
+		}
+	case "aws:kms":
+		if def.KMSMasterKeyID == "" {
+			return "InvalidArgument"
+		}
+	default:
+		return "MalformedXML"
+	}
+	return ""
+}
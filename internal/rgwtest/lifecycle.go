@@ -0,0 +1,83 @@
+package rgwtest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"sort"
+)
+
+// lifecycleConfiguration is the part of a lifecycle configuration that the
+// fake checks.
+type lifecycleConfiguration struct {
+	Rules []struct {
+		ID          string `xml:"ID"`
+		Status      string `xml:"Status"`
+		Transitions []struct {
+			StorageClass string `xml:"StorageClass"`
+		} `xml:"Transition"`
+		NoncurrentVersionTransitions []struct {
+			StorageClass string `xml:"StorageClass"`
+		} `xml:"NoncurrentVersionTransition"`
+	} `xml:"Rule"`
+}
+
+// validateLifecycle checks a lifecycle configuration like radosgw, which
+// needs unique rule IDs and rejects transitions to storage classes that the
+// placement target of the bucket does not have.
+func validateLifecycle(s *Server, _ *bucket, _ http.Header, body []byte) string {
+	var config lifecycleConfiguration
+	if err := xml.Unmarshal(body, &config); err != nil || len(config.Rules) == 0 {
+		return "MalformedXML"
+	}
+
+	ids := make(map[string]bool)
+	for _, rule := range config.Rules {
+		if rule.ID == "" || len(rule.ID) > 255 || ids[rule.ID] {
+			return "InvalidArgument"
+		}
+		ids[rule.ID] = true
+
+		if rule.Status != "Enabled" && rule.Status != "Disabled" {
+			return "MalformedXML"
+		}
+
+		for _, transition := range rule.Transitions {
+			if !s.hasStorageClass(defaultPlacement, transition.StorageClass) {
+				return "InvalidArgument"
+			}
+		}
+		for _, transition := range rule.NoncurrentVersionTransitions {
+			if !s.hasStorageClass(defaultPlacement, transition.StorageClass) {
+				return "InvalidArgument"
+			}
+		}
+	}
+
+	return ""
+}
+
+// normalizeLifecycle sorts the rules of a lifecycle configuration by ID, as
+// radosgw returns them in that order.
+func normalizeLifecycle(_ *Server, _ *bucket, _ http.Header, body []byte) []byte {
+	var config struct {
+		Rules []struct {
+			ID    string `xml:"ID"`
+			Inner []byte `xml:",innerxml"`
+		} `xml:"Rule"`
+	}
+	if err := xml.Unmarshal(body, &config); err != nil {
+		return body
+	}
+	sort.SliceStable(config.Rules, func(i, j int) bool { return config.Rules[i].ID < config.Rules[j].ID })
+
+	var buf bytes.Buffer
+	buf.WriteString(`<LifecycleConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`)
+	for _, rule := range config.Rules {
+		buf.WriteString("<Rule>")
+		buf.Write(rule.Inner)
+		buf.WriteString("</Rule>")
+	}
+	buf.WriteString("</LifecycleConfiguration>")
+	return buf.Bytes()
+}
@@ -0,0 +1,133 @@
+package rgwtest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// notificationEvents are the event types of bucket notifications that
+// radosgw supports.
+var notificationEvents = []string{
+	"s3:ObjectCreated:*",
+	"s3:ObjectCreated:Put",
+	"s3:ObjectCreated:Post",
+	"s3:ObjectCreated:Copy",
+	"s3:ObjectCreated:CompleteMultipartUpload",
+	"s3:ObjectRemoved:*",
+	"s3:ObjectRemoved:Delete",
+	"s3:ObjectRemoved:DeleteMarkerCreated",
+	"s3:ObjectLifecycle:Expiration:*",
+	"s3:ObjectLifecycle:Expiration:Current",
+	"s3:ObjectLifecycle:Expiration:NonCurrent",
+	"s3:ObjectLifecycle:Expiration:DeleteMarker",
+	"s3:ObjectLifecycle:Expiration:AbortMultipartUpload",
+	"s3:ObjectLifecycle:Transition:*",
+	"s3:ObjectLifecycle:Transition:Current",
+	"s3:ObjectLifecycle:Transition:NonCurrent",
+	"s3:ObjectSynced:*",
+	"s3:ObjectSynced:Create",
+	"s3:ObjectSynced:Delete",
+	"s3:ObjectSynced:DeletionMarkerCreated",
+}
+
+type filterRules struct {
+	Rules []struct {
+		Name  string `xml:"Name"`
+		Value string `xml:"Value"`
+	} `xml:"FilterRule"`
+}
+
+// notificationConfiguration is the part of a notification configuration
+// that the fake checks.
+type notificationConfiguration struct {
+	Topics []struct {
+		ID     string   `xml:"Id"`
+		Topic  string   `xml:"Topic"`
+		Events []string `xml:"Event"`
+		Filter struct {
+			Key      filterRules `xml:"S3Key"`
+			Metadata filterRules `xml:"S3Metadata"`
+			Tags     filterRules `xml:"S3Tags"`
+		} `xml:"Filter"`
+	} `xml:"TopicConfiguration"`
+}
+
+// validateNotification checks a notification configuration like radosgw,
+// which needs unique IDs, existing topics and supported events and filters.
+func validateNotification(s *Server, _ *bucket, _ http.Header, body []byte) string {
+	var config notificationConfiguration
+	if err := xml.Unmarshal(body, &config); err != nil {
+		return "MalformedXML"
+	}
+
+	ids := make(map[string]bool)
+	for _, t := range config.Topics {
+		if t.ID == "" || ids[t.ID] {
+			return "InvalidArgument"
+		}
+		ids[t.ID] = true
+
+		if _, ok := s.topics[t.Topic]; !ok {
+			return "InvalidArgument"
+		}
+
+		if len(t.Events) == 0 {
+			return "InvalidArgument"
+		}
+		for _, event := range t.Events {
+			if !containsString(notificationEvents, event) {
+				return "InvalidArgument"
+			}
+		}
+
+		for _, rule := range t.Filter.Key.Rules {
+			if rule.Name != "prefix" && rule.Name != "suffix" && rule.Name != "regex" {
+				return "InvalidArgument"
+			}
+		}
+		for _, rule := range t.Filter.Metadata.Rules {
+			if !strings.HasPrefix(rule.Name, "x-amz-meta-") {
+				return "InvalidArgument"
+			}
+		}
+	}
+
+	return ""
+}
+
+// normalizeNotification sorts the topic configurations of a notification
+// configuration by ID, as radosgw returns them in that order.
+func normalizeNotification(_ *Server, _ *bucket, _ http.Header, body []byte) []byte {
+	var config struct {
+		Topics []struct {
+			ID    string `xml:"Id"`
+			Inner []byte `xml:",innerxml"`
+		} `xml:"TopicConfiguration"`
+	}
+	if err := xml.Unmarshal(body, &config); err != nil {
+		return body
+	}
+	sort.SliceStable(config.Topics, func(i, j int) bool { return config.Topics[i].ID < config.Topics[j].ID })
+
+	var buf bytes.Buffer
+	buf.WriteString(`<NotificationConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`)
+	for _, t := range config.Topics {
+		buf.WriteString("<TopicConfiguration>")
+		buf.Write(t.Inner)
+		buf.WriteString("</TopicConfiguration>")
+	}
+	buf.WriteString("</NotificationConfiguration>")
+	return buf.Bytes()
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package rgwtest
+
+import (
+	"net/http"
+	"sort"
+)
+
+// defaultPlacement is the placement target of all buckets of the fake.
+const defaultPlacement = "default-placement"
+
+// AddStorageClass adds a storage class to a placement target of the default
+// zonegroup, such as "default-placement".
+func (s *Server) AddStorageClass(placement, storageClass string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.storageClasses[placement] {
+		if existing == storageClass {
+			return
+		}
+	}
+	s.storageClasses[placement] = append(s.storageClasses[placement], storageClass)
+	sort.Strings(s.storageClasses[placement])
+}
+
+// hasStorageClass reports whether the placement target has the storage
+// class.
+func (s *Server) hasStorageClass(placement, storageClass string) bool {
+	for _, existing := range s.storageClasses[placement] {
+		if existing == storageClass {
+			return true
+		}
+	}
+	return false
+}
+
+// handlePeriod serves the current period, with a single zonegroup "default"
+// holding the placement targets of the fake.
+func (s *Server) handlePeriod(r *http.Request) (any, *apiError) {
+	if r.Method != http.MethodGet {
+		return nil, &apiError{status: http.StatusMethodNotAllowed, code: "MethodNotAllowed"}
+	}
+
+	names := make([]string, 0, len(s.storageClasses))
+	for name := range s.storageClasses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	targets := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		targets = append(targets, map[string]any{
+			"name":            name,
+			"tags":            []string{},
+			"storage_classes": s.storageClasses[name],
+		})
+	}
+
+	return map[string]any{
+		"id":               "rgwtest-period",
+		"epoch":            1,
+		"realm_id":         "rgwtest-realm",
+		"master_zonegroup": "rgwtest-zonegroup",
+		"period_map": map[string]any{
+			"id": "rgwtest-period",
+			"zonegroups": []map[string]any{{
+				"id":                "rgwtest-zonegroup",
+				"name":              "default",
+				"is_master":         true,
+				"default_placement": defaultPlacement,
+				"placement_targets": targets,
+			}},
+		},
+	}, nil
+}
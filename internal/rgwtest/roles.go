@@ -0,0 +1,349 @@
+package rgwtest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// iamNamespace is the XML namespace of IAM API responses.
+const iamNamespace = "https://iam.amazonaws.com/doc/2010-05-08/"
+
+var (
+	roleNamePattern       = regexp.MustCompile(`^[\w+=,.@-]{1,64}$`)
+	rolePathPattern       = regexp.MustCompile(`^/([\x21-\x7E]*/)?$`)
+	rolePolicyNamePattern = regexp.MustCompile(`^[\w+=,.@-]{1,128}$`)
+)
+
+// role is an IAM role of a tenant.
+type role struct {
+	ID                 string
+	Name               string
+	Path               string
+	Tenant             string
+	TrustPolicy        string
+	MaxSessionDuration int
+	Created            time.Time
+
+	// Policies are the permission policies of the role by name.
+	Policies map[string]string
+}
+
+// arn returns the ARN of the role.
+func (ro *role) arn() string {
+	return "arn:aws:iam::" + ro.Tenant + ":role" + ro.Path + ro.Name
+}
+
+// roleKey returns the key of the role with name in tenant.
+func roleKey(tenant, name string) string {
+	if tenant == "" {
+		return name
+	}
+	return tenant + "$" + name
+}
+
+// serveIAM serves a request to the IAM API of radosgw, of which the fake
+// supports roles with their permission policies and inline user policies.
+// Like radosgw, roles belong to the tenant of the caller, who needs the
+// "roles" cap, or the "user-policy" cap for user policies.
+func (s *Server) serveIAM(w http.ResponseWriter, r *http.Request, caller *user, body []byte) {
+	params, err := url.ParseQuery(string(body))
+	if err != nil || r.Method != http.MethodPost {
+		s.writeError(w, r, http.StatusBadRequest, "InvalidArgument")
+		return
+	}
+
+	action := params.Get("Action")
+	need := capWrite
+	if strings.HasPrefix(action, "Get") || strings.HasPrefix(action, "List") {
+		need = capRead
+	}
+	capType := "roles"
+	if strings.HasSuffix(action, "UserPolicy") || action == "ListUserPolicies" {
+		capType = "user-policy"
+	}
+	if caller.Caps[capType]&need == 0 {
+		s.writeError(w, r, http.StatusForbidden, "AccessDenied")
+		return
+	}
+
+	tenant := tenantOf(caller.ID)
+	var response any
+	var code string
+	switch action {
+	case "CreateRole":
+		response, code = s.createRole(tenant, params)
+	case "GetRole":
+		response, code = s.getRole(tenant, params.Get("RoleName"))
+	case "UpdateAssumeRolePolicy":
+		code = s.updateRole(tenant, params.Get("RoleName"), func(ro *role) string {
+			if !validPolicyDocument(params.Get("PolicyDocument")) {
+				return "MalformedPolicyDocument"
+			}
+			ro.TrustPolicy = params.Get("PolicyDocument")
+			return ""
+		})
+	case "UpdateRole":
+		code = s.updateRole(tenant, params.Get("RoleName"), func(ro *role) string {
+			if params.Has("MaxSessionDuration") {
+				duration, ok := maxSessionDuration(params)
+				if !ok {
+					return "ValidationError"
+				}
+				ro.MaxSessionDuration = duration
+			}
+			return ""
+		})
+	case "DeleteRole":
+		code = s.deleteRole(tenant, params.Get("RoleName"))
+	case "PutRolePolicy":
+		code = s.updateRole(tenant, params.Get("RoleName"), func(ro *role) string {
+			if !rolePolicyNamePattern.MatchString(params.Get("PolicyName")) {
+				return "ValidationError"
+			}
+			if !validPolicyDocument(params.Get("PolicyDocument")) {
+				return "MalformedPolicyDocument"
+			}
+			ro.Policies[params.Get("PolicyName")] = params.Get("PolicyDocument")
+			return ""
+		})
+	case "GetRolePolicy":
+		response, code = s.getRolePolicy(tenant, params.Get("RoleName"), params.Get("PolicyName"))
+	case "DeleteRolePolicy":
+		code = s.updateRole(tenant, params.Get("RoleName"), func(ro *role) string {
+			if _, ok := ro.Policies[params.Get("PolicyName")]; !ok {
+				return "NoSuchEntity"
+			}
+			delete(ro.Policies, params.Get("PolicyName"))
+			return ""
+		})
+	case "ListRolePolicies":
+		response, code = s.listRolePolicies(tenant, params.Get("RoleName"))
+	case "PutUserPolicy":
+		code = s.updateUserPolicies(params.Get("UserName"), func(policies map[string]string) string {
+			if !rolePolicyNamePattern.MatchString(params.Get("PolicyName")) {
+				return "ValidationError"
+			}
+			if !validPolicyDocument(params.Get("PolicyDocument")) {
+				return "MalformedPolicyDocument"
+			}
+			policies[params.Get("PolicyName")] = params.Get("PolicyDocument")
+			return ""
+		})
+	case "GetUserPolicy":
+		response, code = s.getUserPolicy(params.Get("UserName"), params.Get("PolicyName"))
+	case "DeleteUserPolicy":
+		code = s.updateUserPolicies(params.Get("UserName"), func(policies map[string]string) string {
+			if _, ok := policies[params.Get("PolicyName")]; !ok {
+				return "NoSuchEntity"
+			}
+			delete(policies, params.Get("PolicyName"))
+			return ""
+		})
+	case "ListUserPolicies":
+		response, code = s.listUserPolicies(params.Get("UserName"))
+	default:
+		s.writeError(w, r, http.StatusBadRequest, "InvalidAction")
+		return
+	}
+
+	switch code {
+	case "":
+	case "NoSuchEntity":
+		s.writeError(w, r, http.StatusNotFound, code)
+		return
+	case "EntityAlreadyExists", "DeleteConflict":
+		s.writeError(w, r, http.StatusConflict, code)
+		return
+	default:
+		s.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+
+	s.writeQueryResponse(w, iamNamespace, action, response)
+}
+
+// validPolicyDocument reports whether the document is a JSON policy with
+// statements, which radosgw requires of trust and permission policies.
+func validPolicyDocument(document string) bool {
+	var policy struct {
+		Statement json.RawMessage
+	}
+	return json.Unmarshal([]byte(document), &policy) == nil && len(policy.Statement) > 0
+}
+
+// maxSessionDuration returns the MaxSessionDuration parameter, which
+// radosgw limits to between one and twelve hours.
+func maxSessionDuration(params url.Values) (int, bool) {
+	if !params.Has("MaxSessionDuration") {
+		return 3600, true
+	}
+	duration, err := strconv.Atoi(params.Get("MaxSessionDuration"))
+	return duration, err == nil && duration >= 3600 && duration <= 43200
+}
+
+type xmlRole struct {
+	RoleID                   string `xml:"RoleId"`
+	RoleName                 string
+	Path                     string
+	Arn                      string
+	CreateDate               string
+	MaxSessionDuration       int
+	AssumeRolePolicyDocument string
+}
+
+// xml returns the role as in responses of radosgw, which returns the trust
+// policy as JSON, not URL-encoded like AWS.
+func (ro *role) xml() xmlRole {
+	return xmlRole{
+		RoleID:                   ro.ID,
+		RoleName:                 ro.Name,
+		Path:                     ro.Path,
+		Arn:                      ro.arn(),
+		CreateDate:               ro.Created.UTC().Format(time.RFC3339),
+		MaxSessionDuration:       ro.MaxSessionDuration,
+		AssumeRolePolicyDocument: ro.TrustPolicy,
+	}
+}
+
+// createRole creates a role in the tenant.
+func (s *Server) createRole(tenant string, params url.Values) (any, string) {
+	name := params.Get("RoleName")
+	path := params.Get("Path")
+	if path == "" {
+		path = "/"
+	}
+	duration, ok := maxSessionDuration(params)
+	if !roleNamePattern.MatchString(name) || !rolePathPattern.MatchString(path) || len(path) > 512 || !ok {
+		return nil, "ValidationError"
+	}
+	if !validPolicyDocument(params.Get("AssumeRolePolicyDocument")) {
+		return nil, "MalformedPolicyDocument"
+	}
+	if _, ok := s.roles[roleKey(tenant, name)]; ok {
+		return nil, "EntityAlreadyExists"
+	}
+
+	id := randomString("0123456789abcdef", 32)
+	ro := &role{
+		ID:                 id[:8] + "-" + id[8:12] + "-" + id[12:16] + "-" + id[16:20] + "-" + id[20:],
+		Name:               name,
+		Path:               path,
+		Tenant:             tenant,
+		TrustPolicy:        params.Get("AssumeRolePolicyDocument"),
+		MaxSessionDuration: duration,
+		Created:            time.Now(),
+		Policies:           make(map[string]string),
+	}
+	s.roles[roleKey(tenant, name)] = ro
+
+	return struct {
+		XMLName xml.Name `xml:"CreateRoleResult"`
+		Role    xmlRole
+	}{Role: ro.xml()}, ""
+}
+
+// getRole returns a role of the tenant.
+func (s *Server) getRole(tenant, name string) (any, string) {
+	ro, ok := s.roles[roleKey(tenant, name)]
+	if !ok {
+		return nil, "NoSuchEntity"
+	}
+	return struct {
+		XMLName xml.Name `xml:"GetRoleResult"`
+		Role    xmlRole
+	}{Role: ro.xml()}, ""
+}
+
+// updateRole calls update with a role of the tenant.
+func (s *Server) updateRole(tenant, name string, update func(*role) string) string {
+	ro, ok := s.roles[roleKey(tenant, name)]
+	if !ok {
+		return "NoSuchEntity"
+	}
+	return update(ro)
+}
+
+// deleteRole removes a role of the tenant, which radosgw refuses while it
+// has permission policies.
+func (s *Server) deleteRole(tenant, name string) string {
+	ro, ok := s.roles[roleKey(tenant, name)]
+	if !ok {
+		return "NoSuchEntity"
+	}
+	if len(ro.Policies) > 0 {
+		return "DeleteConflict"
+	}
+	delete(s.roles, roleKey(tenant, name))
+	return ""
+}
+
+// getRolePolicy returns a permission policy of a role of the tenant.
+func (s *Server) getRolePolicy(tenant, roleName, policyName string) (any, string) {
+	ro, ok := s.roles[roleKey(tenant, roleName)]
+	if !ok {
+		return nil, "NoSuchEntity"
+	}
+	policy, ok := ro.Policies[policyName]
+	if !ok {
+		return nil, "NoSuchEntity"
+	}
+	return struct {
+		XMLName        xml.Name `xml:"GetRolePolicyResult"`
+		RoleName       string
+		PolicyName     string
+		PolicyDocument string
+	}{RoleName: roleName, PolicyName: policyName, PolicyDocument: policy}, ""
+}
+
+// listRolePolicies returns the names of the permission policies of a role
+// of the tenant.
+func (s *Server) listRolePolicies(tenant, name string) (any, string) {
+	ro, ok := s.roles[roleKey(tenant, name)]
+	if !ok {
+		return nil, "NoSuchEntity"
+	}
+	names := make([]string, 0, len(ro.Policies))
+	for policyName := range ro.Policies {
+		names = append(names, policyName)
+	}
+	sort.Strings(names)
+	return struct {
+		XMLName     xml.Name `xml:"ListRolePoliciesResult"`
+		PolicyNames []string `xml:"PolicyNames>member"`
+		IsTruncated bool
+	}{PolicyNames: names}, ""
+}
+
+// Role returns the trust policy and the permission policies of the role
+// with name in tenant, and whether it exists.
+func (s *Server) Role(tenant, name string) (string, map[string]string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ro, ok := s.roles[roleKey(tenant, name)]
+	if !ok {
+		return "", nil, false
+	}
+	policies := make(map[string]string, len(ro.Policies))
+	for policyName, policy := range ro.Policies {
+		policies[policyName] = policy
+	}
+	return ro.TrustPolicy, policies, true
+}
+
+// DeleteRole removes the role with name in tenant together with its
+// permission policies, for example to simulate changes outside of
+// Terraform.
+func (s *Server) DeleteRole(tenant, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.roles, roleKey(tenant, name))
+}
@@ -0,0 +1,68 @@
+package rgwtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/ceph/go-ceph/rgw/admin"
+)
+
+func TestServerRoles(t *testing.T) {
+	server := NewServer()
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	client := iam.New(testSession(server, server.AccessKey, server.SecretKey))
+
+	const trust = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Federated":["arn:aws:iam:::oidc-provider/sso.example.com"]},"Action":["sts:AssumeRoleWithWebIdentity"]}]}`
+	_, err := client.CreateRoleWithContext(ctx, &iam.CreateRoleInput{RoleName: aws.String("web"), AssumeRolePolicyDocument: aws.String("{}")})
+	expectAWSError(t, err, "MalformedPolicyDocument")
+	_, err = client.CreateRoleWithContext(ctx, &iam.CreateRoleInput{RoleName: aws.String("web"), AssumeRolePolicyDocument: aws.String(trust), Path: aws.String("apps")})
+	expectAWSError(t, err, "ValidationError")
+
+	created, err := client.CreateRoleWithContext(ctx, &iam.CreateRoleInput{RoleName: aws.String("web"), Path: aws.String("/apps/"), AssumeRolePolicyDocument: aws.String(trust)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if arn := aws.StringValue(created.Role.Arn); arn != "arn:aws:iam:::role/apps/web" {
+		t.Fatalf("unexpected ARN %s", arn)
+	}
+	_, err = client.CreateRoleWithContext(ctx, &iam.CreateRoleInput{RoleName: aws.String("web"), AssumeRolePolicyDocument: aws.String(trust)})
+	expectAWSError(t, err, "EntityAlreadyExists")
+
+	if _, err := client.PutRolePolicyWithContext(ctx, &iam.PutRolePolicyInput{
+		RoleName:       aws.String("web"),
+		PolicyName:     aws.String("read"),
+		PolicyDocument: aws.String(`{"Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}]}`),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	policies, err := client.ListRolePoliciesWithContext(ctx, &iam.ListRolePoliciesInput{RoleName: aws.String("web")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(policies.PolicyNames) != 1 || aws.StringValue(policies.PolicyNames[0]) != "read" {
+		t.Fatalf("unexpected policies %v", policies.PolicyNames)
+	}
+
+	_, err = client.DeleteRoleWithContext(ctx, &iam.DeleteRoleInput{RoleName: aws.String("web")})
+	expectAWSError(t, err, "DeleteConflict")
+	if _, err := client.DeleteRolePolicyWithContext(ctx, &iam.DeleteRolePolicyInput{RoleName: aws.String("web"), PolicyName: aws.String("read")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.DeleteRoleWithContext(ctx, &iam.DeleteRoleInput{RoleName: aws.String("web")}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.GetRoleWithContext(ctx, &iam.GetRoleInput{RoleName: aws.String("web")})
+	expectAWSError(t, err, "NoSuchEntity")
+
+	user, err := server.API().CreateUser(ctx, admin.User{ID: "demo", DisplayName: "Demo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = iam.New(testSession(server, user.Keys[0].AccessKey, user.Keys[0].SecretKey)).
+		GetRoleWithContext(ctx, &iam.GetRoleInput{RoleName: aws.String("web")})
+	expectAWSError(t, err, "AccessDenied")
+}
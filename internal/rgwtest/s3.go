@@ -0,0 +1,307 @@
+package rgwtest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"strings"
+)
+
+// s3Subresource is a bucket subresource of the S3 API, such as "?policy",
+// which the fake stores as sent.
+type s3Subresource struct {
+	// contentType is the content type of the document.
+	contentType string
+
+	// missing is the error code and status returned when getting the
+	// subresource of a bucket without one.  If empty is set instead, it is
+	// returned as the document, or the result of initial if that is set.
+	missing       string
+	missingStatus int
+	empty         []byte
+	initial       func(s *Server, b *bucket) []byte
+
+	// permanent subresources cannot be deleted, only changed.
+	permanent bool
+
+	// validate checks a new document of the bucket and returns an error
+	// code if radosgw rejects it.  It is called with the server locked.
+	validate func(s *Server, b *bucket, header http.Header, body []byte) string
+
+	// normalize, if set, returns the document as radosgw stores it.
+	normalize func(s *Server, b *bucket, header http.Header, body []byte) []byte
+}
+
+// s3Subresources are the bucket subresources supported by the fake.
+var s3Subresources = map[string]s3Subresource{
+	"policy": {
+		contentType:   "application/json",
+		missing:       "NoSuchBucketPolicy",
+		missingStatus: http.StatusNotFound,
+		validate:      validateBucketPolicy,
+	},
+	"lifecycle": {
+		contentType:   "application/xml",
+		missing:       "NoSuchLifecycleConfiguration",
+		missingStatus: http.StatusNotFound,
+		validate:      validateLifecycle,
+		normalize:     normalizeLifecycle,
+	},
+	"versioning": {
+		contentType: "application/xml",
+		empty:       []byte(`<VersioningConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></VersioningConfiguration>`),
+		permanent:   true,
+		validate:    validateVersioning,
+		normalize:   normalizeVersioning,
+	},
+	"cors": {
+		contentType:   "application/xml",
+		missing:       "NoSuchCORSConfiguration",
+		missingStatus: http.StatusNotFound,
+		validate:      validateCORS,
+	},
+	"website": {
+		contentType:   "application/xml",
+		missing:       "NoSuchWebsiteConfiguration",
+		missingStatus: http.StatusNotFound,
+		validate:      validateWebsite,
+	},
+	"tagging": {
+		contentType:   "application/xml",
+		missing:       "NoSuchTagSet",
+		missingStatus: http.StatusNotFound,
+		validate:      validateTagging,
+	},
+	"encryption": {
+		contentType:   "application/xml",
+		missing:       "ServerSideEncryptionConfigurationNotFoundError",
+		missingStatus: http.StatusNotFound,
+		validate:      validateEncryption,
+	},
+	"notification": {
+		contentType: "application/xml",
+		empty:       []byte(`<NotificationConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></NotificationConfiguration>`),
+		validate:    validateNotification,
+		normalize:   normalizeNotification,
+	},
+	"acl": {
+		contentType: "application/xml",
+		initial:     initialACL,
+		permanent:   true,
+		validate:    validateACL,
+		normalize:   normalizeACL,
+	},
+	"object-lock": {
+		contentType:   "application/xml",
+		missing:       "ObjectLockConfigurationNotFoundError",
+		missingStatus: http.StatusNotFound,
+		permanent:     true,
+		validate:      validateObjectLock,
+	},
+}
+
+// serveAWS serves a request to the AWS APIs of radosgw, of which the fake
+// only supports the bucket subresources of the S3 API, topics of the SNS
+// API and roles of the IAM API.
+func (s *Server) serveAWS(w http.ResponseWriter, r *http.Request, caller *user, body []byte) {
+	auth, _ := parseAuthorization(r.Header.Get("Authorization"))
+	switch auth.service {
+	case "sns":
+		s.serveSNS(w, r, caller, body)
+		return
+	case "iam":
+		s.serveIAM(w, r, caller, body)
+		return
+	}
+	if auth.service != "s3" {
+		s.writeError(w, r, http.StatusNotImplemented, "NotImplemented")
+		return
+	}
+
+	name, object, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	subresource, ok := s3SubresourceOf(r)
+	if name == "" || object != "" || !ok {
+		s.writeError(w, r, http.StatusNotImplemented, "NotImplemented")
+		return
+	}
+
+	b, found := s.buckets[name]
+	if !found {
+		s.writeError(w, r, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	if !caller.System && b.Owner != caller.ID {
+		s.writeError(w, r, http.StatusForbidden, "AccessDenied")
+		return
+	}
+
+	sub := s3Subresources[subresource]
+	switch r.Method {
+	case http.MethodGet:
+		document, found := b.Subresources[subresource]
+		if !found && sub.empty != nil {
+			document, found = sub.empty, true
+		}
+		if !found && sub.initial != nil {
+			document, found = sub.initial(s, b), true
+		}
+		if !found {
+			s.writeError(w, r, sub.missingStatus, sub.missing)
+			return
+		}
+		w.Header().Set("Content-Type", sub.contentType)
+		_, _ = w.Write(document)
+	case http.MethodPut:
+		if code := sub.validate(s, b, r.Header, body); code != "" {
+			s.writeError(w, r, http.StatusBadRequest, code)
+			return
+		}
+		if sub.normalize != nil {
+			body = sub.normalize(s, b, r.Header, body)
+		}
+		if b.Subresources == nil {
+			b.Subresources = make(map[string][]byte)
+		}
+		b.Subresources[subresource] = body
+	case http.MethodDelete:
+		if sub.permanent {
+			s.writeError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed")
+			return
+		}
+		delete(b.Subresources, subresource)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		s.writeError(w, r, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// s3SubresourceOf returns the bucket subresource requested by r.
+func s3SubresourceOf(r *http.Request) (string, bool) {
+	var names []string
+	for name := range r.URL.Query() {
+		if _, ok := s3Subresources[name]; ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) != 1 {
+		return "", false
+	}
+	return names[0], true
+}
+
+// BucketSubresource returns the document of a subresource of the bucket,
+// such as "policy", and whether it has one.
+func (s *Server) BucketSubresource(name, subresource string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[name]
+	if !ok {
+		return "", false
+	}
+	document, ok := b.Subresources[subresource]
+	return string(document), ok
+}
+
+// SetBucketSubresource sets the document of a subresource of the bucket,
+// for example to simulate changes outside of Terraform.
+func (s *Server) SetBucketSubresource(name, subresource, document string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[name]
+	if !ok {
+		return
+	}
+	if b.Subresources == nil {
+		b.Subresources = make(map[string][]byte)
+	}
+	b.Subresources[subresource] = []byte(document)
+}
+
+// RemoveBucketSubresource removes a subresource of the bucket, for example
+// to simulate changes outside of Terraform.
+func (s *Server) RemoveBucketSubresource(name, subresource string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if b, ok := s.buckets[name]; ok {
+		delete(b.Subresources, subresource)
+	}
+}
+
+// validateBucketPolicy checks that a bucket policy is a JSON object with
+// statements whose principals are ARNs, as radosgw requires.
+func validateBucketPolicy(_ *Server, _ *bucket, _ http.Header, body []byte) string {
+	var policy struct {
+		Statement json.RawMessage
+	}
+	if err := json.Unmarshal(body, &policy); err != nil || len(policy.Statement) == 0 {
+		return "MalformedPolicy"
+	}
+
+	var statements []struct {
+		Principal map[string]json.RawMessage
+	}
+	if err := json.Unmarshal(policy.Statement, &statements); err != nil {
+		var statement struct {
+			Principal map[string]json.RawMessage
+		}
+		if err := json.Unmarshal(policy.Statement, &statement); err != nil {
+			return "MalformedPolicy"
+		}
+		statements = append(statements, statement)
+	}
+
+	for _, statement := range statements {
+		var principals []string
+		if err := json.Unmarshal(statement.Principal["AWS"], &principals); err != nil {
+			var principal string
+			if err := json.Unmarshal(statement.Principal["AWS"], &principal); err != nil {
+				continue
+			}
+			principals = []string{principal}
+		}
+		for _, principal := range principals {
+			if principal != "*" && !strings.HasPrefix(principal, "arn:aws:iam::") {
+				return "MalformedPolicy"
+			}
+		}
+	}
+
+	return ""
+}
+
+// writeXMLError writes an error in the format used by the S3 API, or with
+// the ErrorResponse element of the query APIs such as SNS.
+func (s *Server) writeXMLError(w http.ResponseWriter, r *http.Request, status int, code string) {
+	requestID := s.nextRequestID()
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("X-Amz-Request-Id", requestID)
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(xml.Header))
+
+	type xmlError struct {
+		Code      string `xml:"Code"`
+		RequestID string `xml:"RequestId,omitempty"`
+		HostID    string `xml:"HostId,omitempty"`
+	}
+	if auth, _ := parseAuthorization(r.Header.Get("Authorization")); auth.service != "" && auth.service != "s3" {
+		_ = xml.NewEncoder(w).Encode(struct {
+			XMLName   xml.Name `xml:"ErrorResponse"`
+			Error     xmlError `xml:"Error"`
+			RequestID string   `xml:"RequestId"`
+		}{
+			Error:     xmlError{Code: code},
+			RequestID: requestID,
+		})
+		return
+	}
+
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		xmlError
+	}{
+		xmlError: xmlError{Code: code, RequestID: requestID, HostID: "rgwtest"},
+	})
+}
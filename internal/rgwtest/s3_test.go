@@ -0,0 +1,414 @@
+package rgwtest
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/defaults"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/ceph/go-ceph/rgw/admin"
+)
+
+// testSession returns a session for the AWS APIs of the server with the
+// given credentials.
+func testSession(server *Server, accessKey, secretKey string) *session.Session {
+	return &session.Session{
+		Config: defaults.Config().
+			WithEndpoint(server.URL).
+			WithRegion("us-east-1").
+			WithCredentials(credentials.NewStaticCredentials(accessKey, secretKey, "")).
+			WithS3ForcePathStyle(true).
+			WithHTTPClient(server.Client()),
+		Handlers: defaults.Handlers(),
+	}
+}
+
+// expectAWSError fails the test unless err is an AWS error with code.
+func expectAWSError(t *testing.T, err error, code string) {
+	t.Helper()
+
+	awsErr, ok := err.(awserr.Error)
+	if !ok || awsErr.Code() != code {
+		t.Fatalf("expected %s, got %v", code, err)
+	}
+}
+
+func TestServerBucketPolicy(t *testing.T) {
+	server := NewServer()
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	client := s3.New(testSession(server, server.AccessKey, server.SecretKey))
+
+	user, err := server.API().CreateUser(ctx, admin.User{ID: "demo", DisplayName: "Demo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.CreateBucket("assets", "demo"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.GetBucketPolicyWithContext(ctx, &s3.GetBucketPolicyInput{Bucket: aws.String("assets")})
+	expectAWSError(t, err, "NoSuchBucketPolicy")
+
+	_, err = client.PutBucketPolicyWithContext(ctx, &s3.PutBucketPolicyInput{Bucket: aws.String("missing"), Policy: aws.String(`{"Statement":[]}`)})
+	expectAWSError(t, err, "NoSuchBucket")
+
+	_, err = client.PutBucketPolicyWithContext(ctx, &s3.PutBucketPolicyInput{Bucket: aws.String("assets"), Policy: aws.String(`{"Statement":[{"Principal":{"AWS":"demo"}}]}`)})
+	expectAWSError(t, err, "MalformedPolicy")
+
+	const policy = `{"Statement":[{"Effect":"Allow","Principal":{"AWS":["arn:aws:iam:::user/demo"]},"Action":"s3:GetObject","Resource":"arn:aws:s3:::assets/*"}]}`
+	owner := s3.New(testSession(server, user.Keys[0].AccessKey, user.Keys[0].SecretKey))
+	if _, err := owner.PutBucketPolicyWithContext(ctx, &s3.PutBucketPolicyInput{Bucket: aws.String("assets"), Policy: aws.String(policy)}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := client.GetBucketPolicyWithContext(ctx, &s3.GetBucketPolicyInput{Bucket: aws.String("assets")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aws.StringValue(out.Policy) != policy {
+		t.Fatalf("unexpected policy %s", aws.StringValue(out.Policy))
+	}
+
+	other, err := server.API().CreateUser(ctx, admin.User{ID: "other", DisplayName: "Other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s3.New(testSession(server, other.Keys[0].AccessKey, other.Keys[0].SecretKey)).
+		DeleteBucketPolicyWithContext(ctx, &s3.DeleteBucketPolicyInput{Bucket: aws.String("assets")})
+	expectAWSError(t, err, "AccessDenied")
+
+	if _, err := client.DeleteBucketPolicyWithContext(ctx, &s3.DeleteBucketPolicyInput{Bucket: aws.String("assets")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := server.BucketSubresource("assets", "policy"); ok {
+		t.Fatal("policy still set after deleting it")
+	}
+}
+
+func TestServerBucketLifecycle(t *testing.T) {
+	server := NewServer()
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	client := s3.New(testSession(server, server.AccessKey, server.SecretKey))
+
+	if _, err := server.API().CreateUser(ctx, admin.User{ID: "demo", DisplayName: "Demo"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.CreateBucket("assets", "demo"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := client.GetBucketLifecycleConfigurationWithContext(ctx, &s3.GetBucketLifecycleConfigurationInput{Bucket: aws.String("assets")})
+	expectAWSError(t, err, "NoSuchLifecycleConfiguration")
+
+	rule := func(id, storageClass string) *s3.LifecycleRule {
+		return &s3.LifecycleRule{
+			ID:          aws.String(id),
+			Status:      aws.String("Enabled"),
+			Filter:      &s3.LifecycleRuleFilter{Prefix: aws.String("")},
+			Transitions: []*s3.Transition{{Days: aws.Int64(1), StorageClass: aws.String(storageClass)}},
+		}
+	}
+	put := func(rules ...*s3.LifecycleRule) error {
+		_, err := client.PutBucketLifecycleConfigurationWithContext(ctx, &s3.PutBucketLifecycleConfigurationInput{
+			Bucket:                 aws.String("assets"),
+			LifecycleConfiguration: &s3.BucketLifecycleConfiguration{Rules: rules},
+		})
+		return err
+	}
+
+	expectAWSError(t, put(rule("b", "COLD")), "InvalidArgument")
+	expectAWSError(t, put(rule("b", "STANDARD"), rule("b", "STANDARD")), "InvalidArgument")
+
+	server.AddStorageClass(defaultPlacement, "COLD")
+	if err := put(rule("b", "COLD"), rule("a", "STANDARD")); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := client.GetBucketLifecycleConfigurationWithContext(ctx, &s3.GetBucketLifecycleConfigurationInput{Bucket: aws.String("assets")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Rules) != 2 || aws.StringValue(out.Rules[0].ID) != "a" || aws.StringValue(out.Rules[1].Transitions[0].StorageClass) != "COLD" {
+		t.Fatalf("unexpected rules %v", out.Rules)
+	}
+
+	if _, err := client.DeleteBucketLifecycleWithContext(ctx, &s3.DeleteBucketLifecycleInput{Bucket: aws.String("assets")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := server.BucketSubresource("assets", "lifecycle"); ok {
+		t.Fatal("lifecycle configuration still set after deleting it")
+	}
+}
+
+func TestServerBucketVersioningAndObjectLock(t *testing.T) {
+	server := NewServer()
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	client := s3.New(testSession(server, server.AccessKey, server.SecretKey))
+
+	if _, err := server.API().CreateUser(ctx, admin.User{ID: "demo", DisplayName: "Demo"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.CreateBucket("assets", "demo"); err != nil {
+		t.Fatal(err)
+	}
+
+	versioning, err := client.GetBucketVersioningWithContext(ctx, &s3.GetBucketVersioningInput{Bucket: aws.String("assets")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if versioning.Status != nil {
+		t.Fatalf("unexpected status %s of unversioned bucket", aws.StringValue(versioning.Status))
+	}
+
+	_, err = client.GetObjectLockConfigurationWithContext(ctx, &s3.GetObjectLockConfigurationInput{Bucket: aws.String("assets")})
+	expectAWSError(t, err, "ObjectLockConfigurationNotFoundError")
+
+	lock := func() error {
+		_, err := client.PutObjectLockConfigurationWithContext(ctx, &s3.PutObjectLockConfigurationInput{
+			Bucket: aws.String("assets"),
+			ObjectLockConfiguration: &s3.ObjectLockConfiguration{
+				ObjectLockEnabled: aws.String("Enabled"),
+				Rule: &s3.ObjectLockRule{DefaultRetention: &s3.DefaultRetention{
+					Mode: aws.String("GOVERNANCE"),
+					Days: aws.Int64(1),
+				}},
+			},
+		})
+		return err
+	}
+	version := func(status, mfaDelete, mfa string) error {
+		input := &s3.PutBucketVersioningInput{
+			Bucket:                  aws.String("assets"),
+			VersioningConfiguration: &s3.VersioningConfiguration{Status: aws.String(status)},
+		}
+		if mfaDelete != "" {
+			input.VersioningConfiguration.MFADelete = aws.String(mfaDelete)
+		}
+		if mfa != "" {
+			input.MFA = aws.String(mfa)
+		}
+		_, err := client.PutBucketVersioningWithContext(ctx, input)
+		return err
+	}
+
+	expectAWSError(t, lock(), "InvalidBucketState")
+	expectAWSError(t, version("Enabled", "Enabled", ""), "AccessDenied")
+	if err := version("Enabled", "Enabled", "serial 123456"); err != nil {
+		t.Fatal(err)
+	}
+	if err := lock(); err != nil {
+		t.Fatal(err)
+	}
+	expectAWSError(t, version("Suspended", "", ""), "InvalidBucketState")
+
+	versioning, err = client.GetBucketVersioningWithContext(ctx, &s3.GetBucketVersioningInput{Bucket: aws.String("assets")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aws.StringValue(versioning.Status) != "Enabled" || aws.StringValue(versioning.MFADelete) != "Enabled" {
+		t.Fatalf("unexpected versioning %v", versioning)
+	}
+}
+
+func TestServerBucketCORSWebsiteAndTagging(t *testing.T) {
+	server := NewServer()
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	client := s3.New(testSession(server, server.AccessKey, server.SecretKey))
+
+	if _, err := server.API().CreateUser(ctx, admin.User{ID: "demo", DisplayName: "Demo"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.CreateBucket("assets", "demo"); err != nil {
+		t.Fatal(err)
+	}
+	bucket := aws.String("assets")
+
+	_, err := client.GetBucketCorsWithContext(ctx, &s3.GetBucketCorsInput{Bucket: bucket})
+	expectAWSError(t, err, "NoSuchCORSConfiguration")
+	_, err = client.GetBucketWebsiteWithContext(ctx, &s3.GetBucketWebsiteInput{Bucket: bucket})
+	expectAWSError(t, err, "NoSuchWebsiteConfiguration")
+	_, err = client.GetBucketTaggingWithContext(ctx, &s3.GetBucketTaggingInput{Bucket: bucket})
+	expectAWSError(t, err, "NoSuchTagSet")
+
+	cors := func(method string) error {
+		_, err := client.PutBucketCorsWithContext(ctx, &s3.PutBucketCorsInput{
+			Bucket: bucket,
+			CORSConfiguration: &s3.CORSConfiguration{CORSRules: []*s3.CORSRule{{
+				AllowedMethods: aws.StringSlice([]string{method}),
+				AllowedOrigins: aws.StringSlice([]string{"*"}),
+			}}},
+		})
+		return err
+	}
+	expectAWSError(t, cors("PATCH"), "InvalidRequest")
+	if err := cors("GET"); err != nil {
+		t.Fatal(err)
+	}
+
+	website := func(config *s3.WebsiteConfiguration) error {
+		_, err := client.PutBucketWebsiteWithContext(ctx, &s3.PutBucketWebsiteInput{Bucket: bucket, WebsiteConfiguration: config})
+		return err
+	}
+	expectAWSError(t, website(&s3.WebsiteConfiguration{ErrorDocument: &s3.ErrorDocument{Key: aws.String("error.html")}}), "InvalidArgument")
+	expectAWSError(t, website(&s3.WebsiteConfiguration{IndexDocument: &s3.IndexDocument{Suffix: aws.String("a/index.html")}}), "InvalidArgument")
+	if err := website(&s3.WebsiteConfiguration{IndexDocument: &s3.IndexDocument{Suffix: aws.String("index.html")}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tagging := func(key string) error {
+		_, err := client.PutBucketTaggingWithContext(ctx, &s3.PutBucketTaggingInput{
+			Bucket:  bucket,
+			Tagging: &s3.Tagging{TagSet: []*s3.Tag{{Key: aws.String(key), Value: aws.String("web")}}},
+		})
+		return err
+	}
+	expectAWSError(t, tagging("aws:team"), "InvalidTag")
+	if err := tagging("team"); err != nil {
+		t.Fatal(err)
+	}
+	tags, err := client.GetBucketTaggingWithContext(ctx, &s3.GetBucketTaggingInput{Bucket: bucket})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags.TagSet) != 1 || aws.StringValue(tags.TagSet[0].Key) != "team" {
+		t.Fatalf("unexpected tags %v", tags.TagSet)
+	}
+
+	if _, err := client.DeleteBucketCorsWithContext(ctx, &s3.DeleteBucketCorsInput{Bucket: bucket}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.DeleteBucketWebsiteWithContext(ctx, &s3.DeleteBucketWebsiteInput{Bucket: bucket}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.DeleteBucketTaggingWithContext(ctx, &s3.DeleteBucketTaggingInput{Bucket: bucket}); err != nil {
+		t.Fatal(err)
+	}
+	for _, subresource := range []string{"cors", "website", "tagging"} {
+		if _, ok := server.BucketSubresource("assets", subresource); ok {
+			t.Fatalf("%s still set after deleting it", subresource)
+		}
+	}
+}
+
+func TestServerBucketEncryption(t *testing.T) {
+	server := NewServer()
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	client := s3.New(testSession(server, server.AccessKey, server.SecretKey))
+
+	if _, err := server.API().CreateUser(ctx, admin.User{ID: "demo", DisplayName: "Demo"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.CreateBucket("assets", "demo"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := client.GetBucketEncryptionWithContext(ctx, &s3.GetBucketEncryptionInput{Bucket: aws.String("assets")})
+	expectAWSError(t, err, "ServerSideEncryptionConfigurationNotFoundError")
+
+	encrypt := func(algorithm, keyID string) error {
+		def := &s3.ServerSideEncryptionByDefault{SSEAlgorithm: aws.String(algorithm)}
+		if keyID != "" {
+			def.KMSMasterKeyID = aws.String(keyID)
+		}
+		_, err := client.PutBucketEncryptionWithContext(ctx, &s3.PutBucketEncryptionInput{
+			Bucket: aws.String("assets"),
+			ServerSideEncryptionConfiguration: &s3.ServerSideEncryptionConfiguration{
+				Rules: []*s3.ServerSideEncryptionRule{{ApplyServerSideEncryptionByDefault: def}},
+			},
+		})
+		return err
+	}
+
+	expectAWSError(t, encrypt("aws:kms", ""), "InvalidArgument")
+	expectAWSError(t, encrypt("AES256", "assets-key"), "InvalidArgument")
+	if err := encrypt("aws:kms", "assets-key"); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := client.GetBucketEncryptionWithContext(ctx, &s3.GetBucketEncryptionInput{Bucket: aws.String("assets")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def := out.ServerSideEncryptionConfiguration.Rules[0].ApplyServerSideEncryptionByDefault; aws.StringValue(def.KMSMasterKeyID) != "assets-key" {
+		t.Fatalf("unexpected encryption %v", def)
+	}
+
+	if _, err := client.DeleteBucketEncryptionWithContext(ctx, &s3.DeleteBucketEncryptionInput{Bucket: aws.String("assets")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := server.BucketSubresource("assets", "encryption"); ok {
+		t.Fatal("encryption configuration still set after deleting it")
+	}
+}
+
+func TestServerBucketACL(t *testing.T) {
+	server := NewServer()
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	client := s3.New(testSession(server, server.AccessKey, server.SecretKey))
+
+	if _, err := server.API().CreateUser(ctx, admin.User{ID: "demo", DisplayName: "Demo"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.CreateBucket("assets", "demo"); err != nil {
+		t.Fatal(err)
+	}
+
+	acl, err := client.GetBucketAclWithContext(ctx, &s3.GetBucketAclInput{Bucket: aws.String("assets")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aws.StringValue(acl.Owner.DisplayName) != "Demo" || len(acl.Grants) != 1 || aws.StringValue(acl.Grants[0].Permission) != "FULL_CONTROL" {
+		t.Fatalf("unexpected initial ACL %v", acl)
+	}
+
+	_, err = client.PutBucketAclWithContext(ctx, &s3.PutBucketAclInput{Bucket: aws.String("assets"), ACL: aws.String("bucket-owner-read")})
+	expectAWSError(t, err, "InvalidArgument")
+	if _, err := client.PutBucketAclWithContext(ctx, &s3.PutBucketAclInput{Bucket: aws.String("assets"), ACL: aws.String("public-read")}); err != nil {
+		t.Fatal(err)
+	}
+	acl, err = client.GetBucketAclWithContext(ctx, &s3.GetBucketAclInput{Bucket: aws.String("assets")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acl.Grants) != 2 || aws.StringValue(acl.Grants[1].Grantee.URI) != allUsersGroup {
+		t.Fatalf("unexpected public-read ACL %v", acl)
+	}
+
+	grant := func(id string) error {
+		_, err := client.PutBucketAclWithContext(ctx, &s3.PutBucketAclInput{
+			Bucket: aws.String("assets"),
+			AccessControlPolicy: &s3.AccessControlPolicy{
+				Owner: acl.Owner,
+				Grants: []*s3.Grant{{
+					Grantee:    (&s3.Grantee{}).SetType(s3.TypeCanonicalUser).SetID(id),
+					Permission: aws.String("READ"),
+				}},
+			},
+		})
+		return err
+	}
+	expectAWSError(t, grant("missing"), "InvalidArgument")
+	if err := grant("demo"); err != nil {
+		t.Fatal(err)
+	}
+	document, _ := server.BucketSubresource("assets", "acl")
+	if !strings.Contains(document, `<ID>demo</ID><DisplayName>Demo</DisplayName></Grantee><Permission>READ</Permission>`) {
+		t.Fatalf("unexpected ACL %s", document)
+	}
+}
@@ -0,0 +1,394 @@
+// Package rgwtest provides an in-memory fake of the radosgw admin API for
+// tests.
+//
+// The fake implements the parts of the admin API used by the provider
+// (users, subusers, keys, caps, quotas, buckets, usage, info and the
+// period), the bucket subresources of the S3 API, the topics of the SNS
+// API and the roles and user policies of the IAM API managed by it,
+// verifies the AWS v4 signature of every request and responds with the
+// error codes that radosgw uses.
+package rgwtest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/ceph/go-ceph/rgw/admin"
+)
+
+const (
+	// AdminUserID is the ID of the admin user that is created with the server.
+	AdminUserID = "admin"
+
+	// adminPath is the path prefix of the admin API.
+	adminPath = "/admin"
+)
+
+// Server is a fake radosgw serving the admin API over HTTP.
+type Server struct {
+	*httptest.Server
+
+	// AccessKey and SecretKey are the credentials of the admin user.
+	AccessKey string
+	SecretKey string
+
+	mu      sync.Mutex
+	users   map[string]*user
+	buckets map[string]*bucket
+
+	// storageClasses are the storage classes of the placement targets.
+	storageClasses map[string][]string
+
+	// topics are the bucket notification topics by ARN.
+	topics map[string]*topic
+
+	// roles are the IAM roles by "[<tenant>$]<name>".
+	roles map[string]*role
+
+	// failures are the requests to fail, by the ID returned by Fail.
+	failures      map[int]failure
+	nextFailureID int
+
+	requestID atomic.Uint64
+}
+
+// NewServer starts a fake radosgw with an admin user.  The caller must call
+// Close when finished to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		AccessKey: randomString(accessKeyAlphabet, 20),
+		SecretKey: randomString(secretKeyAlphabet, 40),
+		users:     make(map[string]*user),
+		buckets:   make(map[string]*bucket),
+
+		storageClasses: map[string][]string{defaultPlacement: {"STANDARD"}},
+		topics:         make(map[string]*topic),
+		roles:          make(map[string]*role),
+		failures:       make(map[int]failure),
+	}
+
+	s.users[AdminUserID] = &user{
+		ID:          AdminUserID,
+		DisplayName: "Admin",
+		MaxBuckets:  1000,
+		OpMask:      defaultOpMask,
+		Keys:        []key{{User: AdminUserID, AccessKey: s.AccessKey, SecretKey: s.SecretKey}},
+		Caps:        map[string]capPerm{"users": capReadWrite, "buckets": capReadWrite, "usage": capReadWrite, "metadata": capReadWrite, "info": capRead, "roles": capReadWrite, "user-policy": capReadWrite},
+		UserQuota:   disabledQuota(),
+		BucketQuota: disabledQuota(),
+		System:      true,
+	}
+
+	s.Server = httptest.NewServer(s)
+
+	return s
+}
+
+// API returns an admin API client using the credentials of the admin user.
+func (s *Server) API() *admin.API {
+	api, err := admin.New(s.URL, s.AccessKey, s.SecretKey, s.Client())
+	if err != nil {
+		panic(fmt.Sprintf("rgwtest: creating admin client: %s", err))
+	}
+	return api
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, "IncompleteBody")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	caller, code, status := s.authenticate(r, body)
+	if code != "" {
+		s.writeError(w, r, status, code)
+		return
+	}
+
+	for _, f := range s.failures {
+		if f.match(r) {
+			s.writeError(w, r, f.status, f.code)
+			return
+		}
+	}
+
+	if !isAdminRequest(r) {
+		s.serveAWS(w, r, caller, body)
+		return
+	}
+
+	if !caller.System {
+		s.writeError(w, r, http.StatusForbidden, string(admin.ErrAccessDenied))
+		return
+	}
+
+	var handler func(r *http.Request) (any, *apiError)
+	switch strings.TrimPrefix(r.URL.Path, adminPath) {
+	case "/user":
+		handler = s.handleUser
+	case "/metadata/user":
+		handler = s.handleMetadataUser
+	case "/bucket":
+		handler = s.handleBucket
+	case "/usage":
+		handler = s.handleUsage
+	case "/info":
+		handler = s.handleInfo
+	case "/realm/period":
+		handler = s.handlePeriod
+	default:
+		s.writeError(w, r, http.StatusNotFound, "NoSuchEntity")
+		return
+	}
+
+	response, apiErr := handler(r)
+	if apiErr != nil {
+		s.writeError(w, r, apiErr.status, apiErr.code)
+		return
+	}
+
+	s.writeJSON(w, response)
+}
+
+// failure is an error response the server returns instead of serving the
+// requests matched by match.
+type failure struct {
+	match  func(r *http.Request) bool
+	status int
+	code   string
+}
+
+// Fail makes the server fail the authenticated requests matched by match
+// with status and the error code, for example to simulate outages or
+// requests radosgw rejects, until the returned function is called.
+func (s *Server) Fail(match func(r *http.Request) bool, status int, code string) (restore func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextFailureID
+	s.nextFailureID++
+	s.failures[id] = failure{match: match, status: status, code: code}
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		delete(s.failures, id)
+	}
+}
+
+// apiError is an error response of the admin API.
+type apiError struct {
+	status int
+	code   string
+}
+
+// errorf returns an apiError for one of the error reasons defined by go-ceph.
+func errorf(status int, reason error) *apiError {
+	return &apiError{status: status, code: reason.Error()}
+}
+
+// isAdminRequest reports whether r is a request to the admin API, as opposed
+// to the S3 API and the other AWS APIs of radosgw.
+func isAdminRequest(r *http.Request) bool {
+	return strings.HasPrefix(r.URL.Path, adminPath+"/")
+}
+
+// writeError writes an error in the format used by radosgw, which is JSON
+// for the admin API and XML for the AWS APIs.
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, status int, code string) {
+	if !isAdminRequest(r) {
+		s.writeXMLError(w, r, status, code)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(struct {
+		Code      string `json:"Code"`
+		RequestID string `json:"RequestId"`
+		HostID    string `json:"HostId"`
+	}{
+		Code:      code,
+		RequestID: s.nextRequestID(),
+		HostID:    "rgwtest",
+	})
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	if response == nil {
+		return
+	}
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) nextRequestID() string {
+	return fmt.Sprintf("tx%021x-rgwtest", s.requestID.Add(1))
+}
+
+// authenticate verifies the AWS v4 signature of the request and returns the
+// calling user, or an error code and status if the request is not
+// authenticated.
+func (s *Server) authenticate(r *http.Request, body []byte) (*user, string, int) {
+	authorization := r.Header.Get("Authorization")
+	if authorization == "" {
+		return nil, string(admin.ErrAccessDenied), http.StatusForbidden
+	}
+
+	auth, ok := parseAuthorization(authorization)
+	if !ok {
+		return nil, string(admin.ErrAccessDenied), http.StatusForbidden
+	}
+
+	owner, key := s.findKey(auth.accessKey)
+	if owner == nil {
+		return nil, "InvalidAccessKeyId", http.StatusForbidden
+	}
+	if owner.Suspended != 0 {
+		return nil, "UserSuspended", http.StatusForbidden
+	}
+
+	contentHash := r.Header.Get("X-Amz-Content-Sha256")
+	if contentHash != "" && contentHash != "UNSIGNED-PAYLOAD" {
+		sum := sha256.Sum256(body)
+		if contentHash != hex.EncodeToString(sum[:]) {
+			return nil, "XAmzContentSHA256Mismatch", http.StatusBadRequest
+		}
+	}
+
+	signTime, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return nil, string(admin.ErrAccessDenied), http.StatusForbidden
+	}
+
+	expected, err := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), nil)
+	if err != nil {
+		return nil, string(admin.ErrInvalidArgument), http.StatusBadRequest
+	}
+	for _, name := range auth.signedHeaders {
+		switch name {
+		case "host":
+		case "content-length":
+			// net/http moves the header to ContentLength, the AWS SDK
+			// signs it as a header.
+			expected.ContentLength = r.ContentLength
+			expected.Header.Set("Content-Length", strconv.FormatInt(r.ContentLength, 10))
+		default:
+			for _, value := range r.Header.Values(name) {
+				expected.Header.Add(name, value)
+			}
+		}
+	}
+
+	signer := v4.NewSigner(credentials.NewStaticCredentials(key.AccessKey, key.SecretKey, ""))
+	signer.DisableURIPathEscaping = auth.service == "s3" && !isAdminRequest(r)
+	if _, err := signer.Sign(expected, bytes.NewReader(body), auth.service, auth.region, signTime); err != nil {
+		return nil, string(admin.ErrSignatureDoesNotMatch), http.StatusForbidden
+	}
+
+	expectedAuth, _ := parseAuthorization(expected.Header.Get("Authorization"))
+	if expectedAuth.signature != auth.signature {
+		return nil, string(admin.ErrSignatureDoesNotMatch), http.StatusForbidden
+	}
+
+	return owner, "", 0
+}
+
+// authorization is a parsed AWS v4 Authorization header.
+type authorization struct {
+	accessKey     string
+	region        string
+	service       string
+	signedHeaders []string
+	signature     string
+}
+
+func parseAuthorization(header string) (authorization, bool) {
+	const prefix = "AWS4-HMAC-SHA256 "
+	if !strings.HasPrefix(header, prefix) {
+		return authorization{}, false
+	}
+
+	var auth authorization
+	for _, part := range strings.Split(strings.TrimPrefix(header, prefix), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "Credential":
+			// <access key>/<date>/<region>/<service>/aws4_request
+			scope := strings.Split(value, "/")
+			if len(scope) != 5 {
+				return authorization{}, false
+			}
+			auth.accessKey, auth.region, auth.service = scope[0], scope[2], scope[3]
+		case "SignedHeaders":
+			auth.signedHeaders = strings.Split(value, ";")
+		case "Signature":
+			auth.signature = value
+		}
+	}
+
+	return auth, auth.accessKey != "" && auth.signature != ""
+}
+
+const (
+	accessKeyAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	secretKeyAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+func randomString(alphabet string, length int) string {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(alphabet)))
+	for i := 0; i < length; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(fmt.Sprintf("rgwtest: generating random string: %s", err))
+		}
+		sb.WriteByte(alphabet[n.Int64()])
+	}
+	return sb.String()
+}
+
+// boolParam parses a boolean query parameter the way radosgw does.
+func boolParam(r *http.Request, name string, def bool) bool {
+	if !r.URL.Query().Has(name) {
+		return def
+	}
+	value, err := strconv.ParseBool(r.URL.Query().Get(name))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// intParam parses an integer query parameter, returning def if it is absent.
+func intParam(r *http.Request, name string, def int64) (int64, *apiError) {
+	if !r.URL.Query().Has(name) {
+		return def, nil
+	}
+	value, err := strconv.ParseInt(r.URL.Query().Get(name), 10, 64)
+	if err != nil {
+		return 0, errorf(http.StatusBadRequest, admin.ErrInvalidArgument)
+	}
+	return value, nil
+}
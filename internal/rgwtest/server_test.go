@@ -0,0 +1,256 @@
+package rgwtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+)
+
+func TestServerAuthentication(t *testing.T) {
+	server := NewServer()
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+
+	if _, err := server.API().GetUser(ctx, admin.User{ID: AdminUserID}); err != nil {
+		t.Fatalf("admin credentials rejected: %s", err)
+	}
+
+	wrongSecret, err := admin.New(server.URL, server.AccessKey, "wrong-secret", server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wrongSecret.GetUser(ctx, admin.User{ID: AdminUserID}); !errors.Is(err, admin.ErrSignatureDoesNotMatch) {
+		t.Fatalf("expected %s, got %v", admin.ErrSignatureDoesNotMatch, err)
+	}
+
+	user, err := server.API().CreateUser(ctx, admin.User{ID: "demo", DisplayName: "Demo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	userAPI, err := admin.New(server.URL, user.Keys[0].AccessKey, user.Keys[0].SecretKey, server.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := userAPI.GetUser(ctx, admin.User{ID: "demo"}); !errors.Is(err, admin.ErrAccessDenied) {
+		t.Fatalf("expected %s for non-system user, got %v", admin.ErrAccessDenied, err)
+	}
+}
+
+func TestServerUsers(t *testing.T) {
+	server := NewServer()
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	api := server.API()
+
+	generateKey := false
+	user, err := api.CreateUser(ctx, admin.User{ID: "demo", DisplayName: "Demo", Tenant: "acme", GenerateKey: &generateKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != "acme$demo" || len(user.Keys) != 0 {
+		t.Fatalf("unexpected user %+v", user)
+	}
+
+	if _, err := api.CreateUser(ctx, admin.User{ID: "acme$demo", DisplayName: "Demo"}); !errors.Is(err, admin.ErrUserExists) {
+		t.Fatalf("expected %s, got %v", admin.ErrUserExists, err)
+	}
+
+	suspended := 1
+	user, err = api.ModifyUser(ctx, admin.User{ID: "acme$demo", Suspended: &suspended})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.DisplayName != "Demo" || user.Suspended == nil || *user.Suspended != 1 {
+		t.Fatalf("unexpected user after modification %+v", user)
+	}
+
+	users, err := api.GetUsers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*users) != 2 || (*users)[0] != "acme$demo" || (*users)[1] != AdminUserID {
+		t.Fatalf("unexpected users %v", *users)
+	}
+
+	if err := api.RemoveUser(ctx, admin.User{ID: "acme$demo"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.GetUser(ctx, admin.User{ID: "acme$demo"}); !errors.Is(err, admin.ErrNoSuchUser) {
+		t.Fatalf("expected %s, got %v", admin.ErrNoSuchUser, err)
+	}
+}
+
+func TestServerSubusersAndKeys(t *testing.T) {
+	server := NewServer()
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	api := server.API()
+
+	if _, err := api.CreateUser(ctx, admin.User{ID: "demo", DisplayName: "Demo"}); err != nil {
+		t.Fatal(err)
+	}
+
+	demo := admin.User{ID: "demo"}
+	readonly := admin.SubuserSpec{Name: "readonly", Access: admin.SubuserAccessRead}
+	if err := api.CreateSubuser(ctx, demo, readonly); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.CreateSubuser(ctx, demo, readonly); !errors.Is(err, admin.ErrSubuserExists) {
+		t.Fatalf("expected %s, got %v", admin.ErrSubuserExists, err)
+	}
+
+	generateKey := true
+	keys, err := api.CreateKey(ctx, admin.UserKeySpec{UID: "demo", SubUser: "readonly", KeyType: "s3", GenerateKey: &generateKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var subuserKey admin.UserKeySpec
+	for _, key := range *keys {
+		if key.User == "demo:readonly" {
+			subuserKey = key
+		}
+	}
+	if subuserKey.AccessKey == "" || subuserKey.SecretKey == "" {
+		t.Fatalf("no key generated for subuser in %+v", *keys)
+	}
+
+	if _, err := api.CreateKey(ctx, admin.UserKeySpec{UID: "demo", AccessKey: server.AccessKey, SecretKey: "secret"}); !errors.Is(err, admin.ErrKeyExists) {
+		t.Fatalf("expected %s, got %v", admin.ErrKeyExists, err)
+	}
+
+	if err := api.ModifySubuser(ctx, demo, admin.SubuserSpec{Name: "readonly", Access: admin.SubuserAccessFull}); err != nil {
+		t.Fatal(err)
+	}
+	user, err := api.GetUser(ctx, demo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(user.Subusers) != 1 || user.Subusers[0].Name != "demo:readonly" || user.Subusers[0].Access != admin.SubuserAccessReplyFull {
+		t.Fatalf("unexpected subusers %+v", user.Subusers)
+	}
+
+	purgeKeys := true
+	if err := api.RemoveSubuser(ctx, demo, admin.SubuserSpec{Name: "readonly", PurgeKeys: &purgeKeys}); err != nil {
+		t.Fatal(err)
+	}
+	user, err = api.GetUser(ctx, demo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range user.Keys {
+		if key.User == "demo:readonly" {
+			t.Fatalf("key %s of removed subuser was not purged", key.AccessKey)
+		}
+	}
+
+	err = api.RemoveKey(ctx, admin.UserKeySpec{UID: "demo", AccessKey: subuserKey.AccessKey})
+	if !errors.Is(err, admin.ErrNoSuchKey) {
+		t.Fatalf("expected %s, got %v", admin.ErrNoSuchKey, err)
+	}
+}
+
+func TestServerCapsAndQuotas(t *testing.T) {
+	server := NewServer()
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	api := server.API()
+
+	if _, err := api.CreateUser(ctx, admin.User{ID: "demo", DisplayName: "Demo"}); err != nil {
+		t.Fatal(err)
+	}
+
+	caps, err := api.AddUserCap(ctx, "demo", "users=read;buckets=read,write")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(caps) != 2 || caps[0] != (admin.UserCapSpec{Type: "buckets", Perm: "*"}) || caps[1] != (admin.UserCapSpec{Type: "users", Perm: "read"}) {
+		t.Fatalf("unexpected caps %+v", caps)
+	}
+	if _, err := api.AddUserCap(ctx, "demo", "unknown=read"); !errors.Is(err, admin.ErrInvalidCapability) {
+		t.Fatalf("expected %s, got %v", admin.ErrInvalidCapability, err)
+	}
+
+	enabled := true
+	maxObjects := int64(100)
+	if err := api.SetUserQuota(ctx, admin.QuotaSpec{UID: "demo", Enabled: &enabled, MaxObjects: &maxObjects}); err != nil {
+		t.Fatal(err)
+	}
+	quota, err := api.GetUserQuota(ctx, admin.QuotaSpec{UID: "demo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quota.Enabled == nil || !*quota.Enabled || quota.MaxObjects == nil || *quota.MaxObjects != 100 {
+		t.Fatalf("unexpected quota %+v", quota)
+	}
+}
+
+func TestServerBuckets(t *testing.T) {
+	server := NewServer()
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	api := server.API()
+
+	if _, err := api.CreateUser(ctx, admin.User{ID: "demo", DisplayName: "Demo"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.CreateBucket("assets", "demo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.PutObjects("assets", 3, 4096); err != nil {
+		t.Fatal(err)
+	}
+
+	buckets, err := api.ListBucketsWithStat(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(buckets) != 1 || buckets[0].Bucket != "assets" || buckets[0].Owner != "demo" || *buckets[0].Usage.RgwMain.NumObjects != 3 {
+		t.Fatalf("unexpected buckets %+v", buckets)
+	}
+
+	if err := api.RemoveBucket(ctx, admin.Bucket{Bucket: "assets"}); !errors.Is(err, admin.ErrBucketNotEmpty) {
+		t.Fatalf("expected %s, got %v", admin.ErrBucketNotEmpty, err)
+	}
+	purgeObjects := true
+	if err := api.RemoveBucket(ctx, admin.Bucket{Bucket: "assets", PurgeObject: &purgeObjects}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.GetBucketInfo(ctx, admin.Bucket{Bucket: "assets"}); !errors.Is(err, admin.ErrNoSuchBucket) {
+		t.Fatalf("expected %s, got %v", admin.ErrNoSuchBucket, err)
+	}
+
+	if _, err := api.GetUsage(ctx, admin.Usage{UserID: "demo"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServerFail(t *testing.T) {
+	server := NewServer()
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+
+	restore := server.Fail(func(r *http.Request) bool {
+		return r.Method == http.MethodPut && r.URL.Query().Has("key")
+	}, http.StatusServiceUnavailable, "ServiceUnavailable")
+
+	if _, err := server.API().CreateKey(ctx, admin.UserKeySpec{UID: AdminUserID, AccessKey: "FAILING"}); err == nil {
+		t.Fatal("expected the key creation to fail")
+	}
+	if _, err := server.API().GetUser(ctx, admin.User{ID: AdminUserID}); err != nil {
+		t.Fatalf("unmatched request failed: %s", err)
+	}
+
+	restore()
+	if _, err := server.API().CreateKey(ctx, admin.UserKeySpec{UID: AdminUserID, AccessKey: "WORKING", SecretKey: "secret"}); err != nil {
+		t.Fatalf("request failed after restore: %s", err)
+	}
+}
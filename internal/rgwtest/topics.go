@@ -0,0 +1,265 @@
+package rgwtest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// snsNamespace is the XML namespace of SNS API responses.
+const snsNamespace = "https://sns.amazonaws.com/doc/2010-03-31/"
+
+// zonegroupName is the name of the zonegroup of the fake, which is part of
+// topic ARNs.
+const zonegroupName = "default"
+
+var topicNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// topic is a bucket notification topic.
+type topic struct {
+	ARN        string
+	Name       string
+	Owner      string
+	Attributes map[string]string
+}
+
+// serveSNS serves a request to the SNS API of radosgw, which manages the
+// topics of bucket notifications.
+func (s *Server) serveSNS(w http.ResponseWriter, r *http.Request, caller *user, body []byte) {
+	params, err := url.ParseQuery(string(body))
+	if err != nil || r.Method != http.MethodPost {
+		s.writeError(w, r, http.StatusBadRequest, "InvalidArgument")
+		return
+	}
+
+	var response any
+	var code string
+	action := params.Get("Action")
+	switch action {
+	case "CreateTopic":
+		response, code = s.createTopic(caller, params)
+	case "GetTopicAttributes":
+		response, code = s.getTopicAttributes(caller, params.Get("TopicArn"))
+	case "DeleteTopic":
+		response, code = s.deleteTopic(caller, params.Get("TopicArn"))
+	case "ListTopics":
+		response, code = s.listTopics(caller)
+	default:
+		s.writeError(w, r, http.StatusBadRequest, "InvalidAction")
+		return
+	}
+
+	switch code {
+	case "":
+	case "NotFound":
+		s.writeError(w, r, http.StatusNotFound, code)
+		return
+	case "AccessDenied":
+		s.writeError(w, r, http.StatusForbidden, code)
+		return
+	default:
+		s.writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+
+	s.writeQueryResponse(w, snsNamespace, action, response)
+}
+
+// writeQueryResponse writes the response of an action of the query APIs of
+// radosgw, such as SNS and IAM.
+func (s *Server) writeQueryResponse(w http.ResponseWriter, namespace, action string, result any) {
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName          xml.Name
+		Namespace        string `xml:"xmlns,attr"`
+		Result           any
+		ResponseMetadata struct {
+			RequestID string `xml:"RequestId"`
+		}
+	}{
+		XMLName:   xml.Name{Local: action + "Response"},
+		Namespace: namespace,
+		Result:    result,
+		ResponseMetadata: struct {
+			RequestID string `xml:"RequestId"`
+		}{s.nextRequestID()},
+	})
+}
+
+// tenantOf returns the tenant of a user ID.
+func tenantOf(id string) string {
+	tenant, _, found := strings.Cut(id, "$")
+	if !found {
+		return ""
+	}
+	return tenant
+}
+
+// topicAttributes returns the attributes of a CreateTopic request.
+func topicAttributes(params url.Values) map[string]string {
+	attributes := make(map[string]string)
+	for name, values := range params {
+		if !strings.HasPrefix(name, "Attributes.entry.") || !strings.HasSuffix(name, ".key") {
+			continue
+		}
+		value := params.Get(strings.TrimSuffix(name, ".key") + ".value")
+		attributes[values[0]] = value
+	}
+	return attributes
+}
+
+// createTopic creates or updates a topic like radosgw, which checks the
+// push endpoint and refuses to send credentials in cleartext.
+func (s *Server) createTopic(caller *user, params url.Values) (any, string) {
+	name := params.Get("Name")
+	if !topicNamePattern.MatchString(name) {
+		return nil, "InvalidArgument"
+	}
+
+	attributes := topicAttributes(params)
+	if endpoint := attributes["push-endpoint"]; endpoint != "" {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, "InvalidArgument"
+		}
+		switch u.Scheme {
+		case "http", "https", "kafka":
+		case "amqp", "amqps":
+			if attributes["amqp-exchange"] == "" {
+				return nil, "InvalidArgument"
+			}
+		default:
+			return nil, "InvalidArgument"
+		}
+		if _, hasPassword := u.User.Password(); hasPassword && u.Scheme != "https" && u.Scheme != "amqps" && attributes["use-ssl"] != "true" {
+			return nil, "InvalidArgument"
+		}
+	}
+	if persistent, ok := attributes["persistent"]; ok && persistent != "true" && persistent != "false" {
+		return nil, "InvalidArgument"
+	}
+
+	arn := "arn:aws:sns:" + zonegroupName + ":" + tenantOf(caller.ID) + ":" + name
+	if existing, ok := s.topics[arn]; ok && existing.Owner != caller.ID && !caller.System {
+		return nil, "AccessDenied"
+	}
+	s.topics[arn] = &topic{ARN: arn, Name: name, Owner: caller.ID, Attributes: attributes}
+
+	return struct {
+		XMLName  xml.Name `xml:"CreateTopicResult"`
+		TopicArn string
+	}{TopicArn: arn}, ""
+}
+
+// lookupTopic returns the topic with the ARN if the caller may use it.
+func (s *Server) lookupTopic(caller *user, arn string) (*topic, string) {
+	t, ok := s.topics[arn]
+	if !ok {
+		return nil, "NotFound"
+	}
+	if t.Owner != caller.ID && !caller.System {
+		return nil, "AccessDenied"
+	}
+	return t, ""
+}
+
+// getTopicAttributes returns the attributes of a topic in the form used by
+// radosgw, with the push endpoint and its arguments as JSON.
+func (s *Server) getTopicAttributes(caller *user, arn string) (any, string) {
+	t, code := s.lookupTopic(caller, arn)
+	if code != "" {
+		return nil, code
+	}
+
+	args := url.Values{"Version": {"2010-03-31"}}
+	for name, value := range t.Attributes {
+		args.Set(name, value)
+	}
+	endpoint, _ := json.Marshal(map[string]any{
+		"EndpointAddress": t.Attributes["push-endpoint"],
+		"EndpointArgs":    args.Encode(),
+		"EndpointTopic":   t.Name,
+		"HasStoredSecret": false,
+		"Persistent":      t.Attributes["persistent"] == "true",
+	})
+
+	type entry struct {
+		Key   string `xml:"key"`
+		Value string `xml:"value"`
+	}
+	return struct {
+		XMLName    xml.Name `xml:"GetTopicAttributesResult"`
+		Attributes []entry  `xml:"Attributes>entry"`
+	}{Attributes: []entry{
+		{"User", t.Owner},
+		{"Name", t.Name},
+		{"EndPoint", string(endpoint)},
+		{"TopicArn", t.ARN},
+		{"OpaqueData", t.Attributes["OpaqueData"]},
+		{"Policy", ""},
+	}}, ""
+}
+
+// deleteTopic removes a topic.  Like radosgw, it succeeds for missing
+// topics.
+func (s *Server) deleteTopic(caller *user, arn string) (any, string) {
+	if _, code := s.lookupTopic(caller, arn); code == "AccessDenied" {
+		return nil, code
+	}
+	delete(s.topics, arn)
+	return nil, ""
+}
+
+// listTopics returns the topics of the tenant of the caller.
+func (s *Server) listTopics(caller *user) (any, string) {
+	var arns []string
+	for arn, t := range s.topics {
+		if tenantOf(t.Owner) == tenantOf(caller.ID) {
+			arns = append(arns, arn)
+		}
+	}
+	sort.Strings(arns)
+
+	type member struct {
+		TopicArn string
+	}
+	members := make([]member, 0, len(arns))
+	for _, arn := range arns {
+		members = append(members, member{arn})
+	}
+	return struct {
+		XMLName xml.Name `xml:"ListTopicsResult"`
+		Topics  []member `xml:"Topics>member"`
+	}{Topics: members}, ""
+}
+
+// TopicAttributes returns the attributes of the topic with the ARN, and
+// whether it exists.
+func (s *Server) TopicAttributes(arn string) (map[string]string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.topics[arn]
+	if !ok {
+		return nil, false
+	}
+	attributes := make(map[string]string, len(t.Attributes))
+	for name, value := range t.Attributes {
+		attributes[name] = value
+	}
+	return attributes, true
+}
+
+// DeleteTopic removes the topic with the ARN, for example to simulate
+// changes outside of Terraform.
+func (s *Server) DeleteTopic(arn string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.topics, arn)
+}
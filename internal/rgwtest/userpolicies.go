@@ -0,0 +1,84 @@
+package rgwtest
+
+import (
+	"encoding/xml"
+	"sort"
+)
+
+// updateUserPolicies calls update with the inline policies of a user.
+// Unlike roles, users are referenced by their full ID, "[<tenant>$]<user>".
+func (s *Server) updateUserPolicies(uid string, update func(map[string]string) string) string {
+	u, ok := s.users[uid]
+	if !ok {
+		return "NoSuchEntity"
+	}
+	if u.Policies == nil {
+		u.Policies = make(map[string]string)
+	}
+	return update(u.Policies)
+}
+
+// getUserPolicy returns an inline policy of a user.
+func (s *Server) getUserPolicy(uid, policyName string) (any, string) {
+	u, ok := s.users[uid]
+	if !ok {
+		return nil, "NoSuchEntity"
+	}
+	policy, ok := u.Policies[policyName]
+	if !ok {
+		return nil, "NoSuchEntity"
+	}
+	return struct {
+		XMLName        xml.Name `xml:"GetUserPolicyResult"`
+		UserName       string
+		PolicyName     string
+		PolicyDocument string
+	}{UserName: uid, PolicyName: policyName, PolicyDocument: policy}, ""
+}
+
+// listUserPolicies returns the names of the inline policies of a user.
+func (s *Server) listUserPolicies(uid string) (any, string) {
+	u, ok := s.users[uid]
+	if !ok {
+		return nil, "NoSuchEntity"
+	}
+	names := make([]string, 0, len(u.Policies))
+	for policyName := range u.Policies {
+		names = append(names, policyName)
+	}
+	sort.Strings(names)
+	return struct {
+		XMLName     xml.Name `xml:"ListUserPoliciesResult"`
+		PolicyNames []string `xml:"PolicyNames>member"`
+		IsTruncated bool
+	}{PolicyNames: names}, ""
+}
+
+// UserPolicies returns the inline policies of the user with the ID
+// "[<tenant>$]<user>" by name, and whether the user exists.
+func (s *Server) UserPolicies(uid string) (map[string]string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[uid]
+	if !ok {
+		return nil, false
+	}
+	policies := make(map[string]string, len(u.Policies))
+	for policyName, policy := range u.Policies {
+		policies[policyName] = policy
+	}
+	return policies, true
+}
+
+// DeleteUserPolicy removes an inline policy of the user with the ID
+// "[<tenant>$]<user>", for example to simulate changes outside of
+// Terraform.
+func (s *Server) DeleteUserPolicy(uid, policyName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u, ok := s.users[uid]; ok {
+		delete(u.Policies, policyName)
+	}
+}
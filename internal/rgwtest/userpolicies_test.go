@@ -0,0 +1,65 @@
+package rgwtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/ceph/go-ceph/rgw/admin"
+)
+
+func TestServerUserPolicies(t *testing.T) {
+	server := NewServer()
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	client := iam.New(testSession(server, server.AccessKey, server.SecretKey))
+
+	user, err := server.API().CreateUser(ctx, admin.User{ID: "demo", Tenant: "acme", DisplayName: "Demo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const policy = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}]}`
+	_, err = client.PutUserPolicyWithContext(ctx, &iam.PutUserPolicyInput{UserName: aws.String("demo"), PolicyName: aws.String("read"), PolicyDocument: aws.String(policy)})
+	expectAWSError(t, err, "NoSuchEntity")
+	_, err = client.PutUserPolicyWithContext(ctx, &iam.PutUserPolicyInput{UserName: aws.String("acme$demo"), PolicyName: aws.String("read"), PolicyDocument: aws.String("{}")})
+	expectAWSError(t, err, "MalformedPolicyDocument")
+
+	if _, err := client.PutUserPolicyWithContext(ctx, &iam.PutUserPolicyInput{
+		UserName:       aws.String("acme$demo"),
+		PolicyName:     aws.String("read"),
+		PolicyDocument: aws.String(policy),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.GetUserPolicyWithContext(ctx, &iam.GetUserPolicyInput{UserName: aws.String("acme$demo"), PolicyName: aws.String("read")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aws.StringValue(got.PolicyDocument) != policy {
+		t.Fatalf("unexpected policy %s", aws.StringValue(got.PolicyDocument))
+	}
+	policies, err := client.ListUserPoliciesWithContext(ctx, &iam.ListUserPoliciesInput{UserName: aws.String("acme$demo")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(policies.PolicyNames) != 1 || aws.StringValue(policies.PolicyNames[0]) != "read" {
+		t.Fatalf("unexpected policies %v", policies.PolicyNames)
+	}
+
+	if _, err := client.DeleteUserPolicyWithContext(ctx, &iam.DeleteUserPolicyInput{UserName: aws.String("acme$demo"), PolicyName: aws.String("read")}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.GetUserPolicyWithContext(ctx, &iam.GetUserPolicyInput{UserName: aws.String("acme$demo"), PolicyName: aws.String("read")})
+	expectAWSError(t, err, "NoSuchEntity")
+
+	// the roles cap does not allow managing user policies
+	if _, err := server.API().AddUserCap(ctx, user.ID, "roles=*"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = iam.New(testSession(server, user.Keys[0].AccessKey, user.Keys[0].SecretKey)).
+		GetUserPolicyWithContext(ctx, &iam.GetUserPolicyInput{UserName: aws.String("acme$demo"), PolicyName: aws.String("read")})
+	expectAWSError(t, err, "AccessDenied")
+}
@@ -0,0 +1,688 @@
+package rgwtest
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ceph/go-ceph/rgw/admin"
+)
+
+// defaultOpMask is the op mask of new users.
+const defaultOpMask = "read, write, delete"
+
+type user struct {
+	ID          string
+	DisplayName string
+	Email       string
+	Suspended   int
+	MaxBuckets  int64
+	OpMask      string
+	Subusers    []subuser
+	Keys        []key
+	SwiftKeys   []key
+	Caps        map[string]capPerm
+	UserQuota   quota
+	BucketQuota quota
+
+	// System users are allowed to use the admin API.
+	System bool
+
+	// Policies are the inline IAM policies of the user by name.
+	Policies map[string]string
+}
+
+type subuser struct {
+	ID          string `json:"id"`
+	Permissions string `json:"permissions"`
+}
+
+type key struct {
+	User      string `json:"user"`
+	AccessKey string `json:"access_key,omitempty"`
+	SecretKey string `json:"secret_key"`
+}
+
+type quota struct {
+	Enabled    bool  `json:"enabled"`
+	CheckOnRaw bool  `json:"check_on_raw"`
+	MaxSize    int64 `json:"max_size"`
+	MaxSizeKB  int64 `json:"max_size_kb"`
+	MaxObjects int64 `json:"max_objects"`
+}
+
+func disabledQuota() quota {
+	return quota{MaxSize: -1, MaxObjects: -1}
+}
+
+// userInfo is the representation of a user returned by the admin API.
+type userInfo struct {
+	UserID              string     `json:"user_id"`
+	DisplayName         string     `json:"display_name"`
+	Email               string     `json:"email"`
+	Suspended           int        `json:"suspended"`
+	MaxBuckets          int64      `json:"max_buckets"`
+	Subusers            []subuser  `json:"subusers"`
+	Keys                []key      `json:"keys"`
+	SwiftKeys           []key      `json:"swift_keys"`
+	Caps                []capInfo  `json:"caps"`
+	OpMask              string     `json:"op_mask"`
+	DefaultPlacement    string     `json:"default_placement"`
+	DefaultStorageClass string     `json:"default_storage_class"`
+	PlacementTags       []string   `json:"placement_tags"`
+	BucketQuota         quota      `json:"bucket_quota"`
+	UserQuota           quota      `json:"user_quota"`
+	TempURLKeys         []string   `json:"temp_url_keys"`
+	Type                string     `json:"type"`
+	MfaIds              []string   `json:"mfa_ids"`
+	Stats               *userStats `json:"stats,omitempty"`
+}
+
+type userStats struct {
+	Size        uint64 `json:"size"`
+	SizeActual  uint64 `json:"size_actual"`
+	SizeRounded uint64 `json:"size_rounded"`
+	NumObjects  uint64 `json:"num_objects"`
+}
+
+func (u *user) info() userInfo {
+	return userInfo{
+		UserID:        u.ID,
+		DisplayName:   u.DisplayName,
+		Email:         u.Email,
+		Suspended:     u.Suspended,
+		MaxBuckets:    u.MaxBuckets,
+		Subusers:      append([]subuser{}, u.Subusers...),
+		Keys:          append([]key{}, u.Keys...),
+		SwiftKeys:     append([]key{}, u.SwiftKeys...),
+		Caps:          capsInfo(u.Caps),
+		OpMask:        u.OpMask,
+		PlacementTags: []string{},
+		BucketQuota:   u.BucketQuota,
+		UserQuota:     u.UserQuota,
+		TempURLKeys:   []string{},
+		Type:          "rgw",
+		MfaIds:        []string{},
+	}
+}
+
+// subuserID returns the qualified ID of a subuser of u, which is
+// "<user>:<subuser>".
+func (u *user) subuserID(name string) string {
+	if strings.HasPrefix(name, u.ID+":") {
+		return name
+	}
+	return u.ID + ":" + name
+}
+
+func (u *user) findSubuser(id string) int {
+	for i, subuser := range u.Subusers {
+		if subuser.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// findKey returns the user owning the S3 access key and the key.
+func (s *Server) findKey(accessKey string) (*user, key) {
+	for _, u := range s.users {
+		for _, k := range u.Keys {
+			if k.AccessKey == accessKey {
+				return u, k
+			}
+		}
+	}
+	return nil, key{}
+}
+
+func (s *Server) handleUser(r *http.Request) (any, *apiError) {
+	query := r.URL.Query()
+
+	switch r.Method {
+	case http.MethodGet:
+		if query.Has("quota") {
+			return s.getQuota(r)
+		}
+		return s.getUser(r)
+	case http.MethodPut:
+		switch {
+		case query.Has("key"):
+			return s.createKey(r)
+		case query.Has("caps"):
+			return s.addCaps(r)
+		case query.Has("quota"):
+			return s.setQuota(r)
+		case query.Has("subuser"):
+			return s.createSubuser(r)
+		}
+		return s.createUser(r)
+	case http.MethodPost:
+		if query.Has("subuser") {
+			return s.modifySubuser(r)
+		}
+		return s.modifyUser(r)
+	case http.MethodDelete:
+		switch {
+		case query.Has("key"):
+			return s.removeKey(r)
+		case query.Has("caps"):
+			return s.removeCaps(r)
+		case query.Has("subuser"):
+			return s.removeSubuser(r)
+		}
+		return s.removeUser(r)
+	}
+
+	return nil, &apiError{status: http.StatusMethodNotAllowed, code: "MethodNotAllowed"}
+}
+
+// lookupUser returns the user referenced by the uid parameter.
+func (s *Server) lookupUser(r *http.Request) (*user, *apiError) {
+	uid := r.URL.Query().Get("uid")
+	if uid == "" {
+		return nil, errorf(http.StatusBadRequest, admin.ErrInvalidArgument)
+	}
+
+	u, ok := s.users[uid]
+	if !ok {
+		return nil, errorf(http.StatusNotFound, admin.ErrNoSuchUser)
+	}
+
+	return u, nil
+}
+
+func (s *Server) handleMetadataUser(r *http.Request) (any, *apiError) {
+	if r.Method != http.MethodGet {
+		return nil, &apiError{status: http.StatusMethodNotAllowed, code: "MethodNotAllowed"}
+	}
+
+	ids := make([]string, 0, len(s.users))
+	for id := range s.users {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+func (s *Server) getUser(r *http.Request) (any, *apiError) {
+	query := r.URL.Query()
+
+	var u *user
+	switch {
+	case query.Get("uid") != "":
+		u = s.users[query.Get("uid")]
+	case query.Get("access-key") != "":
+		u, _ = s.findKey(query.Get("access-key"))
+	default:
+		return nil, errorf(http.StatusBadRequest, admin.ErrInvalidArgument)
+	}
+	if u == nil {
+		return nil, errorf(http.StatusNotFound, admin.ErrNoSuchUser)
+	}
+
+	info := u.info()
+	if boolParam(r, "stats", false) {
+		var stats userStats
+		for _, b := range s.buckets {
+			if b.Owner == u.ID {
+				stats.Size += b.Size
+				stats.SizeActual += b.Size
+				stats.SizeRounded += b.Size
+				stats.NumObjects += b.NumObjects
+			}
+		}
+		info.Stats = &stats
+	}
+
+	return info, nil
+}
+
+func (s *Server) createUser(r *http.Request) (any, *apiError) {
+	query := r.URL.Query()
+
+	uid := query.Get("uid")
+	if uid == "" || query.Get("display-name") == "" {
+		return nil, errorf(http.StatusBadRequest, admin.ErrInvalidArgument)
+	}
+	if tenant := query.Get("tenant"); tenant != "" {
+		uid = tenant + "$" + uid
+	}
+	if _, exists := s.users[uid]; exists {
+		return nil, errorf(http.StatusConflict, admin.ErrUserExists)
+	}
+	if email := query.Get("email"); email != "" && s.emailInUse(email) {
+		return nil, errorf(http.StatusConflict, admin.ErrEmailExists)
+	}
+
+	maxBuckets, apiErr := intParam(r, "max-buckets", 1000)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	suspended, apiErr := intParam(r, "suspended", 0)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	u := &user{
+		ID:          uid,
+		DisplayName: query.Get("display-name"),
+		Email:       query.Get("email"),
+		Suspended:   int(suspended),
+		MaxBuckets:  maxBuckets,
+		OpMask:      defaultOpMask,
+		Caps:        make(map[string]capPerm),
+		UserQuota:   disabledQuota(),
+		BucketQuota: disabledQuota(),
+	}
+	if opMask := query.Get("op-mask"); opMask != "" {
+		u.OpMask = opMask
+	}
+
+	if userCaps := query.Get("user-caps"); userCaps != "" {
+		caps, apiErr := parseCaps(userCaps)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		for capType, perm := range caps {
+			u.Caps[capType] |= perm
+		}
+	}
+
+	keyType := query.Get("key-type")
+	if keyType != "" && keyType != "s3" && keyType != "swift" {
+		return nil, errorf(http.StatusBadRequest, admin.ErrInvalidKeyType)
+	}
+
+	accessKey, secretKey := query.Get("access-key"), query.Get("secret-key")
+	if accessKey != "" || secretKey != "" || boolParam(r, "generate-key", true) {
+		k, apiErr := s.newS3Key(uid, accessKey, secretKey)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		u.Keys = append(u.Keys, k)
+	}
+
+	s.users[uid] = u
+
+	return u.info(), nil
+}
+
+func (s *Server) emailInUse(email string) bool {
+	for _, u := range s.users {
+		if u.Email == email {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) modifyUser(r *http.Request) (any, *apiError) {
+	u, apiErr := s.lookupUser(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	query := r.URL.Query()
+
+	if query.Has("display-name") {
+		u.DisplayName = query.Get("display-name")
+	}
+	if query.Has("email") && query.Get("email") != u.Email {
+		if s.emailInUse(query.Get("email")) {
+			return nil, errorf(http.StatusConflict, admin.ErrEmailExists)
+		}
+		u.Email = query.Get("email")
+	}
+	if query.Has("op-mask") {
+		u.OpMask = query.Get("op-mask")
+	}
+
+	maxBuckets, apiErr := intParam(r, "max-buckets", u.MaxBuckets)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	u.MaxBuckets = maxBuckets
+
+	suspended, apiErr := intParam(r, "suspended", int64(u.Suspended))
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	u.Suspended = int(suspended)
+
+	accessKey, secretKey := query.Get("access-key"), query.Get("secret-key")
+	if accessKey != "" || boolParam(r, "generate-key", false) {
+		k, apiErr := s.newS3Key(u.ID, accessKey, secretKey)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		u.Keys = append(u.Keys, k)
+	}
+
+	return u.info(), nil
+}
+
+func (s *Server) removeUser(r *http.Request) (any, *apiError) {
+	u, apiErr := s.lookupUser(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	purgeData := boolParam(r, "purge-data", false)
+	for name, b := range s.buckets {
+		if b.Owner != u.ID {
+			continue
+		}
+		if !purgeData {
+			// radosgw refuses to remove users owning buckets with EEXIST
+			return nil, &apiError{status: http.StatusConflict, code: "BucketAlreadyExists"}
+		}
+		delete(s.buckets, name)
+	}
+
+	delete(s.users, u.ID)
+
+	return nil, nil
+}
+
+// parseSubuserAccess maps the access parameter to the value returned by the
+// admin API.
+func parseSubuserAccess(access string) (string, bool) {
+	switch admin.SubuserAccess(access) {
+	case admin.SubuserAccessNone:
+		return string(admin.SubuserAccessReplyNone), true
+	case admin.SubuserAccessRead:
+		return string(admin.SubuserAccessReplyRead), true
+	case admin.SubuserAccessWrite:
+		return string(admin.SubuserAccessReplyWrite), true
+	case admin.SubuserAccessReadWrite:
+		return string(admin.SubuserAccessReplyReadWrite), true
+	case admin.SubuserAccessFull:
+		return string(admin.SubuserAccessReplyFull), true
+	}
+	return "", false
+}
+
+func (s *Server) createSubuser(r *http.Request) (any, *apiError) {
+	u, apiErr := s.lookupUser(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	query := r.URL.Query()
+
+	if query.Get("subuser") == "" {
+		return nil, errorf(http.StatusBadRequest, admin.ErrInvalidArgument)
+	}
+	id := u.subuserID(query.Get("subuser"))
+	if u.findSubuser(id) >= 0 {
+		return nil, errorf(http.StatusConflict, admin.ErrSubuserExists)
+	}
+
+	permissions, ok := parseSubuserAccess(query.Get("access"))
+	if !ok {
+		return nil, errorf(http.StatusBadRequest, admin.ErrInvalidAccess)
+	}
+
+	keyType := query.Get("key-type")
+	if keyType == "" {
+		keyType = "swift"
+	}
+	if keyType != "s3" && keyType != "swift" {
+		return nil, errorf(http.StatusBadRequest, admin.ErrInvalidKeyType)
+	}
+
+	secretKey := query.Get("secret-key")
+	if secretKey != "" || boolParam(r, "generate-secret", false) {
+		switch keyType {
+		case "swift":
+			u.SwiftKeys = append(u.SwiftKeys, newSwiftKey(id, secretKey))
+		case "s3":
+			k, apiErr := s.newS3Key(id, query.Get("access-key"), secretKey)
+			if apiErr != nil {
+				return nil, apiErr
+			}
+			u.Keys = append(u.Keys, k)
+		}
+	}
+
+	u.Subusers = append(u.Subusers, subuser{ID: id, Permissions: permissions})
+
+	return u.Subusers, nil
+}
+
+func (s *Server) modifySubuser(r *http.Request) (any, *apiError) {
+	u, apiErr := s.lookupUser(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	query := r.URL.Query()
+
+	id := u.subuserID(query.Get("subuser"))
+	i := u.findSubuser(id)
+	if i < 0 {
+		return nil, &apiError{status: http.StatusNotFound, code: "NoSuchSubUser"}
+	}
+
+	if query.Has("access") {
+		permissions, ok := parseSubuserAccess(query.Get("access"))
+		if !ok {
+			return nil, errorf(http.StatusBadRequest, admin.ErrInvalidAccess)
+		}
+		u.Subusers[i].Permissions = permissions
+	}
+
+	if secret := query.Get("secret"); secret != "" || boolParam(r, "generate-secret", false) {
+		u.SwiftKeys = removeKeys(u.SwiftKeys, func(k key) bool { return k.User == id })
+		u.SwiftKeys = append(u.SwiftKeys, newSwiftKey(id, secret))
+	}
+
+	return u.Subusers, nil
+}
+
+func (s *Server) removeSubuser(r *http.Request) (any, *apiError) {
+	u, apiErr := s.lookupUser(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	id := u.subuserID(r.URL.Query().Get("subuser"))
+	i := u.findSubuser(id)
+	if i < 0 {
+		return nil, &apiError{status: http.StatusNotFound, code: "NoSuchSubUser"}
+	}
+
+	u.Subusers = append(u.Subusers[:i], u.Subusers[i+1:]...)
+
+	if boolParam(r, "purge-keys", true) {
+		ownedBySubuser := func(k key) bool { return k.User == id }
+		u.Keys = removeKeys(u.Keys, ownedBySubuser)
+		u.SwiftKeys = removeKeys(u.SwiftKeys, ownedBySubuser)
+	}
+
+	return nil, nil
+}
+
+func removeKeys(keys []key, remove func(key) bool) []key {
+	kept := keys[:0]
+	for _, k := range keys {
+		if !remove(k) {
+			kept = append(kept, k)
+		}
+	}
+	return kept
+}
+
+// newS3Key returns a new S3 key for owner, generating the access and secret
+// key if they are empty.
+func (s *Server) newS3Key(owner, accessKey, secretKey string) (key, *apiError) {
+	if accessKey == "" {
+		accessKey = randomString(accessKeyAlphabet, 20)
+	} else if u, _ := s.findKey(accessKey); u != nil {
+		return key{}, errorf(http.StatusConflict, admin.ErrKeyExists)
+	}
+	if secretKey == "" {
+		secretKey = randomString(secretKeyAlphabet, 40)
+	}
+
+	return key{User: owner, AccessKey: accessKey, SecretKey: secretKey}, nil
+}
+
+func newSwiftKey(owner, secretKey string) key {
+	if secretKey == "" {
+		secretKey = randomString(secretKeyAlphabet, 40)
+	}
+	return key{User: owner, SecretKey: secretKey}
+}
+
+func (s *Server) createKey(r *http.Request) (any, *apiError) {
+	u, apiErr := s.lookupUser(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	query := r.URL.Query()
+
+	owner := u.ID
+	if name := query.Get("subuser"); name != "" {
+		owner = u.subuserID(name)
+		if u.findSubuser(owner) < 0 {
+			return nil, &apiError{status: http.StatusNotFound, code: "NoSuchSubUser"}
+		}
+	}
+
+	switch query.Get("key-type") {
+	case "", "s3":
+		accessKey, secretKey := query.Get("access-key"), query.Get("secret-key")
+
+		// an existing access key of the user gets a new secret
+		for i, k := range u.Keys {
+			if accessKey != "" && k.AccessKey == accessKey {
+				if secretKey == "" {
+					secretKey = randomString(secretKeyAlphabet, 40)
+				}
+				u.Keys[i].SecretKey = secretKey
+				return u.Keys, nil
+			}
+		}
+
+		k, apiErr := s.newS3Key(owner, accessKey, secretKey)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		u.Keys = append(u.Keys, k)
+
+		return u.Keys, nil
+	case "swift":
+		if owner == u.ID {
+			return nil, errorf(http.StatusBadRequest, admin.ErrInvalidArgument)
+		}
+
+		u.SwiftKeys = removeKeys(u.SwiftKeys, func(k key) bool { return k.User == owner })
+		u.SwiftKeys = append(u.SwiftKeys, newSwiftKey(owner, query.Get("secret-key")))
+
+		return u.SwiftKeys, nil
+	}
+
+	return nil, errorf(http.StatusBadRequest, admin.ErrInvalidKeyType)
+}
+
+func (s *Server) removeKey(r *http.Request) (any, *apiError) {
+	u, apiErr := s.lookupUser(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	query := r.URL.Query()
+
+	switch query.Get("key-type") {
+	case "", "s3":
+		accessKey := query.Get("access-key")
+		if accessKey == "" {
+			return nil, errorf(http.StatusBadRequest, admin.ErrInvalidAccessKey)
+		}
+
+		before := len(u.Keys)
+		u.Keys = removeKeys(u.Keys, func(k key) bool { return k.AccessKey == accessKey })
+		if len(u.Keys) == before {
+			return nil, errorf(http.StatusNotFound, admin.ErrNoSuchKey)
+		}
+
+		return nil, nil
+	case "swift":
+		owner := u.subuserID(query.Get("subuser"))
+
+		before := len(u.SwiftKeys)
+		u.SwiftKeys = removeKeys(u.SwiftKeys, func(k key) bool { return k.User == owner })
+		if len(u.SwiftKeys) == before {
+			return nil, errorf(http.StatusNotFound, admin.ErrNoSuchKey)
+		}
+
+		return nil, nil
+	}
+
+	return nil, errorf(http.StatusBadRequest, admin.ErrInvalidKeyType)
+}
+
+func (s *Server) getQuota(r *http.Request) (any, *apiError) {
+	u, apiErr := s.lookupUser(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	switch r.URL.Query().Get("quota-type") {
+	case "user":
+		return u.UserQuota, nil
+	case "bucket":
+		return u.BucketQuota, nil
+	}
+
+	return nil, errorf(http.StatusBadRequest, admin.ErrInvalidArgument)
+}
+
+func (s *Server) setQuota(r *http.Request) (any, *apiError) {
+	u, apiErr := s.lookupUser(r)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	var q *quota
+	switch r.URL.Query().Get("quota-type") {
+	case "user":
+		q = &u.UserQuota
+	case "bucket":
+		q = &u.BucketQuota
+	default:
+		return nil, errorf(http.StatusBadRequest, admin.ErrInvalidArgument)
+	}
+
+	return nil, updateQuota(r, q)
+}
+
+// updateQuota applies the quota parameters of the request to q.
+func updateQuota(r *http.Request, q *quota) *apiError {
+	updated := *q
+	updated.Enabled = boolParam(r, "enabled", q.Enabled)
+	updated.CheckOnRaw = boolParam(r, "check-on-raw", q.CheckOnRaw)
+
+	var apiErr *apiError
+	if updated.MaxObjects, apiErr = intParam(r, "max-objects", q.MaxObjects); apiErr != nil {
+		return apiErr
+	}
+	if updated.MaxSize, apiErr = intParam(r, "max-size", q.MaxSize); apiErr != nil {
+		return apiErr
+	}
+	if r.URL.Query().Has("max-size-kb") {
+		if updated.MaxSizeKB, apiErr = intParam(r, "max-size-kb", 0); apiErr != nil {
+			return apiErr
+		}
+		updated.MaxSize = updated.MaxSizeKB * 1024
+	} else {
+		updated.MaxSizeKB = updated.MaxSize / 1024
+	}
+
+	*q = updated
+	return nil
+}
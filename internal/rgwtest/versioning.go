@@ -0,0 +1,103 @@
+package rgwtest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+)
+
+// versioningConfiguration is the versioning state of a bucket.
+type versioningConfiguration struct {
+	Status    string `xml:"Status"`
+	MfaDelete string `xml:"MfaDelete"`
+}
+
+// versioningOf returns the versioning state of a bucket, with an empty
+// status if versioning was never enabled.
+func versioningOf(b *bucket) versioningConfiguration {
+	var config versioningConfiguration
+	_ = xml.Unmarshal(b.Subresources["versioning"], &config)
+	if config.MfaDelete == "" {
+		config.MfaDelete = "Disabled"
+	}
+	return config
+}
+
+// validateVersioning checks a versioning configuration like radosgw, which
+// keeps versioning enabled on buckets with object lock and needs a token in
+// the x-amz-mfa header to change MFA delete.
+func validateVersioning(_ *Server, b *bucket, header http.Header, body []byte) string {
+	var config versioningConfiguration
+	if err := xml.Unmarshal(body, &config); err != nil {
+		return "MalformedXML"
+	}
+	if config.Status != "Enabled" && config.Status != "Suspended" {
+		return "MalformedXML"
+	}
+	if config.MfaDelete != "" && config.MfaDelete != "Enabled" && config.MfaDelete != "Disabled" {
+		return "MalformedXML"
+	}
+
+	if config.Status != "Enabled" && objectLockEnabled(b) {
+		return "InvalidBucketState"
+	}
+	if config.MfaDelete != "" && config.MfaDelete != versioningOf(b).MfaDelete && header.Get("X-Amz-Mfa") == "" {
+		return "AccessDenied"
+	}
+
+	return ""
+}
+
+// normalizeVersioning returns the versioning configuration as radosgw
+// returns it, keeping MFA delete unless it is changed.
+func normalizeVersioning(_ *Server, b *bucket, _ http.Header, body []byte) []byte {
+	var config versioningConfiguration
+	_ = xml.Unmarshal(body, &config)
+	if config.MfaDelete == "" {
+		config.MfaDelete = versioningOf(b).MfaDelete
+	}
+	return []byte(fmt.Sprintf(`<VersioningConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Status>%s</Status><MfaDelete>%s</MfaDelete></VersioningConfiguration>`,
+		config.Status, config.MfaDelete))
+}
+
+// objectLockEnabled reports whether object lock is enabled on the bucket.
+func objectLockEnabled(b *bucket) bool {
+	_, ok := b.Subresources["object-lock"]
+	return ok
+}
+
+// validateObjectLock checks an object lock configuration like radosgw,
+// which only enables object lock on versioned buckets.
+func validateObjectLock(_ *Server, b *bucket, _ http.Header, body []byte) string {
+	var config struct {
+		ObjectLockEnabled string `xml:"ObjectLockEnabled"`
+		Rule              *struct {
+			DefaultRetention struct {
+				Mode  string `xml:"Mode"`
+				Days  *int   `xml:"Days"`
+				Years *int   `xml:"Years"`
+			} `xml:"DefaultRetention"`
+		} `xml:"Rule"`
+	}
+	if err := xml.Unmarshal(body, &config); err != nil || config.ObjectLockEnabled != "Enabled" {
+		return "MalformedXML"
+	}
+
+	if rule := config.Rule; rule != nil {
+		retention := rule.DefaultRetention
+		if retention.Mode != "GOVERNANCE" && retention.Mode != "COMPLIANCE" {
+			return "MalformedXML"
+		}
+		if (retention.Days == nil) == (retention.Years == nil) {
+			return "MalformedXML"
+		}
+		if (retention.Days != nil && *retention.Days <= 0) || (retention.Years != nil && *retention.Years <= 0) {
+			return "InvalidRetentionPeriod"
+		}
+	}
+
+	if versioningOf(b).Status != "Enabled" {
+		return "InvalidBucketState"
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package rgwtest
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+)
+
+// corsMethods are the methods CORS rules may allow.
+var corsMethods = []string{"GET", "PUT", "POST", "DELETE", "HEAD"}
+
+// validateCORS checks a CORS configuration like radosgw, which needs
+// origins and supported methods in every rule.
+func validateCORS(_ *Server, _ *bucket, _ http.Header, body []byte) string {
+	var config struct {
+		Rules []struct {
+			AllowedOrigins []string `xml:"AllowedOrigin"`
+			AllowedMethods []string `xml:"AllowedMethod"`
+		} `xml:"CORSRule"`
+	}
+	if err := xml.Unmarshal(body, &config); err != nil || len(config.Rules) == 0 {
+		return "MalformedXML"
+	}
+
+	for _, rule := range config.Rules {
+		if len(rule.AllowedOrigins) == 0 || len(rule.AllowedMethods) == 0 {
+			return "MalformedXML"
+		}
+		for _, method := range rule.AllowedMethods {
+			if !containsString(corsMethods, method) {
+				return "InvalidRequest"
+			}
+		}
+	}
+	return ""
+}
+
+// validateWebsite checks a website configuration like radosgw, which needs
+// either an index document or a redirect of all requests.
+func validateWebsite(_ *Server, _ *bucket, _ http.Header, body []byte) string {
+	var config struct {
+		IndexDocument *struct {
+			Suffix string `xml:"Suffix"`
+		} `xml:"IndexDocument"`
+		RedirectAllRequestsTo *struct {
+			HostName string `xml:"HostName"`
+		} `xml:"RedirectAllRequestsTo"`
+		RoutingRules []struct {
+			Redirect *struct{} `xml:"Redirect"`
+		} `xml:"RoutingRules>RoutingRule"`
+	}
+	if err := xml.Unmarshal(body, &config); err != nil {
+		return "MalformedXML"
+	}
+
+	if (config.IndexDocument == nil) == (config.RedirectAllRequestsTo == nil) {
+		return "InvalidArgument"
+	}
+	if config.IndexDocument != nil && (config.IndexDocument.Suffix == "" || strings.Contains(config.IndexDocument.Suffix, "/")) {
+		return "InvalidArgument"
+	}
+	if config.RedirectAllRequestsTo != nil && (config.RedirectAllRequestsTo.HostName == "" || len(config.RoutingRules) > 0) {
+		return "InvalidArgument"
+	}
+	for _, rule := range config.RoutingRules {
+		if rule.Redirect == nil {
+			return "InvalidArgument"
+		}
+	}
+	return ""
+}
+
+// validateTagging checks the tags of a bucket like radosgw, which limits
+// their number and size and reserves the "aws:" prefix.
+func validateTagging(_ *Server, _ *bucket, _ http.Header, body []byte) string {
+	var config struct {
+		Tags []struct {
+			Key   string `xml:"Key"`
+			Value string `xml:"Value"`
+		} `xml:"TagSet>Tag"`
+	}
+	if err := xml.Unmarshal(body, &config); err != nil {
+		return "MalformedXML"
+	}
+
+	if len(config.Tags) > 50 {
+		return "InvalidTag"
+	}
+	keys := make(map[string]bool)
+	for _, tag := range config.Tags {
+		if tag.Key == "" || len(tag.Key) > 128 || len(tag.Value) > 256 || strings.HasPrefix(tag.Key, "aws:") || keys[tag.Key] {
+			return "InvalidTag"
+		}
+		keys[tag.Key] = true
+	}
+	return ""
+}
@@ -6,7 +6,9 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
+	"os"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 
@@ -33,6 +35,13 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "generate" {
+		if err := generate(os.Args[2:]); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
+
 	var debug bool
 
 	flag.BoolVar(&debug, "debug", false, "set to true to run the provider with support for debuggers like delve")
@@ -49,3 +58,36 @@ func main() {
 		log.Fatal(err.Error())
 	}
 }
+
+// generate writes Terraform configuration with import blocks for the users,
+// subusers and keys of an existing radosgw.
+func generate(args []string) error {
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s generate -endpoint <url> [-out <file>]\n\n", os.Args[0])
+		fmt.Fprintf(flags.Output(), "Writes Terraform configuration with import blocks for all users, subusers and keys.\n")
+		fmt.Fprintf(flags.Output(), "The credentials are read from the ACCESS_KEY_ID and SECRET_ACCESS_KEY environment variables.\n\n")
+		flags.PrintDefaults()
+	}
+
+	var opts provider.GenerateOptions
+	var out string
+	flags.StringVar(&opts.Endpoint, "endpoint", "", "radosgw admin endpoint url")
+	flags.StringVar(&out, "out", "radosgw_import.tf", "file to write the configuration to, \"-\" for stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	opts.AccessKeyID = os.Getenv("ACCESS_KEY_ID")
+	opts.SecretAccessKey = os.Getenv("SECRET_ACCESS_KEY")
+	if opts.Endpoint == "" || opts.AccessKeyID == "" || opts.SecretAccessKey == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	if out == "-" {
+		return provider.Generate(context.Background(), os.Stdout, opts)
+	}
+
+	return provider.GenerateFile(context.Background(), out, opts)
+}